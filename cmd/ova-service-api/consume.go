@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Shopify/sarama"
+	"github.com/joho/godotenv"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/ozonva/ova-service-api/pkg/consumer"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// consumedEvent is the line printed by the consume subcommand for every event
+type consumedEvent struct {
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Event     json.RawMessage   `json:"event"`
+}
+
+// runConsume tails the events topic and prints events matching the filters, one JSON object per line.
+func runConsume(args []string) error {
+	// Ignore error because .env file may not exist, in this case real environment variables will be used
+	_ = godotenv.Load()
+
+	flags := flag.NewFlagSet("consume", flag.ContinueOnError)
+	brokers := flags.String("brokers", os.Getenv("KAFKA_BROKERS"), "comma separated list of Kafka brokers, KAFKA_BROKERS by default")
+	topic := flags.String("topic", kafkaTopic, "topic to consume")
+	// Dedicated group by default, so debugging doesn't move offsets of the real consumers
+	group := flags.String("group", "ova-service-api-consume", "consumer group ID")
+	fromBeginning := flags.Bool("from-beginning", false, "consume from the oldest offset if the group has no committed offsets")
+	eventType := flags.String("type", "", "print only events of the type: created, updated or deleted")
+	userID := flags.Uint64("user-id", 0, "print only events of the user")
+	serviceID := flags.String("service-id", "", "print only events of the service")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*brokers) == 0 {
+		return fmt.Errorf("brokers are required, use -brokers flag or KAFKA_BROKERS environment variable")
+	}
+
+	filterType, err := parseEventType(*eventType)
+	if err != nil {
+		return err
+	}
+
+	printer := json.NewEncoder(os.Stdout)
+	printEvent := func(ctx context.Context, event consumer.Event) error {
+		payload := event.Payload
+
+		if filterType != pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UNSPECIFIED && payload.EventType != filterType {
+			return nil
+		}
+		if *userID != 0 && payload.UserId != *userID {
+			return nil
+		}
+		if len(*serviceID) > 0 && payload.ServiceId != *serviceID {
+			return nil
+		}
+
+		eventJSON, err := protojson.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		return printer.Encode(consumedEvent{
+			Partition: event.Partition,
+			Offset:    event.Offset,
+			Key:       string(event.Key),
+			Headers:   event.Headers,
+			Event:     eventJSON,
+		})
+	}
+
+	client, err := readKafkaClient(*brokers)
+	if err != nil {
+		return err
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.IsolationLevel = sarama.ReadCommitted
+	if err = client.Apply(saramaConfig); err != nil {
+		return err
+	}
+
+	c, err := consumer.New(consumer.Config{
+		Brokers:       client.Brokers,
+		Topic:         *topic,
+		GroupID:       *group,
+		FromBeginning: *fromBeginning,
+		Sarama:        saramaConfig,
+	}, consumer.NewRouter().HandleOther(printEvent))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return c.Run(ctx)
+}
+
+func parseEventType(eventType string) (pb.ServiceEventTypeV1, error) {
+	switch eventType {
+	case "":
+		return pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UNSPECIFIED, nil
+	case "created":
+		return pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED, nil
+	case "updated":
+		return pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED, nil
+	case "deleted":
+		return pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_DELETED, nil
+	default:
+		return 0, fmt.Errorf("unknown event type: %q", eventType)
+	}
+}
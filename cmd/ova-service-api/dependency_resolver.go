@@ -2,29 +2,52 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
 
+	"github.com/ozonva/ova-service-api/internal/changefeed"
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
 	flusher_ "github.com/ozonva/ova-service-api/internal/flusher"
+	"github.com/ozonva/ova-service-api/internal/idempotency"
 	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
 	metrics_ "github.com/ozonva/ova-service-api/internal/infrastructure/metrics"
 	tracer_ "github.com/ozonva/ova-service-api/internal/infrastructure/tracer"
+	"github.com/ozonva/ova-service-api/internal/projection"
 	repo_ "github.com/ozonva/ova-service-api/internal/repo"
 	saver_ "github.com/ozonva/ova-service-api/internal/saver"
+	"github.com/ozonva/ova-service-api/internal/watch"
 )
 
 type dependencies struct {
-	Repo     repo_.Repo
-	Flusher  flusher_.Flusher
-	Saver    saver_.Saver
-	Producer kafka.Producer
-	Metrics  metrics_.Metrics
-	Tracer   *tracer_.JaegerTracer
+	Repo      repo_.Repo
+	Flusher   flusher_.Flusher
+	Saver     saver_.Saver
+	Publisher eventbus.Publisher
+	// WritePublisher receives events of the API write handlers, it discards them if the change feed produces them
+	WritePublisher eventbus.Publisher
+	Encoder        events.Encoder
+	Metrics        metrics_.Metrics
+	Tracer         *tracer_.JaegerTracer
+	// ReadModel and Projector are nil if the read model is disabled
+	ReadModel projection.Store
+	Projector *projection.Projector
+	// ChangeFeed is nil if the change feed is disabled. Subscribers receive its events if it targets them.
+	ChangeFeed  *changefeed.Listener
+	Subscribers *eventbus.MemoryBroker
+	// Watch streams changes to WatchServicesV1 clients
+	Watch *watch.Hub
+	// Idempotency keeps responses of Create and MultiCreate by idempotency keys
+	Idempotency idempotency.Store
 }
 
 type dependencyResolver struct {
 	ctx  context.Context
 	env  environment
 	deps *dependencies
+	// deliveries receives delivery results of the asynchronous Kafka producer
+	deliveries *eventbus.DeliveryRelay
 }
 
 func newDependencyResolver(ctx context.Context, env environment) dependencyResolver {
@@ -39,12 +62,18 @@ func (dr *dependencyResolver) resolve() (*dependencies, error) {
 	if err != nil {
 		return nil, err
 	}
+	pgRepo.WithConflictRules(dr.env.ConflictRules)
 
 	flusher := flusher_.New(multiCreateBatchSize, pgRepo)
 	saver := saver_.New(localCapacity, flushTimeout, flusher)
 	saver.Init()
 
-	producer, err := kafka.NewSyncProducer(kafkaTopic, dr.env.Brokers)
+	encoder, err := events.NewEncoder(events.EncoderConfig{
+		Format:   dr.env.EventFormat,
+		Envelope: dr.env.EventEnvelope,
+		Source:   dr.env.EventSource,
+		KeyBy:    dr.env.EventKey,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -56,23 +85,234 @@ func (dr *dependencyResolver) resolve() (*dependencies, error) {
 
 	metrics := metrics_.NewPrometheusMetrics()
 
+	publisher, err := dr.resolvePublisher(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := watch.NewHub(watch.DefaultHistory)
+	if dr.env.WatchSource == watchSourceLocal {
+		// Events published by this instance, including change feed ones, are copied to watchers
+		publisher = eventbus.NewTee(publisher, watch.NewPublisher(hub))
+	}
+
+	readModel, err := dr.resolveReadModel()
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyStore, err := dr.resolveIdempotency()
+	if err != nil {
+		return nil, err
+	}
+
 	deps := dependencies{
-		Repo:     pgRepo,
-		Flusher:  flusher,
-		Saver:    saver,
-		Producer: producer,
-		Metrics:  metrics,
-		Tracer:   tracer,
+		Repo:           pgRepo,
+		Flusher:        flusher,
+		Saver:          saver,
+		Publisher:      publisher,
+		WritePublisher: publisher,
+		Encoder:        encoder,
+		Metrics:        metrics,
+		Tracer:         tracer,
+		ReadModel:      readModel,
+		Watch:          hub,
+		Idempotency:    idempotencyStore,
+	}
+
+	if readModel != nil {
+		deps.Projector = projection.NewProjector(pgRepo, readModel, metrics)
 	}
 
+	if err = dr.resolveChangeFeed(&deps); err != nil {
+		return nil, err
+	}
+
+	dr.deps = &deps
 	return &deps, nil
 }
 
+func (dr *dependencyResolver) resolvePublisher(metrics metrics_.Metrics) (eventbus.Publisher, error) {
+	publisher, err := dr.resolveDriver(metrics)
+	if err != nil || len(dr.env.RetryQueue) == 0 {
+		return publisher, err
+	}
+
+	queue, err := eventbus.NewRetryQueue(dr.env.RetryQueue, eventbus.DefaultRetryQueueCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dead-letter topic is supported by the Kafka driver only, other drivers log exhausted events
+	var deadLetter eventbus.Publisher
+	if len(dr.env.EventBus) == 0 || dr.env.EventBus == eventbus.DriverKafka {
+		if err = dr.ensureTopic(dr.env.DLQTopicSpec); err != nil {
+			return nil, err
+		}
+
+		producer, producerErr := kafka.NewSyncProducer(dr.env.DLQTopic, dr.env.Kafka)
+		if producerErr != nil {
+			return nil, producerErr
+		}
+		deadLetter = eventbus.NewKafkaPublisher(producer)
+	}
+
+	config := eventbus.RetryConfig{
+		MaxAttempts:   dr.env.RetryAttempts,
+		Interval:      dr.env.RetryInterval,
+		AsyncDelivery: dr.deliveries != nil,
+	}
+
+	retrying := eventbus.NewRetryingPublisher(publisher, deadLetter, queue, config, metrics)
+	if dr.deliveries != nil {
+		// Messages of the asynchronous producer fail after Publish returns, they are queued by delivery results
+		dr.deliveries.Bind(retrying.HandleDelivery)
+	}
+
+	return retrying, nil
+}
+
+func (dr *dependencyResolver) resolveDriver(metrics metrics_.Metrics) (eventbus.Publisher, error) {
+	switch dr.env.EventBus {
+	case "", eventbus.DriverKafka:
+		if err := dr.ensureTopic(dr.env.Topic); err != nil {
+			return nil, err
+		}
+
+		producer, err := dr.resolveProducer(metrics)
+		if err != nil {
+			return nil, err
+		}
+		return eventbus.NewKafkaPublisher(producer), nil
+	case eventbus.DriverMemory:
+		return eventbus.NewMemoryBroker(), nil
+	case eventbus.DriverFile:
+		return eventbus.NewFileSink(dr.env.EventFile)
+	case eventbus.DriverStdout:
+		return eventbus.NewStdoutSink(), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus driver: %q", dr.env.EventBus)
+	}
+}
+
+func (dr *dependencyResolver) resolveProducer(metrics metrics_.Metrics) (kafka.Producer, error) {
+	switch dr.env.Producer {
+	case "", "sync":
+		return kafka.NewSyncProducer(kafkaTopic, dr.env.Kafka)
+	case "idempotent":
+		return kafka.NewIdempotentProducer(kafkaTopic, dr.env.Kafka)
+	case "transactional":
+		return kafka.NewTransactionalProducer(kafkaTopic, dr.env.Kafka, dr.env.TransactionalID)
+	case "async":
+		config := kafka.AsyncProducerConfig{
+			BatchSize:   dr.env.BatchSize,
+			BatchBytes:  dr.env.BatchBytes,
+			Linger:      dr.env.Linger,
+			Compression: dr.env.Compression,
+		}
+		dr.deliveries = eventbus.NewDeliveryRelay()
+		return kafka.NewAsyncProducer(kafkaTopic, dr.env.Kafka, config, dr.deliveries.OnDelivery, metrics)
+	default:
+		return nil, fmt.Errorf("unknown Kafka producer: %q", dr.env.Producer)
+	}
+}
+
+func (dr *dependencyResolver) resolveReadModel() (projection.Store, error) {
+	switch dr.env.ReadModel {
+	case "":
+		return nil, nil
+	case readModelMemory:
+		return projection.NewMemoryStore(), nil
+	case readModelPostgres:
+		return projection.NewPostgresStore(dr.ctx, dr.env.ReadModelDSN)
+	default:
+		return nil, fmt.Errorf("unknown read model: %q", dr.env.ReadModel)
+	}
+}
+
+func (dr *dependencyResolver) resolveIdempotency() (idempotency.Store, error) {
+	switch dr.env.IdempotencyStore {
+	case "", idempotencyStoreMemory:
+		return idempotency.NewMemoryStore(dr.env.IdempotencyTTL), nil
+	case idempotencyStorePostgres:
+		return idempotency.NewPostgresStore(dr.ctx, dr.env.DSN, dr.env.IdempotencyTTL)
+	default:
+		return nil, fmt.Errorf("unknown idempotency store: %q", dr.env.IdempotencyStore)
+	}
+}
+
+func (dr *dependencyResolver) resolveChangeFeed(deps *dependencies) error {
+	switch dr.env.ChangeFeed {
+	case "":
+		return nil
+	case changeFeedPostgres:
+	default:
+		return fmt.Errorf("unknown change feed: %q", dr.env.ChangeFeed)
+	}
+
+	switch dr.env.FeedTarget {
+	case feedTargetBus:
+		deps.ChangeFeed = changefeed.NewListener(dr.env.DSN, changefeed.PublishTo(deps.Publisher, deps.Encoder))
+		// Events of the change feed replace events of the API handlers, so they are not produced twice
+		deps.WritePublisher = eventbus.Discard{}
+	case feedTargetSubscribers:
+		deps.Subscribers = eventbus.NewMemoryBroker()
+		deps.ChangeFeed = changefeed.NewListener(dr.env.DSN, changefeed.PublishTo(deps.Subscribers, deps.Encoder))
+	default:
+		return fmt.Errorf("unknown change feed target: %q", dr.env.FeedTarget)
+	}
+
+	return nil
+}
+
+// ensureTopic creates or validates the topic with the configured settings, if topic provisioning is enabled
+func (dr *dependencyResolver) ensureTopic(spec kafka.TopicSpec) error {
+	if !dr.env.ProvisionTopics {
+		return nil
+	}
+
+	return kafka.EnsureTopic(dr.env.Kafka, spec)
+}
+
 func (dr *dependencyResolver) close() {
+	if dr.deps == nil {
+		return
+	}
+
 	if dr.deps.Saver != nil {
 		dr.deps.Saver.Close()
 	}
 
+	// Close publisher after saver to deliver all buffered events
+	if dr.deps.Publisher != nil {
+		err := dr.deps.Publisher.Close()
+		if err != nil {
+			log.Printf("error occured during closing event publisher: %s", err.Error())
+		}
+	}
+
+	if dr.deps.Watch != nil {
+		dr.deps.Watch.Close()
+	}
+
+	if dr.deps.Subscribers != nil {
+		if err := dr.deps.Subscribers.Close(); err != nil {
+			log.Printf("error occured during closing change feed subscribers: %s", err.Error())
+		}
+	}
+
+	if closer, ok := dr.deps.ReadModel.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("error occured during closing read model: %s", err.Error())
+		}
+	}
+
+	if closer, ok := dr.deps.Idempotency.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("error occured during closing idempotency store: %s", err.Error())
+		}
+	}
+
 	if dr.deps.Tracer != nil {
 		err := dr.deps.Tracer.Closer.Close()
 		if err != nil {
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+// runDrainDLQ re-publishes events from the dead-letter topic to the main topic without the error metadata.
+// Only events which are in the dead-letter topic at the start are drained, committed offsets of the group
+// prevent events from being drained twice.
+func runDrainDLQ(args []string) error {
+	// Ignore error because .env file may not exist, in this case real environment variables will be used
+	_ = godotenv.Load()
+
+	dlqTopic := os.Getenv("KAFKA_DLQ_TOPIC")
+	if len(dlqTopic) == 0 {
+		dlqTopic = kafkaDLQTopic
+	}
+
+	flags := flag.NewFlagSet("drain-dlq", flag.ContinueOnError)
+	brokers := flags.String("brokers", os.Getenv("KAFKA_BROKERS"), "comma separated list of Kafka brokers, KAFKA_BROKERS by default")
+	from := flags.String("from", dlqTopic, "dead-letter topic, KAFKA_DLQ_TOPIC by default")
+	to := flags.String("to", kafkaTopic, "topic to re-publish events to")
+	group := flags.String("group", "ova-service-api-dlq-drain", "consumer group storing the drained offsets")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*brokers) == 0 {
+		return fmt.Errorf("brokers are required, use -brokers flag or KAFKA_BROKERS environment variable")
+	}
+	client, err := readKafkaClient(*brokers)
+	if err != nil {
+		return err
+	}
+
+	producer, err := kafka.NewSyncProducer(*to, client)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	drained, err := kafka.DrainTopic(ctx, client, *from, *group, func(message kafka.Message) error {
+		original := eventbus.StripDeadLetterHeaders(eventbus.Message(message))
+		return producer.SendMessage(kafka.Message(original))
+	})
+	log.Printf("Drained %d events from %s to %s", drained, *from, *to)
+
+	return err
+}
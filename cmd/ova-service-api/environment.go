@@ -3,14 +3,54 @@ package main
 import (
 	"fmt"
 	"os"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/ozonva/ova-service-api/internal/idempotency"
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+	repo_ "github.com/ozonva/ova-service-api/internal/repo"
 )
 
 type environment struct {
-	DSN     string
-	Brokers []string
+	DSN             string
+	Kafka           kafka.ClientConfig
+	EventBus        string
+	EventFile       string
+	EventFormat     string
+	EventEnvelope   string
+	EventSource     string
+	EventKey        string
+	Producer        string
+	BatchSize       int
+	BatchBytes      int
+	Linger          time.Duration
+	Compression     string
+	RetryQueue      string
+	RetryAttempts   int
+	RetryInterval   time.Duration
+	DLQTopic        string
+	TransactionalID string
+	ProvisionTopics bool
+	Topic           kafka.TopicSpec
+	DLQTopicSpec    kafka.TopicSpec
+	ReadModel       string
+	ReadModelDSN    string
+	ReadModelGroup  string
+	RebuildOnStart  bool
+	ChangeFeed      string
+	FeedTarget      string
+	WatchSource     string
+	// IdempotencyStore is "memory" or "postgres", keys are kept for IdempotencyTTL
+	IdempotencyStore string
+	IdempotencyTTL   time.Duration
+	// ConflictRules are checked on writes of services, empty if overlapping services are allowed
+	ConflictRules []repo_.ConflictRule
+	// SlotBuffer is the free time FindAvailableSlotsV1 keeps around booked services by default
+	SlotBuffer time.Duration
+	// AdminToken is required by admin RPCs, they are disabled if it is empty
+	AdminToken string
 }
 
 func readEnvironment() (environment, error) {
@@ -22,15 +62,230 @@ func readEnvironment() (environment, error) {
 		return environment{}, fmt.Errorf("DATABASE_CONNECTION_STRING environment variable is required")
 	}
 
-	brokers, ok := os.LookupEnv("KAFKA_BROKERS")
-	if !ok || len(brokers) == 0 {
+	// Optional, events are published to Kafka by default
+	eventBus := os.Getenv("EVENT_BUS")
+	eventFile := os.Getenv("EVENT_FILE")
+
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if len(brokers) == 0 && (len(eventBus) == 0 || eventBus == "kafka") {
 		return environment{}, fmt.Errorf("KAFKA_BROKERS environment variable is required")
 	}
 
+	kafkaClient, err := readKafkaClient(brokers)
+	if err != nil {
+		return environment{}, err
+	}
+
+	// Optional, protobuf payload in the native envelope is used by default
+	eventFormat := os.Getenv("EVENT_FORMAT")
+	eventEnvelope := os.Getenv("EVENT_ENVELOPE")
+	eventSource := os.Getenv("EVENT_SOURCE")
+	eventKey := os.Getenv("EVENT_KEY")
+
+	// Optional, synchronous producer is used by default. Batching settings are applied to asynchronous producer only.
+	producer := os.Getenv("KAFKA_PRODUCER")
+	compression := os.Getenv("KAFKA_COMPRESSION")
+
+	batchSize, err := lookupIntEnv("KAFKA_BATCH_SIZE")
+	if err != nil {
+		return environment{}, err
+	}
+
+	batchBytes, err := lookupIntEnv("KAFKA_BATCH_BYTES")
+	if err != nil {
+		return environment{}, err
+	}
+
+	linger, err := lookupDurationEnv("KAFKA_LINGER")
+	if err != nil {
+		return environment{}, err
+	}
+
+	// Optional, failed events are not retried if the retry queue file is not set
+	retryQueue := os.Getenv("EVENT_RETRY_QUEUE")
+	if producer == "async" && len(retryQueue) == 0 && (len(eventBus) == 0 || eventBus == "kafka") {
+		// Nobody waits for delivery results of the async producer, so failed events are lost without retries
+		return environment{}, fmt.Errorf("EVENT_RETRY_QUEUE environment variable is required for the async Kafka producer")
+	}
+
+	retryAttempts, err := lookupIntEnv("EVENT_RETRY_ATTEMPTS")
+	if err != nil {
+		return environment{}, err
+	}
+
+	retryInterval, err := lookupDurationEnv("EVENT_RETRY_INTERVAL")
+	if err != nil {
+		return environment{}, err
+	}
+
+	dlqTopic := os.Getenv("KAFKA_DLQ_TOPIC")
+	if len(dlqTopic) == 0 {
+		dlqTopic = kafkaDLQTopic
+	}
+
+	// Transactional ID must be unique for the instance and stable across restarts, host name fits well for both
+	transactionalID := os.Getenv("KAFKA_TRANSACTIONAL_ID")
+	if len(transactionalID) == 0 {
+		hostname, hostErr := os.Hostname()
+		if hostErr != nil {
+			return environment{}, fmt.Errorf("can't derive Kafka transactional ID from host name: %s", hostErr.Error())
+		}
+		transactionalID = fmt.Sprintf("%s-%s", kafkaTransactionalIDPrefix, hostname)
+	}
+
+	// Optional, topics are expected to be created by the operator by default
+	provisionTopics, err := lookupBoolEnv("KAFKA_PROVISION_TOPICS")
+	if err != nil {
+		return environment{}, err
+	}
+
+	topic, err := readTopicSpec(kafkaTopic)
+	if err != nil {
+		return environment{}, err
+	}
+
+	dlqTopicSpec, err := readDLQTopicSpec(dlqTopic, topic)
+	if err != nil {
+		return environment{}, err
+	}
+
+	// Optional, query RPCs read from the primary table by default
+	readModel := os.Getenv("READ_MODEL")
+	if len(readModel) > 0 && len(eventBus) > 0 && eventBus != "kafka" {
+		// The read model is projected from the events topic only, it would never be updated by other buses
+		return environment{}, fmt.Errorf("READ_MODEL requires the \"kafka\" event bus, EVENT_BUS is %q", eventBus)
+	}
+
+	readModelDSN := os.Getenv("READ_MODEL_DSN")
+	if len(readModelDSN) == 0 {
+		readModelDSN = dsn
+	}
+
+	// In-memory read model is built by every instance, so every instance needs its own consumer group
+	readModelGroup := os.Getenv("READ_MODEL_GROUP")
+	if len(readModelGroup) == 0 {
+		readModelGroup = readModelGroupPrefix
+		if readModel == readModelMemory {
+			hostname, hostErr := os.Hostname()
+			if hostErr != nil {
+				return environment{}, fmt.Errorf("can't derive read model consumer group from host name: %s", hostErr.Error())
+			}
+			readModelGroup = fmt.Sprintf("%s-%s", readModelGroupPrefix, hostname)
+		}
+	}
+
+	rebuildOnStart, err := lookupBoolEnv("READ_MODEL_REBUILD")
+	if err != nil {
+		return environment{}, err
+	}
+
+	// Optional, events are produced by the API handlers by default
+	changeFeed := os.Getenv("CHANGE_FEED")
+	feedTarget := os.Getenv("CHANGE_FEED_TARGET")
+	if len(feedTarget) == 0 {
+		feedTarget = feedTargetBus
+	}
+
+	// Optional, watchers receive changes made through this instance by default
+	watchSource := os.Getenv("WATCH_SOURCE")
+	if len(watchSource) == 0 {
+		watchSource = watchSourceLocal
+	}
+
+	// Optional, idempotency keys are kept in memory for a day by default
+	idempotencyStore := os.Getenv("IDEMPOTENCY_STORE")
+
+	idempotencyTTL, err := lookupDurationEnv("IDEMPOTENCY_TTL")
+	if err != nil {
+		return environment{}, err
+	}
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = idempotency.DefaultTTL
+	}
+
+	// Optional, overlapping services are allowed by default
+	conflictRules, err := repo_.ParseConflictRules(os.Getenv("CONFLICT_RULES"))
+	if err != nil {
+		return environment{}, fmt.Errorf("CONFLICT_RULES environment variable is not valid: %s", err.Error())
+	}
+
+	// Optional, slots may start right after booked services by default
+	slotBuffer, err := lookupDurationEnv("SLOT_BUFFER")
+	if err != nil {
+		return environment{}, err
+	}
+	if slotBuffer < 0 {
+		return environment{}, fmt.Errorf("SLOT_BUFFER environment variable can't be negative")
+	}
+
+	// Optional, admin RPCs are disabled by default
+	adminToken := os.Getenv("ADMIN_TOKEN")
+
 	env := environment{
-		DSN:     dsn,
-		Brokers: strings.Split(brokers, ","),
+		DSN:              dsn,
+		Kafka:            kafkaClient,
+		EventBus:         eventBus,
+		EventFile:        eventFile,
+		EventFormat:      eventFormat,
+		EventEnvelope:    eventEnvelope,
+		EventSource:      eventSource,
+		EventKey:         eventKey,
+		Producer:         producer,
+		BatchSize:        batchSize,
+		BatchBytes:       batchBytes,
+		Linger:           linger,
+		Compression:      compression,
+		RetryQueue:       retryQueue,
+		RetryAttempts:    retryAttempts,
+		RetryInterval:    retryInterval,
+		DLQTopic:         dlqTopic,
+		TransactionalID:  transactionalID,
+		ProvisionTopics:  provisionTopics,
+		Topic:            topic,
+		DLQTopicSpec:     dlqTopicSpec,
+		ReadModel:        readModel,
+		ReadModelDSN:     readModelDSN,
+		ReadModelGroup:   readModelGroup,
+		RebuildOnStart:   rebuildOnStart,
+		ChangeFeed:       changeFeed,
+		FeedTarget:       feedTarget,
+		WatchSource:      watchSource,
+		IdempotencyStore: idempotencyStore,
+		IdempotencyTTL:   idempotencyTTL,
+		ConflictRules:    conflictRules,
+		SlotBuffer:       slotBuffer,
+		AdminToken:       adminToken,
 	}
 
 	return env, nil
 }
+
+// lookupIntEnv returns zero value if the variable is not set
+func lookupIntEnv(name string) (int, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || len(value) == 0 {
+		return 0, nil
+	}
+
+	res, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s environment variable must be an integer: %s", name, err.Error())
+	}
+
+	return res, nil
+}
+
+// lookupDurationEnv returns zero value if the variable is not set
+func lookupDurationEnv(name string) (time.Duration, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || len(value) == 0 {
+		return 0, nil
+	}
+
+	res, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s environment variable must be a duration: %s", name, err.Error())
+	}
+
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+func TestReadEnvironment_WhenReadModelIsSetWithoutKafkaBus_ShouldFail(t *testing.T) {
+	t.Setenv("DATABASE_CONNECTION_STRING", "postgres://localhost/services")
+	t.Setenv("EVENT_BUS", "stdout")
+	t.Setenv("READ_MODEL", readModelMemory)
+
+	_, err := readEnvironment()
+
+	require.Error(t, err, "Read model without Kafka should be rejected")
+	assert.Contains(t, err.Error(), "READ_MODEL", "Error should name the setting")
+}
+
+func TestReadEnvironment_WhenReadModelIsSetWithKafkaBus_ShouldSucceed(t *testing.T) {
+	t.Setenv("DATABASE_CONNECTION_STRING", "postgres://localhost/services")
+	t.Setenv("EVENT_BUS", "kafka")
+	t.Setenv("KAFKA_BROKERS", "localhost:9092")
+	t.Setenv("READ_MODEL", readModelPostgres)
+
+	env, err := readEnvironment()
+
+	require.NoError(t, err, "Read model with Kafka should be accepted")
+	assert.Equal(t, readModelPostgres, env.ReadModel)
+}
+
+func TestReadEnvironment_WhenDLQSettingsAreNotSet_ShouldKeepDeadLettersLonger(t *testing.T) {
+	t.Setenv("DATABASE_CONNECTION_STRING", "postgres://localhost/services")
+	t.Setenv("KAFKA_BROKERS", "localhost:9092")
+	t.Setenv("KAFKA_TOPIC_PARTITIONS", "12")
+	t.Setenv("KAFKA_TOPIC_REPLICATION", "3")
+	t.Setenv("KAFKA_TOPIC_RETENTION", "24h")
+
+	env, err := readEnvironment()
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, kafkaDLQTopic, env.DLQTopicSpec.Name)
+	assert.Equal(t, int32(1), env.DLQTopicSpec.Partitions, "Dead-letter topic should have the single partition")
+	assert.Equal(t, int16(3), env.DLQTopicSpec.ReplicationFactor, "Replication of the main topic should be kept")
+	assert.Equal(t, kafkaDLQRetention, env.DLQTopicSpec.Retention, "Dead-letter topic should have its own retention")
+}
+
+func TestReadEnvironment_WhenDLQSettingsAreSet_ShouldUseThem(t *testing.T) {
+	t.Setenv("DATABASE_CONNECTION_STRING", "postgres://localhost/services")
+	t.Setenv("KAFKA_BROKERS", "localhost:9092")
+	t.Setenv("KAFKA_DLQ_TOPIC", "services.dead")
+	t.Setenv("KAFKA_DLQ_PARTITIONS", "2")
+	t.Setenv("KAFKA_DLQ_REPLICATION", "2")
+	t.Setenv("KAFKA_DLQ_RETENTION", "2160h")
+
+	env, err := readEnvironment()
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, kafka.TopicSpec{Name: "services.dead", Partitions: 2, ReplicationFactor: 2, Retention: 2160 * time.Hour}, env.DLQTopicSpec)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+// readKafkaClient returns connection settings of the brokers from the comma separated list.
+// Security settings are shared by the service and its subcommands, so they are read from environment only.
+func readKafkaClient(brokers string) (kafka.ClientConfig, error) {
+	var brokerList []string
+	if len(brokers) > 0 {
+		brokerList = strings.Split(brokers, ",")
+	}
+
+	tlsEnabled, err := lookupBoolEnv("KAFKA_TLS_ENABLED")
+	if err != nil {
+		return kafka.ClientConfig{}, err
+	}
+
+	insecureSkipVerify, err := lookupBoolEnv("KAFKA_TLS_INSECURE_SKIP_VERIFY")
+	if err != nil {
+		return kafka.ClientConfig{}, err
+	}
+
+	client := kafka.ClientConfig{
+		Brokers:  brokerList,
+		ClientID: os.Getenv("KAFKA_CLIENT_ID"),
+		TLS: kafka.TLSConfig{
+			Enabled:            tlsEnabled,
+			CAFile:             os.Getenv("KAFKA_TLS_CA_FILE"),
+			CertFile:           os.Getenv("KAFKA_TLS_CERT_FILE"),
+			KeyFile:            os.Getenv("KAFKA_TLS_KEY_FILE"),
+			InsecureSkipVerify: insecureSkipVerify,
+		},
+		SASL: kafka.SASLConfig{
+			Mechanism: strings.ToUpper(os.Getenv("KAFKA_SASL_MECHANISM")),
+			User:      os.Getenv("KAFKA_SASL_USER"),
+			Password:  os.Getenv("KAFKA_SASL_PASSWORD"),
+		},
+	}
+
+	return client, nil
+}
+
+// readTopicSpec returns the desired settings of the topic created or validated on start
+func readTopicSpec(topic string) (kafka.TopicSpec, error) {
+	partitions, err := lookupIntEnv("KAFKA_TOPIC_PARTITIONS")
+	if err != nil {
+		return kafka.TopicSpec{}, err
+	}
+
+	replication, err := lookupIntEnv("KAFKA_TOPIC_REPLICATION")
+	if err != nil {
+		return kafka.TopicSpec{}, err
+	}
+
+	retention, err := lookupDurationEnv("KAFKA_TOPIC_RETENTION")
+	if err != nil {
+		return kafka.TopicSpec{}, err
+	}
+
+	spec := kafka.TopicSpec{
+		Name:              topic,
+		Partitions:        int32(partitions),
+		ReplicationFactor: int16(replication),
+		Retention:         retention,
+	}
+
+	return spec, nil
+}
+
+// readDLQTopicSpec returns the desired settings of the dead-letter topic. Dead letters wait for "drain-dlq"
+// until somebody looks into them, so the topic has the single partition and the long retention by default,
+// the replication factor of the main topic is kept.
+func readDLQTopicSpec(topic string, main kafka.TopicSpec) (kafka.TopicSpec, error) {
+	partitions, err := lookupIntEnv("KAFKA_DLQ_PARTITIONS")
+	if err != nil {
+		return kafka.TopicSpec{}, err
+	}
+	if partitions == 0 {
+		partitions = 1
+	}
+
+	replication, err := lookupIntEnv("KAFKA_DLQ_REPLICATION")
+	if err != nil {
+		return kafka.TopicSpec{}, err
+	}
+	if replication == 0 {
+		replication = int(main.ReplicationFactor)
+	}
+
+	retention, err := lookupDurationEnv("KAFKA_DLQ_RETENTION")
+	if err != nil {
+		return kafka.TopicSpec{}, err
+	}
+	if retention == 0 {
+		retention = kafkaDLQRetention
+	}
+
+	spec := kafka.TopicSpec{
+		Name:              topic,
+		Partitions:        int32(partitions),
+		ReplicationFactor: int16(replication),
+		Retention:         retention,
+	}
+
+	return spec, nil
+}
+
+// lookupBoolEnv returns false if the variable is not set
+func lookupBoolEnv(name string) (bool, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || len(value) == 0 {
+		return false, nil
+	}
+
+	res, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s environment variable must be a boolean: %s", name, err.Error())
+	}
+
+	return res, nil
+}
@@ -5,19 +5,22 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"time"
+	// Zones of services must not depend on the zone database of the host
+	_ "time/tzdata"
 
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/uber/jaeger-client-go"
 	"google.golang.org/grpc"
 
 	"github.com/ozonva/ova-service-api/internal/api"
-	flusher_ "github.com/ozonva/ova-service-api/internal/flusher"
-	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
-	"github.com/ozonva/ova-service-api/internal/infrastructure/metrics"
-	repo_ "github.com/ozonva/ova-service-api/internal/repo"
-	saver_ "github.com/ozonva/ova-service-api/internal/saver"
+	"github.com/ozonva/ova-service-api/internal/idempotency"
+	"github.com/ozonva/ova-service-api/internal/infrastructure/tracer"
+	"github.com/ozonva/ova-service-api/internal/requestid"
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
@@ -29,9 +32,50 @@ const (
 	flushTimeout         = 1 * time.Second
 	localCapacity        = 10
 	kafkaTopic           = "services"
+	kafkaDLQTopic        = "services.dlq"
+	// Default retention of the dead-letter topic, dead letters must outlive the time it takes to notice them
+	kafkaDLQRetention = 30 * 24 * time.Hour
+	// Prefix of the default transactional ID of the Kafka producer, the host name is appended to it
+	kafkaTransactionalIDPrefix = "ova-service-api"
+	// Consumer group of the read model projection, the host name is appended to it for the in-memory read model
+	readModelGroupPrefix = "ova-service-api-projection"
+	readModelMemory      = "memory"
+	readModelPostgres    = "postgres"
+	changeFeedPostgres   = "postgres"
+	// Change feed targets: the configured event bus instead of the API handlers or in-process subscribers
+	feedTargetBus         = "bus"
+	feedTargetSubscribers = "subscribers"
+	// Sources of changes streamed by WatchServicesV1
+	watchSourceLocal      = "local"
+	watchSourceKafka      = "kafka"
+	watchSourceChangeFeed = "changefeed"
+	watchGroupPrefix      = "ova-service-api-watch"
+	// Stores of idempotency keys of Create and MultiCreate
+	idempotencyStoreMemory   = "memory"
+	idempotencyStorePostgres = "postgres"
+	idempotencyPurgeInterval = 10 * time.Minute
 )
 
+// commands are run instead of the server when the name is passed as the first argument
+var commands = map[string]func(args []string) error{
+	"consume":            runConsume,
+	"replay-events":      runReplayEvents,
+	"drain-dlq":          runDrainDLQ,
+	"rebuild-read-model": runRebuildReadModel,
+	"export":             runExport,
+	"import":             runImport,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	ctx := context.Background()
 	env, err := readEnvironment()
 	if err != nil {
@@ -45,23 +89,71 @@ func main() {
 	}
 	defer resolver.close()
 
+	if deps.Projector != nil {
+		projectionConsumer, projectionErr := runProjection(ctx, env, deps.Projector)
+		if projectionErr != nil {
+			log.Fatalf("Error occured during read model projection start: %s", projectionErr.Error())
+		}
+		defer projectionConsumer.Close()
+	}
+
+	if deps.ChangeFeed != nil {
+		go func() {
+			if feedErr := deps.ChangeFeed.Run(ctx); feedErr != nil {
+				log.Printf("change feed stopped: %s", feedErr.Error())
+			}
+		}()
+	}
+
+	watchConsumer, err := runWatchSource(ctx, env, deps)
+	if err != nil {
+		log.Fatalf("Error occured during watch source start: %s", err.Error())
+	}
+	if watchConsumer != nil {
+		defer watchConsumer.Close()
+	}
+
+	go purgeIdempotencyKeys(ctx, deps.Idempotency)
 	go runMetricServer()
 	go runHttpServer(ctx)
 
-	if err = runGrpcServer(ctx, deps.Repo, deps.Saver, deps.Flusher, deps.Producer, deps.Metrics); err != nil {
+	if err = runGrpcServer(ctx, env, deps); err != nil {
 		log.Fatal(err)
 	}
 }
 
 // Actually it should use root context, but for this task we do not use it
-func runGrpcServer(_ context.Context, repo repo_.Repo, saver saver_.Saver, flusher flusher_.Flusher, producer kafka.Producer, metrics metrics.Metrics) error {
+func runGrpcServer(_ context.Context, env environment, deps *dependencies) error {
 	listen, err := net.Listen("tcp", grpcServerEndpoint)
 	if err != nil {
 		log.Fatalf("gRPC: failed to listen: %v", err)
 	}
 
-	server := grpc.NewServer(grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor))
-	pb.RegisterServiceAPIServer(server, api.NewGrpcApiServer(repo, saver, flusher, producer, metrics))
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpc_prometheus.UnaryServerInterceptor,
+			requestid.UnaryServerInterceptor(),
+			tracer.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			grpc_prometheus.StreamServerInterceptor,
+			requestid.StreamServerInterceptor(),
+			tracer.StreamServerInterceptor(),
+		),
+	)
+	apiServer := api.NewGrpcApiServer(deps.Repo, deps.Saver, deps.Flusher, deps.Publisher, deps.Encoder, deps.Metrics).
+		WithWritePublisher(deps.WritePublisher).
+		WithWatcher(deps.Watch, api.DefaultHeartbeatInterval).
+		WithIdempotency(deps.Idempotency).
+		WithSlotBuffer(env.SlotBuffer).
+		WithAdminToken(env.AdminToken)
+	if deps.ReadModel != nil {
+		apiServer.WithReadModel(deps.ReadModel)
+	}
+	if len(env.ConflictRules) > 0 {
+		apiServer.WithConflictDetection()
+	}
+	pb.RegisterServiceAPIServer(server, apiServer)
 
 	if grpcErr := server.Serve(listen); grpcErr != nil {
 		log.Fatalf("gRPC: failed to serve: %v", grpcErr)
@@ -74,7 +166,13 @@ func runHttpServer(ctx context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	mux := runtime.NewServeMux()
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithMarshalerOption(api.SSEContentType, api.NewSSEMarshaler()),
+		runtime.WithMarshalerOption(api.JSONLinesContentType, api.NewJSONLinesMarshaler()),
+		runtime.WithMarshalerOption(api.CSVContentType, api.NewCSVMarshaler()),
+		runtime.WithForwardResponseOption(api.CSVHeader),
+	)
 	opts := []grpc.DialOption{grpc.WithInsecure()}
 
 	if err := pb.RegisterServiceAPIHandlerFromEndpoint(ctx, mux, grpcServerEndpoint, opts); err != nil {
@@ -86,6 +184,34 @@ func runHttpServer(ctx context.Context) {
 	}
 }
 
+// incomingHeaderMatcher additionally forwards request ID, Jaeger trace context, the cursor of SSE clients
+// and idempotency keys from HTTP headers to gRPC metadata
+func incomingHeaderMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case requestid.Header, jaeger.TraceContextHeaderName, api.LastEventIDHeader, api.IdempotencyKeyHeader:
+		return strings.ToLower(key), true
+	default:
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// purgeIdempotencyKeys removes expired keys, so the store doesn't grow with every create
+func purgeIdempotencyKeys(ctx context.Context, store idempotency.Store) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.Purge(); err != nil {
+				log.Printf("error occured during purging idempotency keys: %s", err.Error())
+			}
+		}
+	}
+}
+
 func runMetricServer() {
 	http.Handle("/metrics", promhttp.Handler())
 	if httpErr := http.ListenAndServe(metricEndpoint, nil); httpErr != nil {
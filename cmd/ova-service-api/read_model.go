@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/ozonva/ova-service-api/internal/projection"
+	"github.com/ozonva/ova-service-api/pkg/consumer"
+)
+
+// runProjection starts consuming events to the read model in background. The in-memory read model
+// is empty on start, so it is always rebuilt, the persistent one is rebuilt on READ_MODEL_REBUILD only.
+// Events received during the rebuild wait for it and are applied on top of the rebuilt model.
+func runProjection(ctx context.Context, env environment, projector *projection.Projector) (*consumer.Consumer, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.IsolationLevel = sarama.ReadCommitted
+	if err := env.Kafka.Apply(saramaConfig); err != nil {
+		return nil, err
+	}
+
+	c, err := consumer.New(consumer.Config{
+		Brokers: env.Kafka.Brokers,
+		Topic:   kafkaTopic,
+		GroupID: env.ReadModelGroup,
+		Sarama:  saramaConfig,
+	}, projector.Router())
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if runErr := c.Run(ctx); runErr != nil {
+			log.Printf("read model projection stopped: %s", runErr.Error())
+		}
+	}()
+
+	if env.ReadModel == readModelMemory || env.RebuildOnStart {
+		if _, err = projector.Rebuild(ctx, projection.DefaultRebuildBatchSize); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// runRebuildReadModel rebuilds the persistent read model from scratch. Running instances keep projecting
+// events meanwhile, a change which races with the rebuild may be overwritten by the older state until the next
+// event of the service, so prefer READ_MODEL_REBUILD on start when the consistency matters.
+func runRebuildReadModel(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("rebuild-read-model takes no arguments")
+	}
+
+	env, err := readEnvironment()
+	if err != nil {
+		return err
+	}
+
+	if env.ReadModel != readModelPostgres {
+		return fmt.Errorf("only the %q read model can be rebuilt offline, set READ_MODEL", readModelPostgres)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resolver := newDependencyResolver(ctx, env)
+	deps, err := resolver.resolve()
+	if err != nil {
+		return err
+	}
+	defer resolver.close()
+
+	projected, err := deps.Projector.Rebuild(ctx, projection.DefaultRebuildBatchSize)
+	log.Printf("Projected %d services to the read model", projected)
+
+	return err
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ozonva/ova-service-api/internal/replay"
+)
+
+// replayCheckpoint is stored in the checkpoint file after every published batch
+type replayCheckpoint struct {
+	Replayed      uint64 `json:"replayed"`
+	LastServiceID string `json:"last_service_id"`
+	Done          bool   `json:"done"`
+}
+
+// runReplayEvents re-emits create events for the stored services. With -checkpoint the progress is saved
+// to the file, so the interrupted replay continues from the last published batch on the next run.
+func runReplayEvents(args []string) error {
+	flags := flag.NewFlagSet("replay-events", flag.ContinueOnError)
+	from := flags.String("from", "", "replay services created at or after the time, RFC3339")
+	to := flags.String("to", "", "replay services created before the time, RFC3339")
+	userID := flags.Uint64("user-id", 0, "replay services of the user")
+	after := flags.String("after", "", "resume the replay after the service ID")
+	rate := flags.Int("rate", replay.DefaultRate, "max events per second, 0 is unlimited")
+	limit := flags.Uint64("limit", 0, "max events to replay, 0 is unlimited")
+	batchSize := flags.Uint64("batch-size", replay.DefaultBatchSize, "services loaded and published at once")
+	checkpointPath := flags.String("checkpoint", "", "file to save the progress to and resume from")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := replay.Options{
+		UserID:    *userID,
+		Rate:      *rate,
+		Limit:     *limit,
+		BatchSize: *batchSize,
+	}
+
+	var err error
+	if options.From, err = parseOptionalTime(*from); err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	if options.To, err = parseOptionalTime(*to); err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+
+	if len(*after) > 0 {
+		if options.After, err = uuid.Parse(*after); err != nil {
+			return fmt.Errorf("invalid -after: %w", err)
+		}
+	} else if len(*checkpointPath) > 0 {
+		checkpoint, readErr := readReplayCheckpoint(*checkpointPath)
+		if readErr != nil {
+			return readErr
+		}
+
+		if checkpoint.Done {
+			log.Printf("Replay is already done according to %s", *checkpointPath)
+			return nil
+		}
+
+		if len(checkpoint.LastServiceID) > 0 {
+			if options.After, err = uuid.Parse(checkpoint.LastServiceID); err != nil {
+				return fmt.Errorf("invalid checkpoint: %w", err)
+			}
+			log.Printf("Resuming replay after service %s", checkpoint.LastServiceID)
+		}
+	}
+
+	env, err := readEnvironment()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resolver := newDependencyResolver(ctx, env)
+	deps, err := resolver.resolve()
+	if err != nil {
+		return err
+	}
+	defer resolver.close()
+
+	var saveCheckpoint replay.Checkpoint
+	if len(*checkpointPath) > 0 {
+		saveCheckpoint = func(progress replay.Progress) error {
+			return writeReplayCheckpoint(*checkpointPath, progress)
+		}
+	}
+
+	progress, err := replay.NewReplayer(deps.Repo, deps.Publisher, deps.Encoder).Run(ctx, options, saveCheckpoint)
+	log.Printf("Replayed %d events, last service ID: %s", progress.Replayed, progress.LastServiceID.String())
+
+	return err
+}
+
+func parseOptionalTime(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// readReplayCheckpoint returns empty checkpoint if the file does not exist
+func readReplayCheckpoint(path string) (replayCheckpoint, error) {
+	var checkpoint replayCheckpoint
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+
+	if err = json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("can't parse checkpoint file %s: %w", path, err)
+	}
+
+	return checkpoint, nil
+}
+
+func writeReplayCheckpoint(path string, progress replay.Progress) error {
+	data, err := json.Marshal(replayCheckpoint{
+		Replayed:      progress.Replayed,
+		LastServiceID: progress.LastServiceID.String(),
+		Done:          progress.Done,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write to the temporary file and rename it, so the checkpoint is never left half-written
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+	repo_ "github.com/ozonva/ova-service-api/internal/repo"
+	"github.com/ozonva/ova-service-api/internal/transfer"
+)
+
+// runExport writes services of the repo to the JSONL or CSV file, stdout is used if no file is passed
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "file to write to, stdout by default")
+	format := flags.String("format", "", "jsonl or csv, detected by the output file extension by default")
+	limit := flags.Uint64("limit", 0, "max services to export, 0 is unlimited")
+	offset := flags.Uint64("offset", 0, "services to skip in the List order")
+	var filter models.IntervalFilter
+	flags.Func("from", "export services in the range starting at the RFC 3339 time only", parseFilterTime(&filter.From))
+	flags.Func("to", "export services in the range ending at the RFC 3339 time only", parseFilterTime(&filter.To))
+	flags.BoolVar(&filter.Within, "within", false, "export services which are entirely within the range only")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	formatName, err := transferFormat(*format, *output)
+	if err != nil {
+		return err
+	}
+
+	env, err := readEnvironment()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	repo, err := repo_.NewPostgresServiceRepo(ctx, env.DSN)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if len(*output) > 0 {
+		file, createErr := os.Create(*output)
+		if createErr != nil {
+			return createErr
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer, err := transfer.NewWriter(out, formatName)
+	if err != nil {
+		return err
+	}
+
+	exported, err := transfer.Export(repo, writer, filter, *limit, *offset)
+	log.Printf("Exported %d services", exported)
+
+	return err
+}
+
+// runImport stores services from the JSONL or CSV file to the repo. Lines of the failed records are written
+// to the -errors file with the reason as JSON lines. The interrupted import is resumed with -from-line.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "jsonl or csv, detected by the input file extension by default")
+	dryRun := flags.Bool("dry-run", false, "validate records without storing them")
+	batchSize := flags.Int("batch-size", transfer.DefaultBatchSize, "services stored at once")
+	fromLine := flags.Int("from-line", 1, "skip records before the line, printed by the interrupted import")
+	errorsPath := flags.String("errors", "", "file to append failed records to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import [flags] <file>, pass - to read stdin")
+	}
+	input := flags.Arg(0)
+
+	formatName, err := transferFormat(*format, input)
+	if err != nil {
+		return err
+	}
+
+	env, err := readEnvironment()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	repo, err := repo_.NewPostgresServiceRepo(ctx, env.DSN)
+	if err != nil {
+		return err
+	}
+	// Imported services are checked against each other and stored ones the same way as services created by the API
+	repo.WithConflictRules(env.ConflictRules)
+
+	var in io.Reader = os.Stdin
+	if input != "-" {
+		file, openErr := os.Open(input)
+		if openErr != nil {
+			return openErr
+		}
+		defer file.Close()
+		in = file
+	}
+
+	reader, err := transfer.NewReader(in, formatName)
+	if err != nil {
+		return err
+	}
+
+	report, closeReport, err := openImportReport(*errorsPath)
+	if err != nil {
+		return err
+	}
+	defer closeReport()
+
+	options := transfer.Options{
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+		FromLine:  *fromLine,
+	}
+
+	progress, err := transfer.NewImporter(repo, models.UUIDv7).Run(ctx, reader, options, report)
+	if *dryRun {
+		log.Printf("Validated %d services, %d failed", progress.Imported, progress.Failed)
+	} else {
+		log.Printf("Imported %d services, %d failed", progress.Imported, progress.Failed)
+	}
+	if err != nil {
+		log.Printf("Import is interrupted, resume it with -from-line %d", progress.NextLine)
+	}
+
+	return err
+}
+
+// transferFormat returns the explicit format or detects it by the file extension, stdin and stdout default to JSONL
+func transferFormat(format string, path string) (string, error) {
+	if len(format) > 0 {
+		return format, nil
+	}
+	if len(path) == 0 || path == "-" {
+		return transfer.FormatJSONL, nil
+	}
+
+	return transfer.FormatFromPath(path)
+}
+
+// parseFilterTime returns the flag.Func parser of the RFC 3339 time of the interval bound
+func parseFilterTime(target *time.Time) func(string) error {
+	return func(value string) error {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+
+		*target = parsed.UTC()
+		return nil
+	}
+}
+
+// openImportReport appends failures to the file, so resumed imports keep failures of the previous runs.
+// Failures are logged if no file is passed.
+func openImportReport(path string) (transfer.Report, func(), error) {
+	if len(path) == 0 {
+		report := func(failure transfer.Failure) error {
+			log.Printf("Line %d is not imported: %s", failure.Line, failure.Error)
+			return nil
+		}
+		return report, func() {}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoder := json.NewEncoder(file)
+	report := func(failure transfer.Failure) error {
+		return encoder.Encode(failure)
+	}
+	closeReport := func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("error occured during closing import report: %s", closeErr.Error())
+		}
+	}
+
+	return report, closeReport, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/ozonva/ova-service-api/internal/watch"
+	"github.com/ozonva/ova-service-api/pkg/consumer"
+)
+
+// runWatchSource feeds the watch hub with changes made through every instance, either from the events topic
+// or from the change feed subscribers. The local source is set up by the resolver and needs nothing here.
+// The returned consumer is nil unless the events topic is consumed.
+func runWatchSource(ctx context.Context, env environment, deps *dependencies) (*consumer.Consumer, error) {
+	switch env.WatchSource {
+	case watchSourceLocal:
+		return nil, nil
+	case watchSourceChangeFeed:
+		if deps.Subscribers == nil {
+			return nil, fmt.Errorf("watch source %q requires CHANGE_FEED with CHANGE_FEED_TARGET=%s", watchSourceChangeFeed, feedTargetSubscribers)
+		}
+
+		go deps.Watch.Forward(deps.Subscribers.Subscribe(watch.DefaultBuffer))
+		return nil, nil
+	case watchSourceKafka:
+	default:
+		return nil, fmt.Errorf("unknown watch source: %q", env.WatchSource)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("can't derive watch consumer group from host name: %s", err.Error())
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.IsolationLevel = sarama.ReadCommitted
+	if err = env.Kafka.Apply(saramaConfig); err != nil {
+		return nil, err
+	}
+
+	// Every instance streams all changes, so every instance needs its own consumer group
+	c, err := consumer.New(consumer.Config{
+		Brokers: env.Kafka.Brokers,
+		Topic:   kafkaTopic,
+		GroupID: fmt.Sprintf("%s-%s", watchGroupPrefix, hostname),
+		Sarama:  saramaConfig,
+	}, consumer.NewRouter().HandleOther(deps.Watch.Handle))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if runErr := c.Run(ctx); runErr != nil {
+			log.Printf("watch source stopped: %s", runErr.Error())
+		}
+	}()
+
+	return c, nil
+}
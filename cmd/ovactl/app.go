@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// app is shared by commands, the connection is opened on the first call, so local commands don't need the server
+type app struct {
+	profile profile
+	printer printer
+	conn    *grpc.ClientConn
+}
+
+func (a *app) client(ctx context.Context) (pb.ServiceAPIClient, error) {
+	if a.conn != nil {
+		return pb.NewServiceAPIClient(a.conn), nil
+	}
+
+	opts := []grpc.DialOption{grpc.WithBlock()}
+
+	if a.profile.TLS {
+		tlsConfig, err := a.profile.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	token, err := a.profile.token()
+	if err != nil {
+		return nil, err
+	}
+	if len(token) > 0 {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken{token: token, secure: a.profile.TLS}))
+	}
+
+	conn, err := grpc.DialContext(ctx, a.profile.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to %s: %w", a.profile.Endpoint, err)
+	}
+
+	a.conn = conn
+	return pb.NewServiceAPIClient(conn), nil
+}
+
+func (a *app) close() {
+	if a.conn != nil {
+		_ = a.conn.Close()
+	}
+}
+
+func (p profile) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(p.CAFile) > 0 {
+		ca, err := ioutil.ReadFile(p.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", p.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// bearerToken sends the profile token in the authorization metadata
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+func (t bearerToken) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity lets local profiles send tokens over plaintext connections
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.secure
+}
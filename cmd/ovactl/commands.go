@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// runFunc runs the command with the positional arguments left after the command flags
+type runFunc func(ctx context.Context, app *app, args []string) error
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	// setup registers the command flags and returns the command bound to them
+	setup func(flags *flag.FlagSet) runFunc
+}
+
+// commands are listed in the usage and the shell completion in this order
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "create", usage: "create -user-id <id> [flags]", description: "Create new service", setup: setupCreate},
+		{name: "describe", usage: "describe <service id>", description: "Show service details", setup: setupDescribe},
+		{name: "list", usage: "list [-from <time>] [-to <time>] [-within] [-page-size <n>] [-page-token <token>]", description: "List services, all of them if neither the interval nor the page is set", setup: setupList},
+		{name: "update", usage: "update <service id> [flags]", description: "Update service fields passed as flags, others are kept", setup: setupUpdate},
+		{name: "remove", usage: "remove <service id>...", description: "Remove services", setup: setupRemove},
+		{name: "multicreate", usage: "multicreate [-file <path>]", description: "Create services from JSON lines of create requests", setup: setupMultiCreate},
+		{name: "slots", usage: "slots -name <name>|-address <address> -first-day <date> -last-day <date> -duration <duration> [flags]", description: "Find free slots in working hours", setup: setupSlots},
+		{name: "completion", usage: "completion bash|zsh", description: "Print the shell completion script", setup: setupCompletion},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+
+	return command{}, false
+}
+
+// serviceFlags are shared by create and update
+type serviceFlags struct {
+	userID      *uint64
+	description *string
+	name        *string
+	address     *string
+	when        *string
+	whenLocal   *string
+	timeZone    *string
+	end         *string
+	endLocal    *string
+	duration    *time.Duration
+}
+
+func registerServiceFlags(flags *flag.FlagSet) serviceFlags {
+	return serviceFlags{
+		userID:      flags.Uint64("user-id", 0, "owner of the service"),
+		description: flags.String("description", "", "service description"),
+		name:        flags.String("name", "", "service name"),
+		address:     flags.String("address", "", "service address"),
+		when:        flags.String("when", "", "service time, RFC3339 with the zone offset"),
+		whenLocal:   flags.String("when-local", "", "service time in -time-zone without offset, e.g. 2022-08-31T23:55:00"),
+		timeZone:    flags.String("time-zone", "", "IANA time zone of the service, e.g. Europe/Moscow"),
+		end:         flags.String("end", "", "end of the service, RFC3339 with the zone offset"),
+		endLocal:    flags.String("end-local", "", "end of the service in -time-zone without offset"),
+		duration:    flags.Duration("duration", 0, "duration of the service, sets the end from the service time"),
+	}
+}
+
+// parseTime parses the RFC3339 value of the flag, empty value is nil
+func parseTime(flag string, value string) (*timestamppb.Timestamp, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s: %w", flag, err)
+	}
+
+	return timestamppb.New(t), nil
+}
+
+func toDuration(d time.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(d)
+}
+
+func requireArgs(args []string, count int, usage string) error {
+	if len(args) != count {
+		return fmt.Errorf("usage: ovactl %s", usage)
+	}
+	return nil
+}
+
+func setupCreate(flags *flag.FlagSet) runFunc {
+	service := registerServiceFlags(flags)
+
+	return func(ctx context.Context, app *app, args []string) error {
+		if err := requireArgs(args, 0, "create -user-id <id> [flags]"); err != nil {
+			return err
+		}
+
+		when, err := parseTime("when", *service.when)
+		if err != nil {
+			return err
+		}
+		end, err := parseTime("end", *service.end)
+		if err != nil {
+			return err
+		}
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.CreateServiceV1(ctx, &pb.CreateServiceV1Request{
+			UserId:         *service.userID,
+			Description:    *service.description,
+			ServiceName:    *service.name,
+			ServiceAddress: *service.address,
+			When:           when,
+			WhenLocal:      *service.whenLocal,
+			TimeZone:       *service.timeZone,
+			End:            end,
+			EndLocal:       *service.endLocal,
+			Duration:       toDuration(*service.duration),
+		})
+		if err != nil {
+			return err
+		}
+
+		return app.printer.Print(res)
+	}
+}
+
+func setupDescribe(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, app *app, args []string) error {
+		if err := requireArgs(args, 1, "describe <service id>"); err != nil {
+			return err
+		}
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: args[0]})
+		if err != nil {
+			return err
+		}
+
+		return app.printer.Print(res)
+	}
+}
+
+func setupList(flags *flag.FlagSet) runFunc {
+	from := flags.String("from", "", "list services after the time, RFC3339 with the zone offset")
+	to := flags.String("to", "", "list services before the time, RFC3339 with the zone offset")
+	within := flags.Bool("within", false, "list services entirely inside the interval instead of overlapping ones")
+	pageSize := flags.Uint("page-size", 0, "list the page of services, the server default if only the token is passed")
+	pageToken := flags.String("page-token", "", "list the page after the one which printed the token")
+
+	return func(ctx context.Context, app *app, args []string) error {
+		if err := requireArgs(args, 0, "list [-from <time>] [-to <time>] [-within] [-page-size <n>] [-page-token <token>]"); err != nil {
+			return err
+		}
+
+		req := &pb.ListServicesV1Request{Within: *within, PageSize: uint32(*pageSize), PageToken: *pageToken}
+		var err error
+		if req.From, err = parseTime("from", *from); err != nil {
+			return err
+		}
+		if req.To, err = parseTime("to", *to); err != nil {
+			return err
+		}
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.ListServicesV1(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return app.printer.Print(res)
+	}
+}
+
+// setupUpdate registers the same flags as create. Update replaces all fields of the service,
+// so the current service is loaded first and only the passed flags are changed.
+func setupUpdate(flags *flag.FlagSet) runFunc {
+	service := registerServiceFlags(flags)
+
+	return func(ctx context.Context, app *app, args []string) error {
+		if err := requireArgs(args, 1, "update <service id> [flags]"); err != nil {
+			return err
+		}
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		current, err := client.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: args[0]})
+		if err != nil {
+			return err
+		}
+
+		req := &pb.UpdateServiceV1Request{
+			ServiceId:      current.ServiceId,
+			UserId:         current.UserId,
+			Description:    current.Description,
+			ServiceName:    current.ServiceName,
+			ServiceAddress: current.ServiceAddress,
+			When:           current.When,
+			TimeZone:       current.TimeZone,
+			End:            current.End,
+		}
+
+		var parseErr error
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "user-id":
+				req.UserId = *service.userID
+			case "description":
+				req.Description = *service.description
+			case "name":
+				req.ServiceName = *service.name
+			case "address":
+				req.ServiceAddress = *service.address
+			case "when":
+				var err error
+				if req.When, err = parseTime("when", *service.when); err != nil {
+					parseErr = err
+				}
+			case "when-local":
+				// The local time replaces the current one, which is the instant and would keep it otherwise
+				req.When, req.WhenLocal = nil, *service.whenLocal
+			case "time-zone":
+				req.TimeZone = *service.timeZone
+			case "end":
+				var err error
+				if req.End, err = parseTime("end", *service.end); err != nil {
+					parseErr = err
+				}
+			case "end-local":
+				req.End, req.EndLocal = nil, *service.endLocal
+			case "duration":
+				req.End, req.Duration = nil, toDuration(*service.duration)
+			}
+		})
+		if parseErr != nil {
+			return parseErr
+		}
+
+		if _, err = client.UpdateServiceV1(ctx, req); err != nil {
+			return err
+		}
+
+		updated, err := client.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: args[0]})
+		if err != nil {
+			return err
+		}
+
+		return app.printer.Print(updated)
+	}
+}
+
+func setupRemove(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, app *app, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: ovactl remove <service id>...")
+		}
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range args {
+			if _, err = client.RemoveServiceV1(ctx, &pb.RemoveServiceV1Request{ServiceId: id}); err != nil {
+				return fmt.Errorf("can't remove service %s: %w", id, err)
+			}
+			fmt.Fprintf(os.Stderr, "Service %s is removed\n", id)
+		}
+
+		return nil
+	}
+}
+
+func setupSlots(flags *flag.FlagSet) runFunc {
+	name := flags.String("name", "", "find slots of services with the name")
+	address := flags.String("address", "", "find slots of services at the address")
+	firstDay := flags.String("first-day", "", "first date of the range, e.g. 2030-08-31")
+	lastDay := flags.String("last-day", "", "last date of the range, the first one by default")
+	timeZone := flags.String("time-zone", "", "IANA time zone of dates and working hours, e.g. Europe/Moscow")
+	workStart := flags.String("work-start", "09:00", "start of working hours")
+	workEnd := flags.String("work-end", "18:00", "end of working hours")
+	duration := flags.Duration("duration", 0, "slot duration")
+	step := flags.Duration("step", 0, "step between slot starts, the slot duration or 5m for shorter slots by default")
+	buffer := flags.Duration("buffer", 0, "free time around booked services, the server buffer by default")
+
+	return func(ctx context.Context, app *app, args []string) error {
+		usage := "slots -name <name>|-address <address> -first-day <date> -last-day <date> -duration <duration> [flags]"
+		if err := requireArgs(args, 0, usage); err != nil {
+			return err
+		}
+
+		req := &pb.FindAvailableSlotsV1Request{
+			ServiceName:    *name,
+			ServiceAddress: *address,
+			FirstDay:       *firstDay,
+			LastDay:        *lastDay,
+			TimeZone:       *timeZone,
+			WorkStart:      *workStart,
+			WorkEnd:        *workEnd,
+			SlotDuration:   durationpb.New(*duration),
+			Step:           toDuration(*step),
+		}
+		if len(req.LastDay) == 0 {
+			req.LastDay = req.FirstDay
+		}
+		flags.Visit(func(f *flag.Flag) {
+			// Zero buffer passed explicitly overrides the server one
+			if f.Name == "buffer" {
+				req.Buffer = durationpb.New(*buffer)
+			}
+		})
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.FindAvailableSlotsV1(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return app.printer.Print(res)
+	}
+}
+
+// setupMultiCreate reads create requests in the JSON format of the HTTP API, one per line
+func setupMultiCreate(flags *flag.FlagSet) runFunc {
+	file := flags.String("file", "-", "JSON lines file of create requests, - is stdin")
+
+	return func(ctx context.Context, app *app, args []string) error {
+		if err := requireArgs(args, 0, "multicreate [-file <path>]"); err != nil {
+			return err
+		}
+
+		var in io.Reader = os.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		req, err := readCreateRequests(in)
+		if err != nil {
+			return err
+		}
+
+		client, err := app.client(ctx)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.MultiCreateServiceV1(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return app.printer.Print(res)
+	}
+}
+
+func readCreateRequests(in io.Reader) (*pb.MultiCreateServiceV1Request, error) {
+	req := &pb.MultiCreateServiceV1Request{}
+
+	scanner := bufio.NewScanner(in)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		}
+
+		var create pb.CreateServiceV1Request
+		if err := protojson.Unmarshal([]byte(text), &create); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		req.CreateService = append(req.CreateService, &create)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// setupCompletion prints the completion script built from the command table, so it never lags behind commands.
+// zsh reuses the bash script through bashcompinit.
+//
+//	source <(ovactl completion bash)
+func setupCompletion(_ *flag.FlagSet) runFunc {
+	return func(_ context.Context, _ *app, args []string) error {
+		if err := requireArgs(args, 1, "completion bash|zsh"); err != nil {
+			return err
+		}
+
+		script := bashCompletion()
+		switch args[0] {
+		case "bash":
+		case "zsh":
+			script = "autoload -U +X bashcompinit && bashcompinit\n" + script
+		default:
+			return fmt.Errorf("unsupported shell %q, use bash or zsh", args[0])
+		}
+
+		_, err := fmt.Print(script)
+		return err
+	}
+}
+
+func bashCompletion() string {
+	global := flag.NewFlagSet("ovactl", flag.ContinueOnError)
+	registerGlobalFlags(global)
+
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+
+	var script bytes.Buffer
+	script.WriteString(`_ovactl() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}" cmd="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            ` + strings.Join(flagNames(global), "|") + `) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+    case "$prev" in
+        -output) COMPREPLY=($(compgen -W "` + strings.Join([]string{formatTable, formatJSON, formatYAML}, " ") + `" -- "$cur")); return ;;
+        -file) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+    esac
+    case "$cmd" in
+        "") COMPREPLY=($(compgen -W "` + strings.Join(append(flagNames(global), names...), " ") + `" -- "$cur")) ;;
+`)
+
+	for _, cmd := range commands {
+		flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		cmd.setup(flags)
+
+		words := flagNames(flags)
+		if cmd.name == "completion" {
+			words = []string{"bash", "zsh"}
+		}
+		fmt.Fprintf(&script, "        %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", cmd.name, strings.Join(words, " "))
+	}
+
+	script.WriteString(`    esac
+}
+complete -F _ovactl ovactl
+`)
+
+	return script.String()
+}
+
+func flagNames(flags *flag.FlagSet) []string {
+	var names []string
+	flags.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configEnv       = "OVACTL_CONFIG"
+	profileEnv      = "OVACTL_PROFILE"
+	defaultEndpoint = "localhost:8082"
+	defaultProfile  = "default"
+)
+
+// config is the YAML file with connection profiles:
+//
+//	current_profile: staging
+//	profiles:
+//	  staging:
+//	    endpoint: ova-staging.example.com:443
+//	    tls: true
+//	    token_file: ~/.ova/staging.token
+type config struct {
+	CurrentProfile string             `yaml:"current_profile"`
+	Profiles       map[string]profile `yaml:"profiles"`
+}
+
+type profile struct {
+	Endpoint string `yaml:"endpoint"`
+	// Output is the default output format: table, json or yaml
+	Output string `yaml:"output"`
+	TLS    bool   `yaml:"tls"`
+	// CAFile is the PEM bundle of the server certificate authorities, system roots are used if it is empty
+	CAFile string `yaml:"ca_file"`
+	// Token is sent as the bearer token with every call, TokenFile keeps it out of the config
+	Token     string `yaml:"token"`
+	TokenFile string `yaml:"token_file"`
+}
+
+func defaultConfigPath() string {
+	if path, ok := os.LookupEnv(configEnv); ok {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "ovactl", "config.yaml")
+}
+
+// loadProfile returns the named profile or the current one of the config. The local insecure endpoint is used
+// if the config doesn't exist and no profile is requested.
+func loadProfile(path string, name string) (profile, error) {
+	defaults := profile{Endpoint: defaultEndpoint, Output: formatTable}
+
+	var cfg config
+	if len(path) > 0 {
+		data, err := ioutil.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return profile{}, err
+		}
+		if err = yaml.Unmarshal(data, &cfg); err != nil {
+			return profile{}, fmt.Errorf("can't parse config %s: %w", path, err)
+		}
+	}
+
+	if len(name) == 0 {
+		name = cfg.CurrentProfile
+	}
+	if len(name) == 0 {
+		name = defaultProfile
+	}
+
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		if name == defaultProfile {
+			return defaults, nil
+		}
+		return profile{}, fmt.Errorf("profile %q is not found in %s", name, path)
+	}
+
+	if len(p.Endpoint) == 0 {
+		p.Endpoint = defaults.Endpoint
+	}
+	if len(p.Output) == 0 {
+		p.Output = defaults.Output
+	}
+
+	return p, nil
+}
+
+func (p profile) token() (string, error) {
+	if len(p.TokenFile) == 0 {
+		return p.Token, nil
+	}
+
+	path := p.TokenFile
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("can't read token file: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,115 @@
+// ovactl is the command-line client of the ServiceAPI
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+func main() {
+	err := run(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ovactl: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	global := flag.NewFlagSet("ovactl", flag.ContinueOnError)
+	globals := registerGlobalFlags(global)
+	global.Usage = func() {
+		printUsage(global)
+	}
+
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+
+	if global.NArg() == 0 {
+		printUsage(global)
+		return fmt.Errorf("command is required")
+	}
+
+	cmd, ok := findCommand(global.Arg(0))
+	if !ok {
+		return fmt.Errorf("unknown command %q, run ovactl -help to list commands", global.Arg(0))
+	}
+
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ovactl %s\n\n%s\n\n", cmd.usage, cmd.description)
+		flags.PrintDefaults()
+	}
+	runCommand := cmd.setup(flags)
+	if err := flags.Parse(global.Args()[1:]); err != nil {
+		return err
+	}
+
+	profile, err := loadProfile(*globals.config, *globals.profile)
+	if err != nil {
+		return err
+	}
+	if len(*globals.endpoint) > 0 {
+		profile.Endpoint = *globals.endpoint
+	}
+	if len(*globals.output) > 0 {
+		profile.Output = *globals.output
+	}
+
+	printer, err := newPrinter(profile.Output, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	app := &app{profile: profile, printer: printer}
+	defer app.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *globals.timeout)
+	defer cancel()
+
+	return runCommand(ctx, app, flags.Args())
+}
+
+// globalFlags go before the command name
+type globalFlags struct {
+	config   *string
+	profile  *string
+	endpoint *string
+	output   *string
+	timeout  *time.Duration
+}
+
+func registerGlobalFlags(flags *flag.FlagSet) globalFlags {
+	return globalFlags{
+		config:   flags.String("config", defaultConfigPath(), "config file with profiles, "+configEnv+" overrides the default"),
+		profile:  flags.String("profile", os.Getenv(profileEnv), "profile to use instead of the current one of the config"),
+		endpoint: flags.String("endpoint", "", "gRPC endpoint, overrides the profile one"),
+		output:   flags.String("output", "", "table, json or yaml, overrides the profile one"),
+		timeout:  flags.Duration("timeout", defaultTimeout, "deadline of the command"),
+	}
+}
+
+func printUsage(global *flag.FlagSet) {
+	out := global.Output()
+	fmt.Fprintf(out, "Usage: ovactl [flags] <command> [command flags]\n\nCommands:\n")
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, strings.SplitN(cmd.description, "\n", 2)[0])
+	}
+	_ = w.Flush()
+
+	fmt.Fprintf(out, "\nFlags:\n")
+	global.PrintDefaults()
+}
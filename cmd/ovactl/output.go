@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+type printer interface {
+	Print(message proto.Message) error
+}
+
+func newPrinter(format string, out io.Writer) (printer, error) {
+	switch format {
+	case formatTable:
+		return &tablePrinter{out: out}, nil
+	case formatJSON:
+		return &jsonPrinter{out: out}, nil
+	case formatYAML:
+		return &yamlPrinter{out: out}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, use table, json or yaml", format)
+	}
+}
+
+// marshalOptions keep field names of the proto file, so JSON and YAML outputs match the HTTP API
+var marshalOptions = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+type jsonPrinter struct {
+	out io.Writer
+}
+
+func (p *jsonPrinter) Print(message proto.Message) error {
+	options := marshalOptions
+	options.Multiline = true
+
+	data, err := options.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(p.out, string(data))
+	return err
+}
+
+type yamlPrinter struct {
+	out io.Writer
+}
+
+// Print converts the proto JSON to YAML, so well-known types are rendered the same way as in JSON
+func (p *yamlPrinter) Print(message proto.Message) error {
+	data, err := marshalOptions.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(p.out)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(value); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+type tablePrinter struct {
+	out io.Writer
+}
+
+func (p *tablePrinter) Print(message proto.Message) error {
+	w := tabwriter.NewWriter(p.out, 0, 4, 2, ' ', 0)
+
+	switch m := message.(type) {
+	case *pb.ListServicesV1Response:
+		fmt.Fprintln(w, "SERVICE ID\tUSER ID\tNAME\tWHEN\tEND")
+		for _, info := range m.ServiceShortInfo {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", info.ServiceId, info.UserId, info.ServiceName,
+				formatLocalTime(info.WhenLocal, info.When), formatLocalTime(info.EndLocal, info.End))
+		}
+		if len(m.NextPageToken) > 0 {
+			fmt.Fprintf(w, "Next page token:\t%s\n", m.NextPageToken)
+		}
+	case *pb.DescribeServiceV1Response:
+		fmt.Fprintf(w, "Service ID:\t%s\n", m.ServiceId)
+		fmt.Fprintf(w, "User ID:\t%d\n", m.UserId)
+		fmt.Fprintf(w, "Name:\t%s\n", m.ServiceName)
+		fmt.Fprintf(w, "Address:\t%s\n", m.ServiceAddress)
+		fmt.Fprintf(w, "Description:\t%s\n", m.Description)
+		fmt.Fprintf(w, "Time zone:\t%s\n", m.TimeZone)
+		fmt.Fprintf(w, "When:\t%s\n", formatLocalTime(m.WhenLocal, m.When))
+		fmt.Fprintf(w, "When UTC:\t%s\n", formatTime(m.WhenUtc))
+		fmt.Fprintf(w, "End:\t%s\n", formatLocalTime(m.EndLocal, m.End))
+	case *pb.FindAvailableSlotsV1Response:
+		fmt.Fprintf(w, "START (%s)\tEND\n", m.TimeZone)
+		for _, slot := range m.Slots {
+			fmt.Fprintf(w, "%s\t%s\n", formatLocalTime(slot.StartLocal, slot.Start), formatLocalTime(slot.EndLocal, slot.End))
+		}
+	case *pb.CreateServiceV1Response:
+		fmt.Fprintln(w, "SERVICE ID")
+		fmt.Fprintln(w, m.ServiceId)
+	case *pb.MultiCreateServiceV1Response:
+		fmt.Fprintln(w, "SERVICE ID")
+		for _, id := range m.ServiceId {
+			fmt.Fprintln(w, id)
+		}
+	default:
+		data, err := marshalOptions.Marshal(message)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	}
+
+	return w.Flush()
+}
+
+// formatLocalTime prefers the time in the zone of the service, servers without time zones send the timestamp only
+func formatLocalTime(local string, ts *timestamppb.Timestamp) string {
+	if len(local) > 0 {
+		return local
+	}
+	return formatTime(ts)
+}
+
+func formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return "-"
+	}
+
+	return ts.AsTime().Local().Format(timeLayout)
+}
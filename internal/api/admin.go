@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AuthorizationHeader carries the admin token of admin RPCs, the HTTP gateway forwards it as metadata
+	AuthorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+)
+
+// WithAdminToken allows admin RPCs to callers passing the token as "Authorization: Bearer <token>".
+// Admin RPCs are denied to everyone without the token.
+func (s *GrpcApiServer) WithAdminToken(token string) *GrpcApiServer {
+	s.adminToken = token
+	return s
+}
+
+func (s *GrpcApiServer) authorizeAdmin(ctx context.Context) error {
+	if len(s.adminToken) == 0 {
+		return status.Errorf(codes.PermissionDenied, "Admin RPCs are disabled, admin token is not configured")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(AuthorizationHeader)
+	if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return status.Errorf(codes.Unauthenticated, "Admin token is required")
+	}
+
+	token := strings.TrimPrefix(values[0], bearerPrefix)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		return status.Errorf(codes.PermissionDenied, "Admin token is not valid")
+	}
+
+	return nil
+}
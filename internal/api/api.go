@@ -2,14 +2,21 @@ package api
 
 import (
 	"context"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
 	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/projection"
+	"github.com/ozonva/ova-service-api/internal/repo"
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
 type Metrics interface {
 	IncrementCreateCounter()
 	IncrementMultiCreateCounter()
+	IncrementImportCounter()
 	IncrementUpdateCounter()
 	IncrementRemoveCounter()
 }
@@ -22,34 +29,120 @@ type MultiCreateFlusher interface {
 	Flush(ctx context.Context, services []models.Service) []models.Service
 }
 
-type KafkaProducer interface {
-	SendMessage(message string) error
-	SendMessages(messages []string) error
+type EventPublisher interface {
+	Publish(message eventbus.Message) error
+	PublishBatch(messages []eventbus.Message) error
+}
+
+type EventEncoder interface {
+	Encode(event events.ServiceCUDEvent) (eventbus.Message, error)
 }
 
 type Repo interface {
 	AddServices(services []models.Service) error
 	ListServices(limit, offset uint64) ([]models.Service, error)
+	ListServicesByInterval(filter models.IntervalFilter, limit, offset uint64) ([]models.Service, error)
 	DescribeService(serviceID uuid.UUID) (*models.Service, error)
 	RemoveService(serviceID uuid.UUID) error
 	UpdateService(service *models.Service) error
+	ListServicesForReplay(filter repo.ReplayFilter) ([]models.Service, error)
+	ExportServices(filter models.IntervalFilter, limit, offset uint64, fn func(service models.Service) error) error
+	ListServicesAfter(cursor *repo.ServiceCursor, limit uint64) ([]models.Service, error)
+}
+
+// ServiceReader is the read model used by the query RPCs instead of the primary repo. User calendars and counters
+// are served from the read model only.
+type ServiceReader interface {
+	ListServices(limit, offset uint64) ([]models.Service, error)
+	ListServicesByInterval(filter models.IntervalFilter, limit, offset uint64) ([]models.Service, error)
+	DescribeService(serviceID uuid.UUID) (*models.Service, error)
+	UserCalendar(userID uint64, from, to time.Time) ([]models.Service, error)
+	UserCounters(userID uint64) (projection.UserCounters, error)
 }
 
 type GrpcApiServer struct {
 	pb.UnimplementedServiceAPIServer
-	repo     Repo
-	saver    DelayedSaver
-	flusher  MultiCreateFlusher
-	producer KafkaProducer
-	metrics  Metrics
+	repo      Repo
+	saver     DelayedSaver
+	flusher   MultiCreateFlusher
+	publisher EventPublisher
+	// writePublisher receives events of the write handlers, see WithWritePublisher
+	writePublisher EventPublisher
+	encoder        EventEncoder
+	metrics        Metrics
+	readModel      ServiceReader
+	// ids generates IDs of created services
+	ids models.IDGenerator
+	// idempotency is nil if idempotency keys are ignored
+	idempotency IdempotencyStore
+	// conflictDetection makes creates write through the repo, see WithConflictDetection
+	conflictDetection bool
+	// slotBuffer is the default buffer of FindAvailableSlotsV1
+	slotBuffer time.Duration
+	// adminToken is required by admin RPCs, they are denied if it is empty
+	adminToken string
+
+	watcher           Watcher
+	heartbeatInterval time.Duration
 }
 
-func NewGrpcApiServer(repo Repo, saver DelayedSaver, flusher MultiCreateFlusher, producer KafkaProducer, metrics Metrics) *GrpcApiServer {
+func NewGrpcApiServer(repo Repo, saver DelayedSaver, flusher MultiCreateFlusher, publisher EventPublisher, encoder EventEncoder, metrics Metrics) *GrpcApiServer {
 	return &GrpcApiServer{
-		repo:     repo,
-		saver:    saver,
-		flusher:  flusher,
-		producer: producer,
-		metrics:  metrics,
+		repo:           repo,
+		saver:          saver,
+		flusher:        flusher,
+		publisher:      publisher,
+		writePublisher: publisher,
+		encoder:        encoder,
+		metrics:        metrics,
+		ids:            models.UUIDv7,
+	}
+}
+
+// WithIDGenerator replaces the generator of IDs of created services, UUIDv7 is used by default
+func (s *GrpcApiServer) WithIDGenerator(ids models.IDGenerator) *GrpcApiServer {
+	s.ids = ids
+	return s
+}
+
+// WithWritePublisher sends events of Create, MultiCreate, Update, Remove and Import to the publisher instead of
+// the one of the server, e.g. to eventbus.Discard if the change feed produces them. Replayed events are not
+// produced by writes, so ReplayEventsV1 keeps publishing to the publisher of the server.
+func (s *GrpcApiServer) WithWritePublisher(publisher EventPublisher) *GrpcApiServer {
+	s.writePublisher = publisher
+	return s
+}
+
+// WithReadModel makes List and Describe read from the read model and enables GetUserCalendar and GetUserCounters. The read model is eventually consistent,
+// so Describe falls back to the repo for services which are not projected yet.
+func (s *GrpcApiServer) WithReadModel(readModel ServiceReader) *GrpcApiServer {
+	s.readModel = readModel
+	return s
+}
+
+func (s *GrpcApiServer) listServices(limit, offset uint64) ([]models.Service, error) {
+	if s.readModel != nil {
+		return s.readModel.ListServices(limit, offset)
 	}
+
+	return s.repo.ListServices(limit, offset)
+}
+
+func (s *GrpcApiServer) listServicesByInterval(filter models.IntervalFilter, limit, offset uint64) ([]models.Service, error) {
+	if s.readModel != nil {
+		return s.readModel.ListServicesByInterval(filter, limit, offset)
+	}
+
+	return s.repo.ListServicesByInterval(filter, limit, offset)
+}
+
+func (s *GrpcApiServer) describeService(serviceID uuid.UUID) (*models.Service, error) {
+	if s.readModel != nil {
+		service, err := s.readModel.DescribeService(serviceID)
+		if err == nil {
+			return service, nil
+		}
+	}
+
+	return s.repo.DescribeService(serviceID)
 }
@@ -3,29 +3,41 @@ package api_test
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
 	"github.com/ozonva/ova-service-api/internal/mocks"
 	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/projection"
+	"github.com/ozonva/ova-service-api/internal/repo"
+	"github.com/ozonva/ova-service-api/internal/requestid"
 
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
 var _ = Describe("Api", func() {
 	var (
-		ctx          context.Context
-		ctrl         *gomock.Controller
-		repoMock     *mocks.MockRepo
-		flusherMock  *mocks.MockFlusher
-		saverMock    *mocks.MockSaver
-		producerMock *mocks.MockProducer
-		metricsMock  *mocks.MockMetrics
+		ctx           context.Context
+		ctrl          *gomock.Controller
+		repoMock      *mocks.MockRepo
+		flusherMock   *mocks.MockFlusher
+		saverMock     *mocks.MockSaver
+		publisherMock *mocks.MockPublisher
+		metricsMock   *mocks.MockMetrics
+		encoder       events.Encoder
 
 		carServiceID string
 		carService   models.Service
@@ -39,8 +51,9 @@ var _ = Describe("Api", func() {
 		repoMock = mocks.NewMockRepo(ctrl)
 		flusherMock = mocks.NewMockFlusher(ctrl)
 		saverMock = mocks.NewMockSaver(ctrl)
-		producerMock = mocks.NewMockProducer(ctrl)
+		publisherMock = mocks.NewMockPublisher(ctrl)
 		metricsMock = mocks.NewMockMetrics(ctrl)
+		encoder, _ = events.NewEncoder(events.EncoderConfig{})
 
 		carServiceID = "d6fa505c-6072-4a45-bdae-86e6b13d7342"
 		carService = models.Service{
@@ -63,7 +76,7 @@ var _ = Describe("Api", func() {
 		Context("on calling Create endpoint", func() {
 			When("request body is empty", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().AddServices(gomock.Any()).Times(0)
 
 					_, err := server.CreateServiceV1(ctx, nil)
@@ -74,7 +87,7 @@ var _ = Describe("Api", func() {
 
 			When("request body contains illegal service data", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().AddServices(gomock.Any()).Times(0)
 
 					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 0})
@@ -85,7 +98,7 @@ var _ = Describe("Api", func() {
 
 			When("saver returns error", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					saverMock.EXPECT().Save(gomock.Any()).
 						Return(fmt.Errorf("saver error")).Times(1)
 
@@ -95,12 +108,12 @@ var _ = Describe("Api", func() {
 				})
 			})
 
-			When("producer returns error", func() {
+			When("publisher returns error", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					saverMock.EXPECT().Save(gomock.Any()).Times(1)
-					producerMock.EXPECT().SendMessage(gomock.Any()).
-						Return(fmt.Errorf("producer error")).Times(1)
+					publisherMock.EXPECT().Publish(gomock.Any()).
+						Return(fmt.Errorf("publisher error")).Times(1)
 
 					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1})
 
@@ -110,10 +123,10 @@ var _ = Describe("Api", func() {
 
 			When("valid request", func() {
 				It("should return serviceID", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					saverMock.EXPECT().Save(gomock.Any()).
 						Return(nil).Times(1)
-					producerMock.EXPECT().SendMessage(gomock.Any()).
+					publisherMock.EXPECT().Publish(gomock.Any()).
 						Return(nil).Times(1)
 					metricsMock.EXPECT().IncrementCreateCounter().Times(1)
 
@@ -125,10 +138,164 @@ var _ = Describe("Api", func() {
 			})
 		})
 
+		Context("on passing service time zone", func() {
+			When("local time is passed with the zone", func() {
+				It("should save the instant of the local time in the zone", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+					metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+					var saved models.Service
+					saverMock.EXPECT().Save(gomock.Any()).
+						DoAndReturn(func(service models.Service) error {
+							saved = service
+							return nil
+						}).Times(1)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{
+						UserId:    1,
+						TimeZone:  "America/New_York",
+						WhenLocal: "2040-07-15T10:00:00",
+					})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(saved.TimeZone).Should(Equal("America/New_York"))
+					Expect(saved.WhenUTC.Format(time.RFC3339)).Should(Equal("2040-07-15T14:00:00Z"))
+				})
+			})
+
+			When("zone is unknown", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					saverMock.EXPECT().Save(gomock.Any()).Times(0)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1, TimeZone: "Europe/Atlantis"})
+
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+
+			When("local time is skipped by the DST transition", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					saverMock.EXPECT().Save(gomock.Any()).Times(0)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{
+						UserId:    1,
+						TimeZone:  "America/New_York",
+						WhenLocal: "2040-03-11T02:30:00",
+					})
+
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+
+			When("service is described", func() {
+				It("should return the local time in the zone of the service", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					when := time.Date(2040, 1, 15, 7, 0, 0, 0, time.UTC)
+					Expect(carService.RestoreCalendar(&when, nil, "Asia/Tokyo")).Should(Succeed())
+					repoMock.EXPECT().DescribeService(gomock.Any()).Return(&carService, nil).Times(1)
+
+					res, err := server.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: carServiceID})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.TimeZone).Should(Equal("Asia/Tokyo"))
+					Expect(res.WhenLocal).Should(Equal("2040-01-15T16:00:00+09:00"))
+					Expect(res.WhenUtc.AsTime()).Should(BeTemporally("==", when))
+				})
+			})
+		})
+
+		Context("on passing service end", func() {
+			When("duration is passed", func() {
+				It("should save the end after the duration from the service time", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+					metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+					var saved models.Service
+					saverMock.EXPECT().Save(gomock.Any()).
+						DoAndReturn(func(service models.Service) error {
+							saved = service
+							return nil
+						}).Times(1)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{
+						UserId:    1,
+						TimeZone:  "Europe/Moscow",
+						WhenLocal: "2040-07-15T10:00:00",
+						Duration:  durationpb.New(90 * time.Minute),
+					})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(saved.EndUTC.Format(time.RFC3339)).Should(Equal("2040-07-15T08:30:00Z"))
+					Expect(saved.Duration()).Should(Equal(90 * time.Minute))
+				})
+			})
+
+			When("end is not after the service time", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					saverMock.EXPECT().Save(gomock.Any()).Times(0)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{
+						UserId:    1,
+						WhenLocal: "2040-07-15T10:00:00",
+						EndLocal:  "2040-07-15T09:00:00",
+					})
+
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+
+			When("both end and duration are passed", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					saverMock.EXPECT().Save(gomock.Any()).Times(0)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{
+						UserId:    1,
+						WhenLocal: "2040-07-15T10:00:00",
+						EndLocal:  "2040-07-15T11:00:00",
+						Duration:  durationpb.New(time.Hour),
+					})
+
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+		})
+
+		Context("on producing events", func() {
+			When("request ID is present in the context", func() {
+				It("should pass request ID and event metadata in message headers", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					saverMock.EXPECT().Save(gomock.Any()).Return(nil).Times(1)
+					metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+					var produced eventbus.Message
+					publisherMock.EXPECT().Publish(gomock.Any()).
+						DoAndReturn(func(message eventbus.Message) error {
+							produced = message
+							return nil
+						}).Times(1)
+
+					requestCtx := requestid.NewContext(ctx, "request-1")
+					res, err := server.CreateServiceV1(requestCtx, &pb.CreateServiceV1Request{UserId: 1})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(produced.Key).Should(BeEquivalentTo(res.ServiceId))
+					Expect(produced.Headers).Should(HaveKeyWithValue(requestid.Header, "request-1"))
+					Expect(produced.Headers).Should(HaveKeyWithValue(events.EventTypeHeader, "SERVICE_EVENT_TYPE_CREATED"))
+					Expect(produced.Headers).Should(HaveKeyWithValue(events.SchemaVersionHeader, events.SchemaVersion))
+				})
+			})
+		})
+
 		Context("on calling Describe endpoint", func() {
 			When("request body is empty", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().DescribeService(gomock.Any()).Times(0)
 
 					_, err := server.DescribeServiceV1(ctx, nil)
@@ -139,7 +306,7 @@ var _ = Describe("Api", func() {
 
 			When("can't parse serviceID", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().DescribeService(gomock.Any()).Times(0)
 
 					_, err := server.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: "bad uuid"})
@@ -150,7 +317,7 @@ var _ = Describe("Api", func() {
 
 			When("service not found", func() {
 				It("should return NotFound error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().DescribeService(gomock.Any()).
 						Return(nil, fmt.Errorf("not found")).Times(1)
 
@@ -162,7 +329,7 @@ var _ = Describe("Api", func() {
 
 			When("can't map service model to response", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().DescribeService(gomock.Any()).Return(nil, nil).Times(1)
 
 					_, err := server.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: carServiceID})
@@ -173,7 +340,7 @@ var _ = Describe("Api", func() {
 
 			When("valid request", func() {
 				It("should return service", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().DescribeService(gomock.Any()).Return(&carService, nil).Times(1)
 
 					res, err := server.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: carServiceID})
@@ -187,11 +354,11 @@ var _ = Describe("Api", func() {
 		Context("on calling List endpoint", func() {
 			When("error occurs in repo", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().ListServices(gomock.Any(), gomock.Any()).
 						Return(nil, fmt.Errorf("repo error")).Times(1)
 
-					_, err := server.ListServicesV1(ctx, &empty.Empty{})
+					_, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{})
 
 					Expect(err).Should(HaveOccurred())
 				})
@@ -199,11 +366,11 @@ var _ = Describe("Api", func() {
 
 			When("valid request", func() {
 				It("should return list of services", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().ListServices(gomock.Any(), gomock.Any()).
 						Return([]models.Service{carService, carService}, nil).Times(1)
 
-					res, err := server.ListServicesV1(ctx, &empty.Empty{})
+					res, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{})
 
 					Expect(err).ShouldNot(HaveOccurred())
 					Expect(len(res.ServiceShortInfo)).Should(BeEquivalentTo(2))
@@ -213,10 +380,156 @@ var _ = Describe("Api", func() {
 			})
 		})
 
+		Context("on calling List endpoint with interval", func() {
+			When("interval is set", func() {
+				It("should list services by the interval", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					from := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+					to := from.AddDate(0, 1, 0)
+					repoMock.EXPECT().ListServices(gomock.Any(), gomock.Any()).Times(0)
+					repoMock.EXPECT().
+						ListServicesByInterval(models.IntervalFilter{From: from, To: to, Within: true}, gomock.Any(), gomock.Any()).
+						Return([]models.Service{carService}, nil).Times(1)
+
+					res, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{
+						From:   timestamppb.New(from),
+						To:     timestamppb.New(to),
+						Within: true,
+					})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.ServiceShortInfo).Should(HaveLen(1))
+				})
+			})
+
+			When("interval ends before its start", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					from := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+					repoMock.EXPECT().ListServicesByInterval(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					_, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{
+						From: timestamppb.New(from),
+						To:   timestamppb.New(from.Add(-time.Hour)),
+					})
+
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+		})
+
+		Context("on calling List endpoint with pages", func() {
+			// Services of the same millisecond are ordered by ID, so the cursor keeps both
+			when := time.Date(2040, 1, 1, 10, 0, 0, 123456000, time.UTC)
+			later := when.Add(time.Hour)
+			first := models.Service{ID: uuid.MustParse("0190f1c0-0000-7000-8000-000000000001"), UserID: 1, WhenUTC: &later}
+			second := models.Service{ID: uuid.MustParse("0190f1c0-0000-7000-8000-000000000002"), UserID: 1, WhenUTC: &when}
+			third := models.Service{ID: uuid.MustParse("0190f1c0-0000-7000-8000-000000000003"), UserID: 1, WhenUTC: &when}
+
+			When("more services follow the page", func() {
+				It("should return the token of the last service of the page", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().ListServicesAfter(nil, uint64(3)).
+						Return([]models.Service{first, second, third}, nil).Times(1)
+
+					res, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{PageSize: 2})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.ServiceShortInfo).Should(HaveLen(2))
+					Expect(res.ServiceShortInfo[1].ServiceId).Should(Equal(second.ID.String()))
+
+					cursor, err := repo.ParseServiceCursor(res.NextPageToken)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(cursor.ID).Should(Equal(second.ID))
+					Expect(*cursor.WhenUTC).Should(BeTemporally("==", when))
+				})
+			})
+
+			When("token of the page ending in the middle of the same time is passed", func() {
+				It("should continue after its service and return no token for the last page", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().ListServicesAfter(gomock.Any(), uint64(3)).
+						DoAndReturn(func(cursor *repo.ServiceCursor, limit uint64) ([]models.Service, error) {
+							Expect(cursor.ID).Should(Equal(second.ID))
+							Expect(*cursor.WhenUTC).Should(BeTemporally("==", when))
+							return []models.Service{third}, nil
+						}).Times(1)
+
+					res, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{
+						PageSize:  2,
+						PageToken: repo.CursorAfter(second).Token(),
+					})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.ServiceShortInfo).Should(HaveLen(1))
+					Expect(res.ServiceShortInfo[0].ServiceId).Should(Equal(third.ID.String()))
+					Expect(res.NextPageToken).Should(BeEmpty())
+				})
+			})
+
+			When("only the token is passed", func() {
+				It("should use the default page size", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().ListServicesAfter(gomock.Any(), uint64(101)).Return(nil, nil).Times(1)
+
+					_, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{PageToken: repo.CursorAfter(first).Token()})
+
+					Expect(err).ShouldNot(HaveOccurred())
+				})
+			})
+
+			When("token is not valid or pages are combined with the interval", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().ListServicesAfter(gomock.Any(), gomock.Any()).Times(0)
+
+					_, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{PageToken: "not a token"})
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+
+					_, err = server.ListServicesV1(ctx, &pb.ListServicesV1Request{PageSize: 2, From: timestamppb.New(when)})
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+		})
+
+		Context("on calling query endpoints with read model", func() {
+			When("service is projected", func() {
+				It("should read it from the read model", func() {
+					readModel := projection.NewMemoryStore()
+					Expect(readModel.Upsert(carService)).Should(Succeed())
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithReadModel(readModel)
+					repoMock.EXPECT().DescribeService(gomock.Any()).Times(0)
+					repoMock.EXPECT().ListServices(gomock.Any(), gomock.Any()).Times(0)
+
+					described, err := server.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: carServiceID})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(described.ServiceId).Should(BeEquivalentTo(carServiceID))
+
+					listed, err := server.ListServicesV1(ctx, &pb.ListServicesV1Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(len(listed.ServiceShortInfo)).Should(BeEquivalentTo(1))
+				})
+			})
+
+			When("service is not projected yet", func() {
+				It("should describe it from repo", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithReadModel(projection.NewMemoryStore())
+					repoMock.EXPECT().DescribeService(carService.ID).Return(&carService, nil).Times(1)
+
+					res, err := server.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: carServiceID})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.ServiceId).Should(BeEquivalentTo(carServiceID))
+				})
+			})
+		})
+
 		Context("on calling Remove endpoint", func() {
 			When("request body is empty", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().RemoveService(gomock.Any()).Times(0)
 
 					_, err := server.RemoveServiceV1(ctx, nil)
@@ -227,7 +540,7 @@ var _ = Describe("Api", func() {
 
 			When("can't parse serviceID", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().RemoveService(gomock.Any()).Times(0)
 
 					_, err := server.RemoveServiceV1(ctx, &pb.RemoveServiceV1Request{ServiceId: "bad uuid"})
@@ -238,7 +551,21 @@ var _ = Describe("Api", func() {
 
 			When("service not found", func() {
 				It("should return NotFound error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().DescribeService(gomock.Any()).
+						Return(nil, fmt.Errorf("not found")).Times(1)
+					repoMock.EXPECT().RemoveService(gomock.Any()).Times(0)
+
+					_, err := server.RemoveServiceV1(ctx, &pb.RemoveServiceV1Request{ServiceId: carServiceID})
+
+					Expect(err).Should(HaveOccurred())
+				})
+			})
+
+			When("repo fails to remove service", func() {
+				It("should return NotFound error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().DescribeService(gomock.Any()).Return(&carService, nil).Times(1)
 					repoMock.EXPECT().RemoveService(gomock.Any()).
 						Return(fmt.Errorf("not found")).Times(1)
 
@@ -248,12 +575,13 @@ var _ = Describe("Api", func() {
 				})
 			})
 
-			When("producer returns error", func() {
+			When("publisher returns error", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().DescribeService(gomock.Any()).Return(&carService, nil).Times(1)
 					repoMock.EXPECT().RemoveService(gomock.Any()).Times(1)
-					producerMock.EXPECT().SendMessage(gomock.Any()).
-						Return(fmt.Errorf("producer error")).Times(1)
+					publisherMock.EXPECT().Publish(gomock.Any()).
+						Return(fmt.Errorf("publisher error")).Times(1)
 
 					_, err := server.RemoveServiceV1(ctx, &pb.RemoveServiceV1Request{ServiceId: carServiceID})
 
@@ -263,10 +591,11 @@ var _ = Describe("Api", func() {
 
 			When("valid request", func() {
 				It("should return empty result after removing", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().DescribeService(gomock.Any()).Return(&carService, nil).Times(1)
 					repoMock.EXPECT().RemoveService(gomock.Any()).
 						Return(nil).Times(1)
-					producerMock.EXPECT().SendMessage(gomock.Any()).
+					publisherMock.EXPECT().Publish(gomock.Any()).
 						Return(nil).Times(1)
 					metricsMock.EXPECT().IncrementRemoveCounter().Times(1)
 
@@ -281,7 +610,7 @@ var _ = Describe("Api", func() {
 		Context("on calling MultiCreate endpoint", func() {
 			When("request body is empty", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Times(0)
 
 					_, err := server.MultiCreateServiceV1(ctx, nil)
@@ -292,7 +621,7 @@ var _ = Describe("Api", func() {
 
 			When("request body contains list with invalid objects", func() {
 				It("should return Argument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Times(0)
 					req := &pb.MultiCreateServiceV1Request{CreateService: []*pb.CreateServiceV1Request{nil}}
 
@@ -304,7 +633,7 @@ var _ = Describe("Api", func() {
 
 			When("can't flush all services to repo", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).
 						Return([]models.Service{carService}).Times(1)
 					req := &pb.MultiCreateServiceV1Request{CreateService: validMultiCreateRequest}
@@ -315,12 +644,12 @@ var _ = Describe("Api", func() {
 				})
 			})
 
-			When("producer returns error", func() {
+			When("publisher returns error", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Times(1)
-					producerMock.EXPECT().SendMessages(gomock.Any()).
-						Return(fmt.Errorf("producer error")).Times(1)
+					publisherMock.EXPECT().PublishBatch(gomock.Any()).
+						Return(fmt.Errorf("publisher error")).Times(1)
 					req := &pb.MultiCreateServiceV1Request{CreateService: validMultiCreateRequest}
 
 					_, err := server.MultiCreateServiceV1(ctx, req)
@@ -331,10 +660,10 @@ var _ = Describe("Api", func() {
 
 			When("valid request", func() {
 				It("should return slice of serviceID", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).
 						Return(nil).Times(1)
-					producerMock.EXPECT().SendMessages(gomock.Any()).
+					publisherMock.EXPECT().PublishBatch(gomock.Any()).
 						Return(nil).Times(1)
 					metricsMock.EXPECT().IncrementMultiCreateCounter().Times(1)
 					req := &pb.MultiCreateServiceV1Request{CreateService: validMultiCreateRequest}
@@ -350,7 +679,7 @@ var _ = Describe("Api", func() {
 		Context("on calling Update endpoint", func() {
 			When("request body is empty", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().UpdateService(gomock.Any()).Times(0)
 
 					_, err := server.UpdateServiceV1(ctx, nil)
@@ -361,7 +690,7 @@ var _ = Describe("Api", func() {
 
 			When("can't parse serviceID", func() {
 				It("should return InvalidArgument error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().UpdateService(gomock.Any()).Times(0)
 
 					_, err := server.UpdateServiceV1(ctx, &pb.UpdateServiceV1Request{ServiceId: "bad uuid"})
@@ -372,7 +701,7 @@ var _ = Describe("Api", func() {
 
 			When("request body contains illegal service data", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().UpdateService(gomock.Any()).Times(0)
 
 					_, err := server.UpdateServiceV1(ctx, &pb.UpdateServiceV1Request{ServiceId: carServiceID, UserId: 0})
@@ -383,7 +712,7 @@ var _ = Describe("Api", func() {
 
 			When("repo returns error", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().UpdateService(gomock.Any()).
 						Return(fmt.Errorf("repo error")).Times(1)
 
@@ -393,12 +722,12 @@ var _ = Describe("Api", func() {
 				})
 			})
 
-			When("producer returns error", func() {
+			When("publisher returns error", func() {
 				It("should return Internal error", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().UpdateService(gomock.Any()).Times(1)
-					producerMock.EXPECT().SendMessage(gomock.Any()).
-						Return(fmt.Errorf("producer error")).Times(1)
+					publisherMock.EXPECT().Publish(gomock.Any()).
+						Return(fmt.Errorf("publisher error")).Times(1)
 
 					_, err := server.UpdateServiceV1(ctx, &pb.UpdateServiceV1Request{ServiceId: carServiceID, UserId: 1})
 
@@ -408,9 +737,9 @@ var _ = Describe("Api", func() {
 
 			When("valid request", func() {
 				It("should update service", func() {
-					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, producerMock, metricsMock)
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
 					repoMock.EXPECT().UpdateService(gomock.Any()).Times(1)
-					producerMock.EXPECT().SendMessage(gomock.Any()).
+					publisherMock.EXPECT().Publish(gomock.Any()).
 						Return(nil).Times(1)
 					metricsMock.EXPECT().IncrementUpdateCounter().Times(1)
 
@@ -421,5 +750,118 @@ var _ = Describe("Api", func() {
 				})
 			})
 		})
+
+		Context("on calling ReplayEvents endpoint", func() {
+			var adminCtx context.Context
+
+			BeforeEach(func() {
+				adminCtx = metadata.NewIncomingContext(ctx, metadata.Pairs(api.AuthorizationHeader, "Bearer admin-token"))
+			})
+
+			When("admin token is not passed or not valid", func() {
+				It("should deny the replay", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithAdminToken("admin-token")
+					repoMock.EXPECT().ListServicesForReplay(gomock.Any()).Times(0)
+					wrongCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(api.AuthorizationHeader, "Bearer guess"))
+
+					_, err := server.ReplayEventsV1(ctx, &pb.ReplayEventsV1Request{})
+					Expect(status.Code(err)).Should(Equal(codes.Unauthenticated))
+
+					_, err = server.ReplayEventsV1(wrongCtx, &pb.ReplayEventsV1Request{})
+					Expect(status.Code(err)).Should(Equal(codes.PermissionDenied))
+				})
+			})
+
+			When("admin token is not configured", func() {
+				It("should deny the replay to everyone", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock)
+					repoMock.EXPECT().ListServicesForReplay(gomock.Any()).Times(0)
+
+					_, err := server.ReplayEventsV1(adminCtx, &pb.ReplayEventsV1Request{})
+
+					Expect(status.Code(err)).Should(Equal(codes.PermissionDenied))
+				})
+			})
+
+			When("after service ID is not valid UUID", func() {
+				It("should return InvalidArgument error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithAdminToken("admin-token")
+					repoMock.EXPECT().ListServicesForReplay(gomock.Any()).Times(0)
+
+					_, err := server.ReplayEventsV1(adminCtx, &pb.ReplayEventsV1Request{AfterServiceId: "bad uuid"})
+
+					Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+				})
+			})
+
+			When("publisher returns error", func() {
+				It("should return Internal error", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithAdminToken("admin-token")
+					repoMock.EXPECT().ListServicesForReplay(gomock.Any()).
+						Return([]models.Service{carService}, nil).Times(1)
+					publisherMock.EXPECT().PublishBatch(gomock.Any()).
+						Return(fmt.Errorf("publisher error")).Times(1)
+
+					_, err := server.ReplayEventsV1(adminCtx, &pb.ReplayEventsV1Request{})
+
+					Expect(status.Code(err)).Should(Equal(codes.Internal))
+				})
+			})
+
+			When("valid request", func() {
+				It("should publish replayed create events and return cursor", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithAdminToken("admin-token")
+					repoMock.EXPECT().ListServicesForReplay(repo.ReplayFilter{UserID: 1, Limit: 1}).
+						Return([]models.Service{carService}, nil).Times(1)
+
+					var published []eventbus.Message
+					publisherMock.EXPECT().PublishBatch(gomock.Any()).
+						DoAndReturn(func(messages []eventbus.Message) error {
+							published = messages
+							return nil
+						}).Times(1)
+
+					res, err := server.ReplayEventsV1(adminCtx, &pb.ReplayEventsV1Request{UserId: 1, Limit: 1})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.Replayed).Should(BeEquivalentTo(1))
+					Expect(res.LastServiceId).Should(Equal(carServiceID))
+					Expect(published).Should(HaveLen(1))
+					Expect(published[0].Headers).Should(HaveKeyWithValue(events.ReplayHeader, "true"))
+				})
+			})
+
+			When("events of writes are discarded for the change feed", func() {
+				It("should still publish replayed events to the server publisher", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithWritePublisher(eventbus.Discard{}).
+						WithAdminToken("admin-token")
+					repoMock.EXPECT().ListServicesForReplay(gomock.Any()).
+						Return([]models.Service{carService}, nil).Times(1)
+					publisherMock.EXPECT().PublishBatch(gomock.Len(1)).Return(nil).Times(1)
+
+					res, err := server.ReplayEventsV1(adminCtx, &pb.ReplayEventsV1Request{Limit: 1})
+
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(res.Replayed).Should(BeEquivalentTo(1))
+				})
+
+				It("should not publish events of Create to the server publisher", func() {
+					server := api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+						WithWritePublisher(eventbus.Discard{})
+					saverMock.EXPECT().Save(gomock.Any()).Return(nil).Times(1)
+					metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+					publisherMock.EXPECT().Publish(gomock.Any()).Times(0)
+
+					_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1})
+
+					Expect(err).ShouldNot(HaveOccurred())
+				})
+			})
+		})
 	})
 })
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ozonva/ova-service-api/internal/repo"
+)
+
+// ConflictViolationType is the type of PreconditionFailure violations of conflicting services.
+// The subject of the violation is the conflicting service ID, the description is the rule.
+const ConflictViolationType = "SERVICE_CONFLICT"
+
+// WithConflictDetection makes Create and MultiCreate write through the repo instead of the saver and the flusher,
+// so conflicts found by the repo are returned to the client. Update always returns them.
+func (s *GrpcApiServer) WithConflictDetection() *GrpcApiServer {
+	s.conflictDetection = true
+	return s
+}
+
+// conflictStatus returns FailedPrecondition with conflicting service IDs if the repo error is repo.ConflictError
+func conflictStatus(err error) (*status.Status, bool) {
+	var conflictErr *repo.ConflictError
+	if !errors.As(err, &conflictErr) {
+		return nil, false
+	}
+
+	ids := conflictErr.ServiceIDs()
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	st := status.Newf(codes.FailedPrecondition, "Service conflicts with services: %s", strings.Join(idStrings, ", "))
+
+	failure := &errdetails.PreconditionFailure{}
+	for _, conflict := range conflictErr.Conflicts {
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        ConflictViolationType,
+			Subject:     conflict.ServiceID.String(),
+			Description: string(conflict.Rule),
+		})
+	}
+
+	if withDetails, detailsErr := st.WithDetails(failure); detailsErr == nil {
+		st = withDetails
+	}
+
+	return st, true
+}
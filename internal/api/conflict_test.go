@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/repo"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+var _ = Describe("Conflicts", func() {
+	var (
+		ctx           context.Context
+		ctrl          *gomock.Controller
+		repoMock      *mocks.MockRepo
+		saverMock     *mocks.MockSaver
+		flusherMock   *mocks.MockFlusher
+		publisherMock *mocks.MockPublisher
+		metricsMock   *mocks.MockMetrics
+		server        *api.GrpcApiServer
+
+		conflictingID uuid.UUID
+		conflictErr   error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		repoMock = mocks.NewMockRepo(ctrl)
+		saverMock = mocks.NewMockSaver(ctrl)
+		flusherMock = mocks.NewMockFlusher(ctrl)
+		publisherMock = mocks.NewMockPublisher(ctrl)
+		metricsMock = mocks.NewMockMetrics(ctrl)
+		encoder, _ := events.NewEncoder(events.EncoderConfig{})
+		server = api.NewGrpcApiServer(repoMock, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+			WithConflictDetection()
+
+		conflictingID = uuid.New()
+		conflictErr = &repo.ConflictError{ServiceID: uuid.New(), Conflicts: []repo.Conflict{
+			{Rule: repo.ConflictRuleAddress, ServiceID: conflictingID},
+		}}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	expectConflict := func(err error) {
+		st := status.Convert(err)
+		Expect(st.Code()).Should(Equal(codes.FailedPrecondition))
+		Expect(st.Message()).Should(ContainSubstring(conflictingID.String()))
+
+		Expect(st.Details()).Should(HaveLen(1))
+		failure, ok := st.Details()[0].(*errdetails.PreconditionFailure)
+		Expect(ok).Should(BeTrue())
+		Expect(failure.Violations).Should(HaveLen(1))
+		Expect(failure.Violations[0].Type).Should(Equal(api.ConflictViolationType))
+		Expect(failure.Violations[0].Subject).Should(Equal(conflictingID.String()))
+		Expect(failure.Violations[0].Description).Should(Equal(string(repo.ConflictRuleAddress)))
+	}
+
+	Context("on calling Create endpoint", func() {
+		When("service conflicts with the stored one", func() {
+			It("should return FailedPrecondition error with the conflicting service ID", func() {
+				saverMock.EXPECT().Save(gomock.Any()).Times(0)
+				publisherMock.EXPECT().Publish(gomock.Any()).Times(0)
+				repoMock.EXPECT().AddServices(gomock.Any()).Return(conflictErr).Times(1)
+
+				_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1, ServiceAddress: "Moscow"})
+
+				expectConflict(err)
+			})
+		})
+
+		When("service doesn't conflict", func() {
+			It("should save it through the repo", func() {
+				saverMock.EXPECT().Save(gomock.Any()).Times(0)
+				repoMock.EXPECT().AddServices(gomock.Len(1)).Return(nil).Times(1)
+				publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+				_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1, ServiceAddress: "Moscow"})
+
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("on calling MultiCreate endpoint", func() {
+		When("services conflict", func() {
+			It("should save none of them and return FailedPrecondition error", func() {
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Times(0)
+				publisherMock.EXPECT().PublishBatch(gomock.Any()).Times(0)
+				repoMock.EXPECT().AddServices(gomock.Len(2)).Return(conflictErr).Times(1)
+
+				_, err := server.MultiCreateServiceV1(ctx, &pb.MultiCreateServiceV1Request{
+					CreateService: []*pb.CreateServiceV1Request{{UserId: 1}, {UserId: 2}},
+				})
+
+				expectConflict(err)
+			})
+		})
+	})
+
+	Context("on calling Update endpoint", func() {
+		When("updated service conflicts", func() {
+			It("should return FailedPrecondition error with the conflicting service ID", func() {
+				publisherMock.EXPECT().Publish(gomock.Any()).Times(0)
+				repoMock.EXPECT().UpdateService(gomock.Any()).Return(conflictErr).Times(1)
+
+				_, err := server.UpdateServiceV1(ctx, &pb.UpdateServiceV1Request{ServiceId: uuid.New().String(), UserId: 1})
+
+				expectConflict(err)
+			})
+		})
+	})
+})
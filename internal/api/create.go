@@ -2,19 +2,26 @@ package api
 
 import (
 	"context"
-	"github.com/ozonva/ova-service-api/internal/events"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
 	"github.com/ozonva/ova-service-api/internal/models"
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
-func (s *GrpcApiServer) CreateServiceV1(_ context.Context, req *pb.CreateServiceV1Request) (*pb.CreateServiceV1Response, error) {
+// localTimeLayout is the layout of when_local in requests
+const localTimeLayout = "2006-01-02T15:04:05"
+
+func (s *GrpcApiServer) CreateServiceV1(ctx context.Context, req *pb.CreateServiceV1Request) (*pb.CreateServiceV1Response, error) {
 	log.Info().Msg("CreateServiceV1 is called...")
 
 	if req == nil {
@@ -23,24 +30,78 @@ func (s *GrpcApiServer) CreateServiceV1(_ context.Context, req *pb.CreateService
 		return nil, invalidArgErr
 	}
 
-	when := extractTimeFromTimestamp(req.GetWhen())
-	service, err := models.NewService(req.UserId, req.Description, req.ServiceName, req.ServiceAddress, when)
+	replay := &pb.CreateServiceV1Response{}
+	call, replayed, err := s.beginIdempotent(ctx, "CreateServiceV1", req.IdempotencyKey, req, replay)
+	if err != nil {
+		log.Err(err).Msg("Error occurred in CreateServiceV1")
+		return nil, err
+	}
+	if replayed {
+		return replay, nil
+	}
+
+	res, err := s.createService(ctx, req)
+	s.completeIdempotent(call, res, err)
+
+	return res, err
+}
+
+func (s *GrpcApiServer) createService(ctx context.Context, req *pb.CreateServiceV1Request) (*pb.CreateServiceV1Response, error) {
+	when, err := parseWhen(req.GetWhen(), req.WhenLocal, req.TimeZone)
+	if err != nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Service time is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in CreateServiceV1")
+		return nil, invalidArgErr
+	}
+
+	end, err := parseEnd(req.GetEnd(), req.EndLocal, req.GetDuration(), when, req.TimeZone)
+	if err != nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Service end is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in CreateServiceV1")
+		return nil, invalidArgErr
+	}
+
+	service, err := models.NewService(s.ids, req.UserId, req.Description, req.ServiceName, req.ServiceAddress, when, end, req.TimeZone)
 
+	if errors.Is(err, models.ErrInvalidEnd) {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Service end is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in CreateServiceV1")
+		return nil, invalidArgErr
+	}
 	if err != nil {
 		internalErr := status.Errorf(codes.Internal, "Error occurred during service creation: %s", err.Error())
 		log.Err(internalErr).Msg("Error occurred in CreateServiceV1")
 		return nil, internalErr
 	}
 
-	saverErr := s.saver.Save(*service)
-	if saverErr != nil {
-		return nil, status.Errorf(codes.Internal, "Error occurred while saver trying to save the service: %s", saverErr.Error())
+	if s.conflictDetection {
+		repoErr := s.repo.AddServices([]models.Service{*service})
+		if conflict, ok := conflictStatus(repoErr); ok {
+			log.Err(conflict.Err()).Msg("Error occurred in CreateServiceV1")
+			return nil, conflict.Err()
+		}
+		if repoErr != nil {
+			return nil, status.Errorf(codes.Internal, "Error occurred during saving to repo: %s", repoErr.Error())
+		}
+	} else {
+		saverErr := s.saver.Save(*service)
+		if saverErr != nil {
+			return nil, status.Errorf(codes.Internal, "Error occurred while saver trying to save the service: %s", saverErr.Error())
+		}
+	}
+
+	event := events.NewServiceCreateEvent(service.ID, service.UserID)
+	message, encodeErr := s.encoder.Encode(event)
+	if encodeErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to encode Create event: %s", encodeErr.Error())
 	}
 
-	event := events.NewServiceCreateEvent(service.ID)
-	kafkaErr := s.producer.SendMessage(event.String())
-	if kafkaErr != nil {
-		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce Create event to Kafka: %s", kafkaErr.Error())
+	messages := []eventbus.Message{message}
+	publishSpan := startPublishSpan(ctx, messages)
+	publishErr := s.writePublisher.Publish(messages[0])
+	publishSpan.Finish()
+	if publishErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce Create event to event bus: %s", publishErr.Error())
 	}
 
 	s.metrics.IncrementCreateCounter()
@@ -48,11 +109,80 @@ func (s *GrpcApiServer) CreateServiceV1(_ context.Context, req *pb.CreateService
 	return &pb.CreateServiceV1Response{ServiceId: service.ID.String()}, nil
 }
 
-func extractTimeFromTimestamp(ts *timestamp.Timestamp) *time.Time {
-	if ts == nil {
-		return nil
+// parseWhen returns the service time from the timestamp or, if it is not set, from the local time in the zone.
+// Timestamps are absolute, so they don't depend on the zone of the server or the service.
+func parseWhen(ts *timestamp.Timestamp, whenLocal string, timeZone string) (*time.Time, error) {
+	return parseInstant("when", ts, whenLocal, timeZone)
+}
+
+// parseEnd returns the service end the same way as parseWhen or, if duration is set, as the duration from when.
+// Only one of them can be set, the end is checked against when by the model.
+func parseEnd(ts *timestamp.Timestamp, endLocal string, d *duration.Duration, when *time.Time, timeZone string) (*time.Time, error) {
+	if d == nil {
+		return parseInstant("end", ts, endLocal, timeZone)
+	}
+
+	if ts != nil || len(endLocal) > 0 {
+		return nil, fmt.Errorf("only one of end, end_local and duration can be set")
+	}
+	if err := d.CheckValid(); err != nil {
+		return nil, err
+	}
+	if when == nil {
+		return nil, fmt.Errorf("duration can't be set without the service time")
+	}
+
+	end := when.Add(d.AsDuration())
+	return &end, nil
+}
+
+// parseInstant parses the pair of the timestamp and the local time fields named field and field_local
+func parseInstant(field string, ts *timestamp.Timestamp, local string, timeZone string) (*time.Time, error) {
+	location, err := models.LoadTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	if ts != nil {
+		if len(local) > 0 {
+			return nil, fmt.Errorf("only one of %s and %s_local can be set", field, field)
+		}
+
+		instant := ts.AsTime()
+		return &instant, nil
+	}
+
+	if len(local) == 0 {
+		return nil, nil
+	}
+
+	wall, err := time.Parse(localTimeLayout, local)
+	if err != nil {
+		return nil, fmt.Errorf("%s_local must be the local time without offset like %q", field, localTimeLayout)
+	}
+
+	instant, err := models.LocalTime(wall, location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instant, nil
+}
+
+// formatWhenLocal returns the service time in the zone of the service with its offset
+func formatWhenLocal(service *models.Service) string {
+	if service.WhenLocal == nil {
+		return ""
+	}
+
+	return service.WhenLocal.Format(time.RFC3339)
+}
+
+// formatEndLocal returns the service end in the zone of the service with its offset
+func formatEndLocal(service *models.Service) string {
+	if service.EndLocal == nil {
+		return ""
 	}
 
-	localTime := time.Unix(ts.Seconds, int64(ts.Nanos))
-	return &localTime
+	return service.EndLocal.Format(time.RFC3339)
 }
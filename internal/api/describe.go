@@ -31,7 +31,7 @@ func (s *GrpcApiServer) DescribeServiceV1(_ context.Context, req *pb.DescribeSer
 		return nil, invalidArgErr
 	}
 
-	service, repoErr := s.repo.DescribeService(serviceID)
+	service, repoErr := s.describeService(serviceID)
 
 	if repoErr != nil {
 		return nil, status.Error(codes.NotFound, "Service was not found")
@@ -61,6 +61,11 @@ func mapServiceToDescribeV1Response(service *models.Service) (*pb.DescribeServic
 		tsUTC = timestamppb.New(*service.WhenUTC)
 	}
 
+	var tsEnd *timestamppb.Timestamp
+	if service.EndUTC != nil {
+		tsEnd = timestamppb.New(*service.EndUTC)
+	}
+
 	return &pb.DescribeServiceV1Response{
 		ServiceId:      service.ID.String(),
 		UserId:         service.UserID,
@@ -69,5 +74,9 @@ func mapServiceToDescribeV1Response(service *models.Service) (*pb.DescribeServic
 		ServiceAddress: service.ServiceAddress,
 		When:           ts,
 		WhenUtc:        tsUTC,
+		TimeZone:       service.TimeZone,
+		WhenLocal:      formatWhenLocal(service),
+		End:            tsEnd,
+		EndLocal:       formatEndLocal(service),
 	}, nil
 }
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// ExportServicesV1 reads the primary repo even if the read model is set, the read model can't provide the snapshot
+func (s *GrpcApiServer) ExportServicesV1(req *pb.ExportServicesV1Request, stream pb.ServiceAPI_ExportServicesV1Server) error {
+	log.Info().Msg("ExportServicesV1 is called...")
+
+	if req == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Request argument is nil")
+		log.Err(invalidArgErr).Msg("Error occurred in ExportServicesV1")
+		return invalidArgErr
+	}
+
+	var filter models.IntervalFilter
+	if req.GetFrom() != nil || req.GetTo() != nil {
+		var err error
+		if filter, err = mapIntervalFilter(req.From, req.To, req.Within); err != nil {
+			invalidArgErr := status.Errorf(codes.InvalidArgument, "Interval is not valid: %s", err.Error())
+			log.Err(invalidArgErr).Msg("Error occurred in ExportServicesV1")
+			return invalidArgErr
+		}
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = ^uint64(0)
+	}
+
+	var exported uint64
+	var sendErr error
+
+	repoErr := s.repo.ExportServices(filter, limit, req.Offset, func(service models.Service) error {
+		res, mapErr := mapServiceToExportV1Response(&service)
+		if mapErr != nil {
+			return mapErr
+		}
+
+		if sendErr = stream.Send(res); sendErr != nil {
+			return sendErr
+		}
+
+		exported++
+		return nil
+	})
+
+	if sendErr != nil {
+		log.Err(sendErr).Uint64("exported", exported).Msg("Export is stopped by the client")
+		return sendErr
+	}
+	if repoErr != nil {
+		internalErr := status.Errorf(codes.Internal, "Export stopped after %d services: %s", exported, repoErr.Error())
+		log.Err(internalErr).Msg("Error occurred in ExportServicesV1")
+		return internalErr
+	}
+
+	return nil
+}
+
+func mapServiceToExportV1Response(service *models.Service) (*pb.ExportServicesV1Response, error) {
+	if service == nil {
+		return nil, fmt.Errorf("service is nil")
+	}
+
+	var ts *timestamppb.Timestamp
+	if service.WhenLocal != nil {
+		ts = timestamppb.New(*service.WhenLocal)
+	}
+
+	var tsUTC *timestamppb.Timestamp
+	if service.WhenUTC != nil {
+		tsUTC = timestamppb.New(*service.WhenUTC)
+	}
+
+	var tsEnd *timestamppb.Timestamp
+	if service.EndUTC != nil {
+		tsEnd = timestamppb.New(*service.EndUTC)
+	}
+
+	return &pb.ExportServicesV1Response{
+		ServiceId:      service.ID.String(),
+		UserId:         service.UserID,
+		Description:    service.Description,
+		ServiceName:    service.ServiceName,
+		ServiceAddress: service.ServiceAddress,
+		When:           ts,
+		WhenUtc:        tsUTC,
+		TimeZone:       service.TimeZone,
+		WhenLocal:      formatWhenLocal(service),
+		End:            tsEnd,
+		EndLocal:       formatEndLocal(service),
+	}, nil
+}
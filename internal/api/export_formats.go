@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// Accept header values of the export formats
+const (
+	JSONLinesContentType = "application/x-ndjson"
+	CSVContentType       = "text/csv"
+)
+
+// csvColumns are the header row, "when" and "end" are local times in "time_zone" with the offset
+var csvColumns = []string{"service_id", "user_id", "description", "service_name", "service_address", "when", "when_utc", "time_zone", "end"}
+
+// JSONLinesMarshaler renders streamed responses of the HTTP gateway as JSON Lines: one message per line
+// without the {"result": message} wrapper. Stream errors are sent as the last {"error": status} line.
+type JSONLinesMarshaler struct {
+	runtime.JSONPb
+}
+
+func NewJSONLinesMarshaler() *JSONLinesMarshaler {
+	return &JSONLinesMarshaler{JSONPb: runtime.JSONPb{OrigName: true}}
+}
+
+func (m *JSONLinesMarshaler) ContentType() string {
+	return JSONLinesContentType
+}
+
+func (m *JSONLinesMarshaler) Delimiter() []byte {
+	return []byte("\n")
+}
+
+func (m *JSONLinesMarshaler) Marshal(v interface{}) ([]byte, error) {
+	if chunk, ok := v.(map[string]interface{}); ok {
+		if result, ok := chunk["result"]; ok {
+			return m.JSONPb.Marshal(result)
+		}
+	}
+
+	return m.JSONPb.Marshal(v)
+}
+
+// CSVMarshaler renders streamed export responses of the HTTP gateway as CSV rows, the header row is written
+// by CSVHeader. Stream errors are sent as the last row of "error" and the JSON encoded status.
+type CSVMarshaler struct {
+	runtime.JSONPb
+}
+
+func NewCSVMarshaler() *CSVMarshaler {
+	return &CSVMarshaler{JSONPb: runtime.JSONPb{OrigName: true}}
+}
+
+func (m *CSVMarshaler) ContentType() string {
+	return CSVContentType
+}
+
+func (m *CSVMarshaler) Delimiter() []byte {
+	return []byte("\n")
+}
+
+func (m *CSVMarshaler) Marshal(v interface{}) ([]byte, error) {
+	switch chunk := v.(type) {
+	case map[string]interface{}:
+		if response, ok := chunk["result"].(*pb.ExportServicesV1Response); ok {
+			return marshalCSVRow(exportRecord(response))
+		}
+	case map[string]proto.Message:
+		if streamErr, ok := chunk["error"]; ok {
+			data, err := m.JSONPb.Marshal(streamErr)
+			if err != nil {
+				return nil, err
+			}
+			return marshalCSVRow([]string{"error", string(data)})
+		}
+	}
+
+	// Unary responses and errors are not tabular
+	return m.JSONPb.Marshal(v)
+}
+
+// CSVHeader is the forward response option writing the header row before the first CSV row.
+// Streams call forward response options with nil message once the response headers are set.
+func CSVHeader(_ context.Context, w http.ResponseWriter, message proto.Message) error {
+	if message != nil || w.Header().Get("Content-Type") != CSVContentType {
+		return nil
+	}
+
+	row, err := marshalCSVRow(csvColumns)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(row, '\n'))
+	return err
+}
+
+func exportRecord(response *pb.ExportServicesV1Response) []string {
+	return []string{
+		response.ServiceId,
+		strconv.FormatUint(response.UserId, 10),
+		response.Description,
+		response.ServiceName,
+		response.ServiceAddress,
+		response.WhenLocal,
+		formatCSVTimestamp(response.WhenUtc),
+		response.TimeZone,
+		response.EndLocal,
+	}
+}
+
+func formatCSVTimestamp(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+
+	return ts.AsTime().Format(time.RFC3339Nano)
+}
+
+// marshalCSVRow quotes the record and leaves the line end to the delimiter
+func marshalCSVRow(record []string) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
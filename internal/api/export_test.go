@@ -0,0 +1,176 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+type fakeExportStream struct {
+	grpc.ServerStream
+	sent []*pb.ExportServicesV1Response
+	err  error
+}
+
+func (s *fakeExportStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeExportStream) Send(response *pb.ExportServicesV1Response) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.sent = append(s.sent, response)
+	return nil
+}
+
+var _ = Describe("Export", func() {
+	var (
+		ctrl     *gomock.Controller
+		repoMock *mocks.MockRepo
+		server   *api.GrpcApiServer
+		services []models.Service
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		repoMock = mocks.NewMockRepo(ctrl)
+		server = api.NewGrpcApiServer(repoMock, nil, nil, nil, nil, nil)
+		services = []models.Service{
+			{ID: uuid.New(), UserID: 1, ServiceName: "Car service"},
+			{ID: uuid.New(), UserID: 2, ServiceName: "Yacht service"},
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	exportServices := func(_ models.IntervalFilter, _, _ uint64, fn func(service models.Service) error) error {
+		for _, service := range services {
+			if err := fn(service); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	Context("on calling Export endpoint", func() {
+		When("limit is not set", func() {
+			It("should stream all services", func() {
+				stream := &fakeExportStream{}
+				repoMock.EXPECT().ExportServices(models.IntervalFilter{}, ^uint64(0), uint64(0), gomock.Any()).DoAndReturn(exportServices).Times(1)
+
+				err := server.ExportServicesV1(&pb.ExportServicesV1Request{}, stream)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(stream.sent).Should(HaveLen(2))
+				Expect(stream.sent[1].ServiceName).Should(BeEquivalentTo("Yacht service"))
+			})
+		})
+
+		When("interval is set", func() {
+			It("should pass it to the repo", func() {
+				from := time.Date(2030, 3, 31, 0, 0, 0, 0, time.UTC)
+				to := from.Add(24 * time.Hour)
+				filter := models.IntervalFilter{From: from, To: to, Within: true}
+				repoMock.EXPECT().ExportServices(filter, ^uint64(0), uint64(0), gomock.Any()).DoAndReturn(exportServices).Times(1)
+
+				err := server.ExportServicesV1(&pb.ExportServicesV1Request{
+					From:   timestamppb.New(from),
+					To:     timestamppb.New(to),
+					Within: true,
+				}, &fakeExportStream{})
+
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		When("interval is not valid", func() {
+			It("should return InvalidArgument error", func() {
+				from := time.Date(2030, 3, 31, 0, 0, 0, 0, time.UTC)
+				repoMock.EXPECT().ExportServices(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				err := server.ExportServicesV1(&pb.ExportServicesV1Request{
+					From: timestamppb.New(from),
+					To:   timestamppb.New(from.Add(-time.Hour)),
+				}, &fakeExportStream{})
+
+				Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+
+		When("client leaves", func() {
+			It("should stop the export with the stream error", func() {
+				stream := &fakeExportStream{err: context.Canceled}
+				repoMock.EXPECT().ExportServices(models.IntervalFilter{}, uint64(10), uint64(5), gomock.Any()).DoAndReturn(exportServices).Times(1)
+
+				err := server.ExportServicesV1(&pb.ExportServicesV1Request{Limit: 10, Offset: 5}, stream)
+
+				Expect(err).Should(MatchError(context.Canceled))
+			})
+		})
+
+		When("repo returns error", func() {
+			It("should return Internal error", func() {
+				repoMock.EXPECT().ExportServices(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(fmt.Errorf("connection reset")).Times(1)
+
+				err := server.ExportServicesV1(&pb.ExportServicesV1Request{}, &fakeExportStream{})
+
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("on rendering export over HTTP", func() {
+		response := &pb.ExportServicesV1Response{
+			ServiceId:   "d6fa505c",
+			UserId:      1,
+			ServiceName: "Car, boat and yacht service",
+			TimeZone:    "Europe/Moscow",
+			WhenLocal:   "2030-03-31T10:00:00+03:00",
+			EndLocal:    "2030-03-31T11:30:00+03:00",
+		}
+
+		It("should send JSON Lines without result wrapper", func() {
+			data, err := api.NewJSONLinesMarshaler().Marshal(map[string]interface{}{"result": response})
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(HavePrefix(`{"service_id":"d6fa505c"`))
+		})
+
+		It("should send quoted CSV rows", func() {
+			data, err := api.NewCSVMarshaler().Marshal(map[string]interface{}{"result": response})
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(Equal(`d6fa505c,1,,"Car, boat and yacht service",,2030-03-31T10:00:00+03:00,,Europe/Moscow,2030-03-31T11:30:00+03:00`))
+		})
+
+		It("should write CSV header once the stream starts", func() {
+			recorder := httptest.NewRecorder()
+			recorder.Header().Set("Content-Type", api.CSVContentType)
+
+			Expect(api.CSVHeader(context.Background(), recorder, nil)).Should(Succeed())
+			Expect(api.CSVHeader(context.Background(), recorder, response)).Should(Succeed())
+
+			Expect(recorder.Body.String()).Should(Equal("service_id,user_id,description,service_name,service_address,when,when_utc,time_zone,end\n"))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ozonva/ova-service-api/internal/idempotency"
+)
+
+// IdempotencyKeyHeader is used if the request has no idempotency key, the HTTP gateway forwards it as metadata
+const IdempotencyKeyHeader = "idempotency-key"
+
+type IdempotencyStore interface {
+	Begin(scope string, key string, fingerprint string) ([]byte, error)
+	Complete(scope string, key string, response []byte) error
+	Release(scope string, key string) error
+}
+
+// WithIdempotency makes Create and MultiCreate return the response of the first request for repeated keys,
+// keys are ignored without the store.
+func (s *GrpcApiServer) WithIdempotency(store IdempotencyStore) *GrpcApiServer {
+	s.idempotency = store
+	return s
+}
+
+// idempotentCall is the claimed key, nil if the request has no key
+type idempotentCall struct {
+	scope string
+	key   string
+}
+
+// beginIdempotent claims the key of the request. If the request with the key is already completed,
+// its response is unmarshalled to replay and replayed is true.
+func (s *GrpcApiServer) beginIdempotent(ctx context.Context, scope string, key string, req proto.Message, replay proto.Message) (call *idempotentCall, replayed bool, err error) {
+	if s.idempotency == nil {
+		return nil, false, nil
+	}
+
+	if len(key) == 0 {
+		key = idempotencyKey(ctx)
+	}
+	if len(key) == 0 {
+		return nil, false, nil
+	}
+	if len(key) > idempotency.MaxKeyLength {
+		return nil, false, status.Errorf(codes.InvalidArgument, "Idempotency key is longer than %d characters", idempotency.MaxKeyLength)
+	}
+
+	fingerprint, err := idempotency.Fingerprint(req)
+	if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "Error occurred while computing request fingerprint: %s", err.Error())
+	}
+
+	response, err := s.idempotency.Begin(scope, key, fingerprint)
+	switch {
+	case errors.Is(err, idempotency.ErrConflict):
+		return nil, false, status.Errorf(codes.AlreadyExists, "Idempotency key %q is already used with a different request", key)
+	case errors.Is(err, idempotency.ErrInProgress):
+		return nil, false, status.Errorf(codes.Aborted, "Request with idempotency key %q is in progress, retry later", key)
+	case err != nil:
+		return nil, false, status.Errorf(codes.Internal, "Error occurred while checking idempotency key: %s", err.Error())
+	}
+
+	if response != nil {
+		if err = proto.Unmarshal(response, replay); err != nil {
+			return nil, false, status.Errorf(codes.Internal, "Error occurred while loading stored response: %s", err.Error())
+		}
+
+		log.Info().Str("scope", scope).Str("key", key).Msg("Stored response is returned for repeated request")
+		return nil, true, nil
+	}
+
+	return &idempotentCall{scope: scope, key: key}, false, nil
+}
+
+// completeIdempotent stores the successful response. The key is released on errors, so the request can be retried.
+func (s *GrpcApiServer) completeIdempotent(call *idempotentCall, res proto.Message, err error) {
+	if call == nil {
+		return
+	}
+
+	if err != nil {
+		if releaseErr := s.idempotency.Release(call.scope, call.key); releaseErr != nil {
+			log.Err(releaseErr).Str("key", call.key).Msg("Can't release idempotency key")
+		}
+		return
+	}
+
+	response, err := proto.Marshal(res)
+	if err == nil {
+		err = s.idempotency.Complete(call.scope, call.key, response)
+	}
+	if err != nil {
+		// The request is done, so the key stays claimed and repeated requests get Aborted until it expires
+		log.Err(err).Str("key", call.key).Msg("Can't store response of idempotent request")
+	}
+}
+
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(IdempotencyKeyHeader)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
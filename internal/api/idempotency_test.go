@@ -0,0 +1,168 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/idempotency"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+var _ = Describe("Idempotency", func() {
+	var (
+		ctx           context.Context
+		ctrl          *gomock.Controller
+		saverMock     *mocks.MockSaver
+		flusherMock   *mocks.MockFlusher
+		publisherMock *mocks.MockPublisher
+		metricsMock   *mocks.MockMetrics
+		server        *api.GrpcApiServer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		saverMock = mocks.NewMockSaver(ctrl)
+		flusherMock = mocks.NewMockFlusher(ctrl)
+		publisherMock = mocks.NewMockPublisher(ctrl)
+		metricsMock = mocks.NewMockMetrics(ctrl)
+		encoder, _ := events.NewEncoder(events.EncoderConfig{})
+		server = api.NewGrpcApiServer(nil, saverMock, flusherMock, publisherMock, encoder, metricsMock).
+			WithIdempotency(idempotency.NewMemoryStore(idempotency.DefaultTTL))
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Context("on calling Create endpoint", func() {
+		When("request with the same key is repeated", func() {
+			It("should return the first service ID without creating the service again", func() {
+				saverMock.EXPECT().Save(gomock.Any()).Return(nil).Times(1)
+				publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+				req := &pb.CreateServiceV1Request{UserId: 1, ServiceName: "Car service", IdempotencyKey: "create-1"}
+				first, err := server.CreateServiceV1(ctx, req)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				repeated, err := server.CreateServiceV1(ctx, req)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(repeated.ServiceId).Should(Equal(first.ServiceId))
+			})
+		})
+
+		When("key is passed in the metadata", func() {
+			It("should use it if the request has no key", func() {
+				saverMock.EXPECT().Save(gomock.Any()).Return(nil).Times(1)
+				publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+				keyCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(api.IdempotencyKeyHeader, "create-2"))
+				first, err := server.CreateServiceV1(keyCtx, &pb.CreateServiceV1Request{UserId: 1})
+				Expect(err).ShouldNot(HaveOccurred())
+
+				repeated, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1, IdempotencyKey: "create-2"})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(repeated.ServiceId).Should(Equal(first.ServiceId))
+			})
+		})
+
+		When("key is reused with a different request", func() {
+			It("should return AlreadyExists error", func() {
+				saverMock.EXPECT().Save(gomock.Any()).Return(nil).Times(1)
+				publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+				_, err := server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 1, IdempotencyKey: "create-3"})
+				Expect(err).ShouldNot(HaveOccurred())
+
+				_, err = server.CreateServiceV1(ctx, &pb.CreateServiceV1Request{UserId: 2, IdempotencyKey: "create-3"})
+				Expect(status.Code(err)).Should(Equal(codes.AlreadyExists))
+			})
+		})
+
+		When("the first request fails", func() {
+			It("should process the retry with the same key", func() {
+				saverMock.EXPECT().Save(gomock.Any()).Return(fmt.Errorf("saver error")).Times(1)
+				saverMock.EXPECT().Save(gomock.Any()).Return(nil).Times(1)
+				publisherMock.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementCreateCounter().Times(1)
+
+				req := &pb.CreateServiceV1Request{UserId: 1, IdempotencyKey: "create-4"}
+				_, err := server.CreateServiceV1(ctx, req)
+				Expect(err).Should(HaveOccurred())
+
+				res, err := server.CreateServiceV1(ctx, req)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.ServiceId).ShouldNot(BeEmpty())
+			})
+		})
+
+		When("key is too long", func() {
+			It("should return InvalidArgument error", func() {
+				req := &pb.CreateServiceV1Request{UserId: 1, IdempotencyKey: strings.Repeat("k", idempotency.MaxKeyLength+1)}
+
+				_, err := server.CreateServiceV1(ctx, req)
+
+				Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+	})
+
+	Context("on calling MultiCreate endpoint", func() {
+		When("request with the same key is repeated", func() {
+			It("should return the first service IDs without creating services again", func() {
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Return([]models.Service{}).Times(1)
+				publisherMock.EXPECT().PublishBatch(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementMultiCreateCounter().Times(1)
+
+				req := &pb.MultiCreateServiceV1Request{
+					CreateService: []*pb.CreateServiceV1Request{
+						{UserId: 1, ServiceName: "Panzer service"},
+						{UserId: 1, ServiceName: "Yacht service"},
+					},
+					IdempotencyKey: "multi-create-1",
+				}
+				first, err := server.MultiCreateServiceV1(ctx, req)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				repeated, err := server.MultiCreateServiceV1(ctx, req)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(repeated.ServiceId).Should(Equal(first.ServiceId))
+			})
+		})
+
+		When("key is reused with a different request", func() {
+			It("should return AlreadyExists error", func() {
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Return([]models.Service{}).Times(1)
+				publisherMock.EXPECT().PublishBatch(gomock.Any()).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementMultiCreateCounter().Times(1)
+
+				_, err := server.MultiCreateServiceV1(ctx, &pb.MultiCreateServiceV1Request{
+					CreateService:  []*pb.CreateServiceV1Request{{UserId: 1}},
+					IdempotencyKey: "multi-create-2",
+				})
+				Expect(err).ShouldNot(HaveOccurred())
+
+				_, err = server.MultiCreateServiceV1(ctx, &pb.MultiCreateServiceV1Request{
+					CreateService:  []*pb.CreateServiceV1Request{{UserId: 1}, {UserId: 2}},
+					IdempotencyKey: "multi-create-2",
+				})
+				Expect(status.Code(err)).Should(Equal(codes.AlreadyExists))
+			})
+		})
+	})
+})
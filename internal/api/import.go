@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/models"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+const (
+	// importChunkSize is the number of services passed to the flusher at once, the flusher splits them further
+	importChunkSize = 100
+	// maxImportFailures bounds the summary size, failures above it are only counted
+	maxImportFailures = 1000
+)
+
+type importChunk struct {
+	services []models.Service
+	indexes  map[uuid.UUID]uint64
+}
+
+func (s *GrpcApiServer) ImportServicesV1(stream pb.ServiceAPI_ImportServicesV1Server) error {
+	log.Info().Msg("ImportServicesV1 is called...")
+
+	importSpan, ctx := opentracing.StartSpanFromContext(stream.Context(), "ImportServicesV1")
+	defer importSpan.Finish()
+
+	summary := &pb.ImportServicesV1Response{}
+	chunk := newImportChunk()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Err(err).Msg("Error occurred in ImportServicesV1")
+			return err
+		}
+
+		index := summary.Received
+		summary.Received++
+
+		if req == nil {
+			addImportFailure(summary, index, fmt.Errorf("request is empty"))
+			continue
+		}
+
+		when, err := parseWhen(req.GetWhen(), req.WhenLocal, req.TimeZone)
+		if err != nil {
+			addImportFailure(summary, index, err)
+			continue
+		}
+		end, err := parseEnd(req.GetEnd(), req.EndLocal, req.GetDuration(), when, req.TimeZone)
+		if err != nil {
+			addImportFailure(summary, index, err)
+			continue
+		}
+
+		service, err := models.NewService(s.ids, req.UserId, req.Description, req.ServiceName, req.ServiceAddress, when, end, req.TimeZone)
+		if err != nil {
+			addImportFailure(summary, index, err)
+			continue
+		}
+
+		chunk.add(*service, index)
+		if len(chunk.services) < importChunkSize {
+			continue
+		}
+
+		s.importChunk(ctx, chunk, summary)
+		chunk = newImportChunk()
+	}
+
+	s.importChunk(ctx, chunk, summary)
+
+	importSpan.SetTag("Received", summary.Received)
+	importSpan.SetTag("Imported", summary.Imported)
+	s.metrics.IncrementImportCounter()
+
+	return stream.SendAndClose(summary)
+}
+
+// importChunk saves services through the flusher and publishes Create events for the saved ones.
+// Services discarded by the flusher or saved without events are reported as failures, the import goes on.
+func (s *GrpcApiServer) importChunk(ctx context.Context, chunk *importChunk, summary *pb.ImportServicesV1Response) {
+	if len(chunk.services) == 0 {
+		return
+	}
+
+	notSaved := make(map[uuid.UUID]struct{})
+	for _, service := range s.flusher.Flush(ctx, chunk.services) {
+		notSaved[service.ID] = struct{}{}
+	}
+
+	saved := make([]models.Service, 0, len(chunk.services))
+	for _, service := range chunk.services {
+		if _, ok := notSaved[service.ID]; ok {
+			addImportFailure(summary, chunk.indexes[service.ID], fmt.Errorf("service wasn't saved to the repo"))
+			continue
+		}
+		saved = append(saved, service)
+	}
+
+	if len(saved) == 0 {
+		return
+	}
+
+	if err := s.publishImported(ctx, saved); err != nil {
+		// Services are already saved, so they are reported with their IDs to be fixed without importing them again
+		log.Err(err).Msg("Error occurred in ImportServicesV1")
+		for _, service := range saved {
+			addImportFailure(summary, chunk.indexes[service.ID],
+				fmt.Errorf("service %s was saved, but its event wasn't produced to event bus: %w", service.ID.String(), err))
+		}
+		return
+	}
+
+	summary.Imported += uint64(len(saved))
+}
+
+func (s *GrpcApiServer) publishImported(ctx context.Context, services []models.Service) error {
+	messages, err := events.EncodeAll(s.encoder, mapServicesToCreateEvents(services))
+	if err != nil {
+		return err
+	}
+
+	publishSpan := startPublishSpan(ctx, messages)
+	defer publishSpan.Finish()
+
+	return s.writePublisher.PublishBatch(messages)
+}
+
+func newImportChunk() *importChunk {
+	return &importChunk{
+		services: make([]models.Service, 0, importChunkSize),
+		indexes:  make(map[uuid.UUID]uint64, importChunkSize),
+	}
+}
+
+func (c *importChunk) add(service models.Service, index uint64) {
+	c.services = append(c.services, service)
+	c.indexes[service.ID] = index
+}
+
+func addImportFailure(summary *pb.ImportServicesV1Response, index uint64, err error) {
+	summary.Failed++
+	if len(summary.Failures) < maxImportFailures {
+		summary.Failures = append(summary.Failures, &pb.ImportFailureV1{Index: index, Error: err.Error()})
+	}
+}
@@ -0,0 +1,173 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+type fakeImportStream struct {
+	grpc.ServerStream
+	requests []*pb.CreateServiceV1Request
+	err      error
+	summary  *pb.ImportServicesV1Response
+}
+
+func (s *fakeImportStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeImportStream) Recv() (*pb.CreateServiceV1Request, error) {
+	if len(s.requests) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeImportStream) SendAndClose(summary *pb.ImportServicesV1Response) error {
+	s.summary = summary
+	return nil
+}
+
+var _ = Describe("Import", func() {
+	var (
+		ctrl          *gomock.Controller
+		flusherMock   *mocks.MockFlusher
+		publisherMock *mocks.MockPublisher
+		metricsMock   *mocks.MockMetrics
+		server        *api.GrpcApiServer
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		flusherMock = mocks.NewMockFlusher(ctrl)
+		publisherMock = mocks.NewMockPublisher(ctrl)
+		metricsMock = mocks.NewMockMetrics(ctrl)
+		encoder, _ := events.NewEncoder(events.EncoderConfig{})
+		server = api.NewGrpcApiServer(nil, nil, flusherMock, publisherMock, encoder, metricsMock)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Context("on calling Import endpoint", func() {
+		When("stream contains invalid requests", func() {
+			It("should import valid services and report failures with their positions", func() {
+				stream := &fakeImportStream{requests: []*pb.CreateServiceV1Request{
+					{UserId: 1, ServiceName: "Panzer service"},
+					nil,
+					{ServiceName: "Service without user"},
+					{UserId: 1, ServiceName: "Yacht service"},
+				}}
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Len(2)).Return(nil).Times(1)
+				publisherMock.EXPECT().PublishBatch(gomock.Len(2)).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementImportCounter().Times(1)
+
+				err := server.ImportServicesV1(stream)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(stream.summary.Received).Should(BeEquivalentTo(4))
+				Expect(stream.summary.Imported).Should(BeEquivalentTo(2))
+				Expect(stream.summary.Failed).Should(BeEquivalentTo(2))
+				Expect(stream.summary.Failures[0].Index).Should(BeEquivalentTo(1))
+				Expect(stream.summary.Failures[1].Index).Should(BeEquivalentTo(2))
+			})
+		})
+
+		When("flusher discards some services", func() {
+			It("should report discarded services as failures", func() {
+				stream := &fakeImportStream{requests: []*pb.CreateServiceV1Request{
+					{UserId: 1, ServiceName: "Panzer service"},
+					{UserId: 1, ServiceName: "Yacht service"},
+				}}
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, services []models.Service) []models.Service {
+						return services[1:]
+					}).Times(1)
+				publisherMock.EXPECT().PublishBatch(gomock.Len(1)).Return(nil).Times(1)
+				metricsMock.EXPECT().IncrementImportCounter().Times(1)
+
+				err := server.ImportServicesV1(stream)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(stream.summary.Imported).Should(BeEquivalentTo(1))
+				Expect(stream.summary.Failures).Should(HaveLen(1))
+				Expect(stream.summary.Failures[0].Index).Should(BeEquivalentTo(1))
+			})
+		})
+
+		When("stream contains more services than a chunk", func() {
+			It("should flush services in chunks", func() {
+				requests := make([]*pb.CreateServiceV1Request, 150)
+				for i := range requests {
+					requests[i] = &pb.CreateServiceV1Request{UserId: 1, ServiceName: fmt.Sprintf("Service %d", i)}
+				}
+				stream := &fakeImportStream{requests: requests}
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Len(100)).Return(nil).Times(1)
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Len(50)).Return(nil).Times(1)
+				publisherMock.EXPECT().PublishBatch(gomock.Any()).Return(nil).Times(2)
+				metricsMock.EXPECT().IncrementImportCounter().Times(1)
+
+				err := server.ImportServicesV1(stream)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(stream.summary.Imported).Should(BeEquivalentTo(150))
+			})
+		})
+
+		When("publisher returns error", func() {
+			It("should report saved services of the chunk as failures and go on", func() {
+				requests := make([]*pb.CreateServiceV1Request, 150)
+				for i := range requests {
+					requests[i] = &pb.CreateServiceV1Request{UserId: 1, ServiceName: fmt.Sprintf("Service %d", i)}
+				}
+				requests[120] = nil
+				stream := &fakeImportStream{requests: requests}
+				flusherMock.EXPECT().Flush(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				gomock.InOrder(
+					publisherMock.EXPECT().PublishBatch(gomock.Len(100)).Return(nil).Times(1),
+					publisherMock.EXPECT().PublishBatch(gomock.Len(49)).Return(fmt.Errorf("publisher error")).Times(1),
+				)
+				metricsMock.EXPECT().IncrementImportCounter().Times(1)
+
+				err := server.ImportServicesV1(stream)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(stream.summary.Received).Should(BeEquivalentTo(150))
+				Expect(stream.summary.Imported).Should(BeEquivalentTo(100))
+				Expect(stream.summary.Failed).Should(BeEquivalentTo(50))
+				Expect(stream.summary.Failures[0].Index).Should(BeEquivalentTo(120))
+				Expect(stream.summary.Failures[1].Index).Should(BeEquivalentTo(100))
+				Expect(stream.summary.Failures[1].Error).Should(ContainSubstring("publisher error"))
+			})
+		})
+
+		When("stream fails", func() {
+			It("should return the stream error", func() {
+				stream := &fakeImportStream{err: fmt.Errorf("connection reset")}
+
+				err := server.ImportServicesV1(stream)
+
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+	})
+})
@@ -3,22 +3,65 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/repo"
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
-func (s *GrpcApiServer) ListServicesV1(_ context.Context, _ *empty.Empty) (*pb.ListServicesV1Response, error) {
+const (
+	// defaultPageSize is the page size of ListServicesV1 requests with page_token only
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+func (s *GrpcApiServer) ListServicesV1(_ context.Context, req *pb.ListServicesV1Request) (*pb.ListServicesV1Response, error) {
 	log.Info().Msg("ListServiceV1 is called...")
 
-	// We want to list all and satisfy the Repo interface
-	services, repoErr := s.repo.ListServices(^uint64(0), 0)
+	var (
+		services      []models.Service
+		nextPageToken string
+		repoErr       error
+	)
+
+	switch {
+	case req.PageSize > 0 || len(req.PageToken) > 0:
+		if req.GetFrom() != nil || req.GetTo() != nil {
+			invalidArgErr := status.Errorf(codes.InvalidArgument, "Pages can't be combined with the interval")
+			log.Err(invalidArgErr).Msg("Error occurred in ListServicesV1")
+			return nil, invalidArgErr
+		}
+
+		var cursor *repo.ServiceCursor
+		if len(req.PageToken) > 0 {
+			var err error
+			if cursor, err = repo.ParseServiceCursor(req.PageToken); err != nil {
+				invalidArgErr := status.Errorf(codes.InvalidArgument, "Page token is not valid")
+				log.Err(invalidArgErr).Msg("Error occurred in ListServicesV1")
+				return nil, invalidArgErr
+			}
+		}
+
+		services, nextPageToken, repoErr = s.listServicesPage(cursor, pageSize(req.PageSize))
+	case req.GetFrom() == nil && req.GetTo() == nil:
+		// We want to list all and satisfy the Repo interface
+		services, repoErr = s.listServices(^uint64(0), 0)
+	default:
+		filter, err := mapIntervalFilter(req.From, req.To, req.Within)
+		if err != nil {
+			invalidArgErr := status.Errorf(codes.InvalidArgument, "Interval is not valid: %s", err.Error())
+			log.Err(invalidArgErr).Msg("Error occurred in ListServicesV1")
+			return nil, invalidArgErr
+		}
+
+		services, repoErr = s.listServicesByInterval(filter, ^uint64(0), 0)
+	}
 
 	if repoErr != nil {
 		return nil, status.Errorf(codes.Internal, "Error occurred during list services: %s", repoErr.Error())
@@ -38,9 +81,53 @@ func (s *GrpcApiServer) ListServicesV1(_ context.Context, _ *empty.Empty) (*pb.L
 
 	return &pb.ListServicesV1Response{
 		ServiceShortInfo: infos,
+		NextPageToken:    nextPageToken,
 	}, nil
 }
 
+// listServicesPage reads the page from the repo, the read model has no keyset pagination. The service after the page
+// is loaded to tell whether the page is the last one, the token is empty then.
+func (s *GrpcApiServer) listServicesPage(cursor *repo.ServiceCursor, size uint64) ([]models.Service, string, error) {
+	services, err := s.repo.ListServicesAfter(cursor, size+1)
+	if err != nil || uint64(len(services)) <= size {
+		return services, "", err
+	}
+
+	services = services[:size]
+	return services, repo.CursorAfter(services[size-1]).Token(), nil
+}
+
+func pageSize(requested uint32) uint64 {
+	switch {
+	case requested == 0:
+		return defaultPageSize
+	case requested > maxPageSize:
+		return maxPageSize
+	default:
+		return uint64(requested)
+	}
+}
+
+// mapIntervalFilter maps the interval of ListServicesV1 and ExportServicesV1 requests
+func mapIntervalFilter(from, to *timestamppb.Timestamp, within bool) (models.IntervalFilter, error) {
+	filter := models.IntervalFilter{Within: within}
+
+	for _, bound := range []struct {
+		ts     *timestamppb.Timestamp
+		target *time.Time
+	}{{from, &filter.From}, {to, &filter.To}} {
+		if bound.ts == nil {
+			continue
+		}
+		if err := bound.ts.CheckValid(); err != nil {
+			return filter, err
+		}
+		*bound.target = bound.ts.AsTime()
+	}
+
+	return filter, filter.Validate()
+}
+
 func mapServiceToServiceShortInfoV1Response(service *models.Service) (*pb.ServiceShortInfoV1Response, error) {
 	if service == nil {
 		return nil, fmt.Errorf("service is nil")
@@ -51,10 +138,19 @@ func mapServiceToServiceShortInfoV1Response(service *models.Service) (*pb.Servic
 		ts = timestamppb.New(*service.WhenLocal)
 	}
 
+	var tsEnd *timestamppb.Timestamp
+	if service.EndUTC != nil {
+		tsEnd = timestamppb.New(*service.EndUTC)
+	}
+
 	return &pb.ServiceShortInfoV1Response{
 		ServiceId:   service.ID.String(),
 		UserId:      service.UserID,
 		ServiceName: service.ServiceName,
 		When:        ts,
+		TimeZone:    service.TimeZone,
+		WhenLocal:   formatWhenLocal(service),
+		End:         tsEnd,
+		EndLocal:    formatEndLocal(service),
 	}, nil
 }
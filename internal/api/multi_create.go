@@ -22,7 +22,24 @@ func (s *GrpcApiServer) MultiCreateServiceV1(ctx context.Context, req *pb.MultiC
 		return nil, invalidArgErr
 	}
 
-	services, err := mapServiceRequestToDomainServices(req.CreateService)
+	replay := &pb.MultiCreateServiceV1Response{}
+	call, replayed, err := s.beginIdempotent(ctx, "MultiCreateServiceV1", req.IdempotencyKey, req, replay)
+	if err != nil {
+		log.Err(err).Msg("Error occurred in MultiCreateServiceV1")
+		return nil, err
+	}
+	if replayed {
+		return replay, nil
+	}
+
+	res, err := s.multiCreateServices(ctx, req)
+	s.completeIdempotent(call, res, err)
+
+	return res, err
+}
+
+func (s *GrpcApiServer) multiCreateServices(ctx context.Context, req *pb.MultiCreateServiceV1Request) (*pb.MultiCreateServiceV1Response, error) {
+	services, err := mapServiceRequestToDomainServices(s.ids, req.CreateService)
 
 	if err != nil {
 		internalErr := status.Errorf(codes.InvalidArgument, "Error occurred during parsing input: %s", err.Error())
@@ -30,17 +47,23 @@ func (s *GrpcApiServer) MultiCreateServiceV1(ctx context.Context, req *pb.MultiC
 		return nil, internalErr
 	}
 
-	tracer := opentracing.GlobalTracer()
-	multiCreateParentSpan := tracer.StartSpan("MultiCreateServiceV1", opentracing.Tag{
+	multiCreateParentSpan, ctx := opentracing.StartSpanFromContext(ctx, "MultiCreateServiceV1", opentracing.Tag{
 		Key:   "Count",
 		Value: len(services),
 	})
 	defer multiCreateParentSpan.Finish()
 
-	ctx = opentracing.ContextWithSpan(ctx, multiCreateParentSpan)
-	notSavedServices := s.flusher.Flush(ctx, services)
-
-	if len(notSavedServices) > 0 {
+	// The repo checks all services in one transaction, the flusher would save conflicting chunks partially
+	if s.conflictDetection {
+		repoErr := s.repo.AddServices(services)
+		if conflict, ok := conflictStatus(repoErr); ok {
+			log.Err(conflict.Err()).Msg("Error occurred in MultiCreateServiceV1")
+			return nil, conflict.Err()
+		}
+		if repoErr != nil {
+			return nil, status.Errorf(codes.Internal, "Error occurred during saving to repo: %s", repoErr.Error())
+		}
+	} else if notSavedServices := s.flusher.Flush(ctx, services); len(notSavedServices) > 0 {
 		internalErr := status.Errorf(codes.Internal, "Can't save all services properly. %d services was discarded", len(notSavedServices))
 		log.Err(internalErr).Msg("Error occurred in MultiCreateServiceV1")
 		return nil, internalErr
@@ -52,10 +75,16 @@ func (s *GrpcApiServer) MultiCreateServiceV1(ctx context.Context, req *pb.MultiC
 	// 1. Outbox pattern.
 	// 2. Event sourcing.
 	// 3. Global transaction, but it requires that Kafka consumers should process events in an idempotent manner.
-	messages := mapServicesToMessages(services)
-	kafkaErr := s.producer.SendMessages(messages)
-	if kafkaErr != nil {
-		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce events to Kafka for MultiCreate operation: %s", kafkaErr.Error())
+	messages, encodeErr := events.EncodeAll(s.encoder, mapServicesToCreateEvents(services))
+	if encodeErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to encode events for MultiCreate operation: %s", encodeErr.Error())
+	}
+
+	publishSpan := startPublishSpan(ctx, messages)
+	publishErr := s.writePublisher.PublishBatch(messages)
+	publishSpan.Finish()
+	if publishErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce events to event bus for MultiCreate operation: %s", publishErr.Error())
 	}
 
 	s.metrics.IncrementMultiCreateCounter()
@@ -63,7 +92,7 @@ func (s *GrpcApiServer) MultiCreateServiceV1(ctx context.Context, req *pb.MultiC
 	return &pb.MultiCreateServiceV1Response{ServiceId: mapServiceToServiceIDStrings(services)}, nil
 }
 
-func mapServiceRequestToDomainServices(reqServices []*pb.CreateServiceV1Request) ([]models.Service, error) {
+func mapServiceRequestToDomainServices(ids models.IDGenerator, reqServices []*pb.CreateServiceV1Request) ([]models.Service, error) {
 	if len(reqServices) == 0 {
 		return nil, fmt.Errorf("empty service list")
 	}
@@ -74,8 +103,16 @@ func mapServiceRequestToDomainServices(reqServices []*pb.CreateServiceV1Request)
 		if rs == nil {
 			return nil, fmt.Errorf("list contains empty values")
 		}
-		when := extractTimeFromTimestamp(rs.GetWhen())
-		service, err := models.NewService(rs.UserId, rs.Description, rs.ServiceName, rs.ServiceAddress, when)
+		when, err := parseWhen(rs.GetWhen(), rs.WhenLocal, rs.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseEnd(rs.GetEnd(), rs.EndLocal, rs.GetDuration(), when, rs.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+
+		service, err := models.NewService(ids, rs.UserId, rs.Description, rs.ServiceName, rs.ServiceAddress, when, end, rs.TimeZone)
 
 		if err != nil {
 			return nil, err
@@ -101,17 +138,12 @@ func mapServiceToServiceIDStrings(services []models.Service) []string {
 	return serviceIDs
 }
 
-func mapServicesToMessages(services []models.Service) []string {
-	if len(services) == 0 {
-		return make([]string, 0)
-	}
-
-	messages := make([]string, len(services))
+func mapServicesToCreateEvents(services []models.Service) []events.ServiceCUDEvent {
+	createEvents := make([]events.ServiceCUDEvent, len(services))
 
 	for i, service := range services {
-		event := events.NewServiceCreateEvent(service.ID)
-		messages[i] = event.String()
+		createEvents[i] = events.NewServiceCreateEvent(service.ID, service.UserID)
 	}
 
-	return messages
+	return createEvents
 }
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/requestid"
+)
+
+// startPublishSpan starts the span around publishing events and propagates its context and request ID
+// to the message headers, so the trace continues in consumers. Caller is responsible to finish the span.
+func startPublishSpan(ctx context.Context, messages []eventbus.Message) opentracing.Span {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PublishEvents", ext.SpanKindProducer, opentracing.Tag{
+		Key:   "Count",
+		Value: len(messages),
+	})
+
+	requestID := requestid.FromContext(ctx)
+
+	for i := range messages {
+		if messages[i].Headers == nil {
+			messages[i].Headers = make(map[string]string)
+		}
+
+		if len(requestID) > 0 {
+			messages[i].Headers[requestid.Header] = requestID
+		}
+
+		if err := eventbus.InjectSpanContext(span.Tracer(), span.Context(), &messages[i]); err != nil {
+			log.Err(err).Msg("Can't inject span context to message headers")
+		}
+	}
+
+	return span
+}
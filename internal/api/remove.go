@@ -10,10 +10,11 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/ozonva/ova-service-api/internal/eventbus"
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
-func (s *GrpcApiServer) RemoveServiceV1(_ context.Context, req *pb.RemoveServiceV1Request) (*empty.Empty, error) {
+func (s *GrpcApiServer) RemoveServiceV1(ctx context.Context, req *pb.RemoveServiceV1Request) (*empty.Empty, error) {
 	log.Info().Msg("RemoveServiceV1 is called...")
 
 	if req == nil {
@@ -30,6 +31,12 @@ func (s *GrpcApiServer) RemoveServiceV1(_ context.Context, req *pb.RemoveService
 		return nil, invalidArgErr
 	}
 
+	// Load the service first because Delete event is keyed by the service owner when partitioning by user.
+	service, describeErr := s.repo.DescribeService(serviceID)
+	if describeErr != nil || service == nil {
+		return nil, status.Error(codes.NotFound, "Service was not found")
+	}
+
 	repoErr := s.repo.RemoveService(serviceID)
 	if repoErr != nil {
 		return nil, status.Error(codes.NotFound, "Service was not found")
@@ -37,10 +44,18 @@ func (s *GrpcApiServer) RemoveServiceV1(_ context.Context, req *pb.RemoveService
 
 	// It is possible situation when delete actually doesn't occur because entity was already deleted,
 	// but we do not handle this situation for now and consider that our consumers are idempotent.
-	event := events.NewServiceDeleteEvent(serviceID)
-	kafkaErr := s.producer.SendMessage(event.String())
-	if kafkaErr != nil {
-		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce Delete event to Kafka: %s", kafkaErr.Error())
+	event := events.NewServiceDeleteEvent(serviceID, service.UserID)
+	message, encodeErr := s.encoder.Encode(event)
+	if encodeErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to encode Delete event: %s", encodeErr.Error())
+	}
+
+	messages := []eventbus.Message{message}
+	publishSpan := startPublishSpan(ctx, messages)
+	publishErr := s.writePublisher.Publish(messages[0])
+	publishSpan.Finish()
+	if publishErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce Delete event to event bus: %s", publishErr.Error())
 	}
 
 	s.metrics.IncrementRemoveCounter()
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ozonva/ova-service-api/internal/replay"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+func (s *GrpcApiServer) ReplayEventsV1(ctx context.Context, req *pb.ReplayEventsV1Request) (*pb.ReplayEventsV1Response, error) {
+	log.Info().Msg("ReplayEventsV1 is called...")
+
+	if req == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Request argument is nil")
+		log.Err(invalidArgErr).Msg("Error occurred in ReplayEventsV1")
+		return nil, invalidArgErr
+	}
+
+	if authErr := s.authorizeAdmin(ctx); authErr != nil {
+		log.Err(authErr).Msg("Error occurred in ReplayEventsV1")
+		return nil, authErr
+	}
+
+	options := replay.Options{
+		UserID: req.UserId,
+		Rate:   int(req.Rate),
+		Limit:  req.Limit,
+	}
+	// The whole table may be replayed by the single call, so the bus is never flooded at unlimited rate
+	if options.Rate == 0 {
+		options.Rate = replay.DefaultRate
+	}
+
+	if req.From != nil {
+		options.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		options.To = req.To.AsTime()
+	}
+
+	if len(req.AfterServiceId) > 0 {
+		after, err := uuid.Parse(req.AfterServiceId)
+		if err != nil {
+			invalidArgErr := status.Errorf(codes.InvalidArgument, "After service ID is not valid UUID")
+			log.Err(invalidArgErr).Msg("Error occurred in ReplayEventsV1")
+			return nil, invalidArgErr
+		}
+		options.After = after
+	}
+
+	progress, err := replay.NewReplayer(s.repo, s.publisher, s.encoder).Run(ctx, options, nil)
+	if err != nil {
+		internalErr := status.Errorf(codes.Internal, "Replay stopped after %d events, resume after service %s: %s",
+			progress.Replayed, progress.LastServiceID.String(), err.Error())
+		log.Err(internalErr).Msg("Error occurred in ReplayEventsV1")
+		return nil, internalErr
+	}
+
+	return &pb.ReplayEventsV1Response{
+		Replayed:      progress.Replayed,
+		LastServiceId: progress.LastServiceID.String(),
+		Done:          progress.Done,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/slots"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+const (
+	// dateLayout is the layout of days of FindAvailableSlotsV1
+	dateLayout = "2006-01-02"
+	// clockLayout is the layout of working hours of FindAvailableSlotsV1, "24:00" is accepted as well
+	clockLayout = "15:04"
+)
+
+// WithSlotBuffer sets the free time FindAvailableSlotsV1 keeps around booked services if the request has no buffer
+func (s *GrpcApiServer) WithSlotBuffer(buffer time.Duration) *GrpcApiServer {
+	s.slotBuffer = buffer
+	return s
+}
+
+func (s *GrpcApiServer) FindAvailableSlotsV1(_ context.Context, req *pb.FindAvailableSlotsV1Request) (*pb.FindAvailableSlotsV1Response, error) {
+	log.Info().Msg("FindAvailableSlotsV1 is called...")
+
+	if req == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Request argument is nil")
+		log.Err(invalidArgErr).Msg("Error occurred in FindAvailableSlotsV1")
+		return nil, invalidArgErr
+	}
+
+	slotsReq, err := s.mapSlotsRequest(req)
+	if err != nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Slots request is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in FindAvailableSlotsV1")
+		return nil, invalidArgErr
+	}
+
+	// The read model may miss just booked services, conflict rules reject creates of such slots
+	from, to := slotsReq.Window()
+	booked, repoErr := s.listServicesByInterval(models.IntervalFilter{
+		From:           from,
+		To:             to,
+		ServiceName:    req.ServiceName,
+		ServiceAddress: req.ServiceAddress,
+	}, ^uint64(0), 0)
+	if repoErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred during list services: %s", repoErr.Error())
+	}
+
+	found := slots.Find(slotsReq, booked)
+
+	res := &pb.FindAvailableSlotsV1Response{
+		Slots:    make([]*pb.AvailableSlotV1, len(found)),
+		TimeZone: slotsReq.Location.String(),
+	}
+	for i, slot := range found {
+		res.Slots[i] = &pb.AvailableSlotV1{
+			Start:      timestamppb.New(slot.Start),
+			End:        timestamppb.New(slot.End),
+			StartLocal: slot.Start.Format(time.RFC3339),
+			EndLocal:   slot.End.Format(time.RFC3339),
+		}
+	}
+
+	return res, nil
+}
+
+func (s *GrpcApiServer) mapSlotsRequest(req *pb.FindAvailableSlotsV1Request) (slots.Request, error) {
+	if (len(req.ServiceName) > 0) == (len(req.ServiceAddress) > 0) {
+		return slots.Request{}, fmt.Errorf("exactly one of service_name and service_address must be set")
+	}
+
+	location, err := models.LoadTimeZone(req.TimeZone)
+	if err != nil {
+		return slots.Request{}, err
+	}
+
+	slotsReq := slots.Request{
+		Location: location,
+		Buffer:   s.slotBuffer,
+		Now:      time.Now(),
+	}
+
+	if slotsReq.FirstDay, err = time.Parse(dateLayout, req.FirstDay); err != nil {
+		return slots.Request{}, fmt.Errorf("first_day must be the date like %q", dateLayout)
+	}
+	if slotsReq.LastDay, err = time.Parse(dateLayout, req.LastDay); err != nil {
+		return slots.Request{}, fmt.Errorf("last_day must be the date like %q", dateLayout)
+	}
+	if slotsReq.WorkStart, err = parseClock("work_start", req.WorkStart); err != nil {
+		return slots.Request{}, err
+	}
+	if slotsReq.WorkEnd, err = parseClock("work_end", req.WorkEnd); err != nil {
+		return slots.Request{}, err
+	}
+
+	if slotsReq.Duration, err = parseDuration("slot_duration", req.SlotDuration); err != nil {
+		return slots.Request{}, err
+	}
+	// Short slots start every MinStep by default, the step is validated only if the caller sends it
+	slotsReq.Step = slotsReq.Duration
+	if slotsReq.Step < slots.MinStep {
+		slotsReq.Step = slots.MinStep
+	}
+	if req.Step != nil {
+		if slotsReq.Step, err = parseDuration("step", req.Step); err != nil {
+			return slots.Request{}, err
+		}
+	}
+	if req.Buffer != nil {
+		if slotsReq.Buffer, err = parseDuration("buffer", req.Buffer); err != nil {
+			return slots.Request{}, err
+		}
+	}
+
+	return slotsReq, slotsReq.Validate()
+}
+
+// parseClock returns the offset of the wall clock time from the midnight
+func parseClock(field string, value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	clock, err := time.Parse(clockLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be the time like %q", field, clockLayout)
+	}
+
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+func parseDuration(field string, d *duration.Duration) (time.Duration, error) {
+	if d == nil {
+		return 0, fmt.Errorf("%s is not set", field)
+	}
+	if err := d.CheckValid(); err != nil {
+		return 0, fmt.Errorf("%s is not valid: %s", field, err.Error())
+	}
+
+	return d.AsDuration(), nil
+}
@@ -0,0 +1,114 @@
+package api_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+var _ = Describe("Slots", func() {
+	var (
+		ctx      context.Context
+		ctrl     *gomock.Controller
+		repoMock *mocks.MockRepo
+		server   *api.GrpcApiServer
+		req      *pb.FindAvailableSlotsV1Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		repoMock = mocks.NewMockRepo(ctrl)
+		encoder, _ := events.NewEncoder(events.EncoderConfig{})
+		server = api.NewGrpcApiServer(repoMock, mocks.NewMockSaver(ctrl), mocks.NewMockFlusher(ctrl),
+			mocks.NewMockPublisher(ctrl), encoder, mocks.NewMockMetrics(ctrl)).
+			WithSlotBuffer(30 * time.Minute)
+
+		req = &pb.FindAvailableSlotsV1Request{
+			ServiceAddress: "Moscow",
+			FirstDay:       "2040-01-10",
+			LastDay:        "2040-01-10",
+			TimeZone:       "Europe/Moscow",
+			WorkStart:      "09:00",
+			WorkEnd:        "13:00",
+			SlotDuration:   durationpb.New(time.Hour),
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Context("on calling FindAvailableSlotsV1 endpoint", func() {
+		When("service is booked at the address", func() {
+			It("should return slots free of it and the buffer in the requested zone", func() {
+				// 10:00-11:00 in Moscow
+				when := time.Date(2040, 1, 10, 7, 0, 0, 0, time.UTC)
+				end := when.Add(time.Hour)
+				repoMock.EXPECT().ListServicesByInterval(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(filter models.IntervalFilter, limit, offset uint64) ([]models.Service, error) {
+						Expect(filter.ServiceAddress).Should(Equal("Moscow"))
+						Expect(filter.ServiceName).Should(BeEmpty())
+						return []models.Service{{WhenUTC: &when, EndUTC: &end}}, nil
+					}).Times(1)
+
+				res, err := server.FindAvailableSlotsV1(ctx, req)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.TimeZone).Should(Equal("Europe/Moscow"))
+				Expect(res.Slots).Should(HaveLen(1))
+				Expect(res.Slots[0].StartLocal).Should(Equal("2040-01-10T12:00:00+03:00"))
+				Expect(res.Slots[0].EndLocal).Should(Equal("2040-01-10T13:00:00+03:00"))
+			})
+		})
+
+		When("slot is shorter than the min step and step is not set", func() {
+			It("should start slots every min step", func() {
+				repoMock.EXPECT().ListServicesByInterval(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				req.WorkEnd = "10:00"
+				req.SlotDuration = durationpb.New(2 * time.Minute)
+
+				res, err := server.FindAvailableSlotsV1(ctx, req)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.Slots).Should(HaveLen(12))
+				Expect(res.Slots[1].StartLocal).Should(Equal("2040-01-10T09:05:00+03:00"))
+				Expect(res.Slots[1].EndLocal).Should(Equal("2040-01-10T09:07:00+03:00"))
+			})
+		})
+
+		When("step is shorter than the min step", func() {
+			It("should return InvalidArgument error", func() {
+				repoMock.EXPECT().ListServicesByInterval(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				req.Step = durationpb.New(time.Minute)
+
+				_, err := server.FindAvailableSlotsV1(ctx, req)
+
+				Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+
+		When("request sets both service name and address", func() {
+			It("should return InvalidArgument error", func() {
+				repoMock.EXPECT().ListServicesByInterval(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				req.ServiceName = "Haircut"
+
+				_, err := server.FindAvailableSlotsV1(ctx, req)
+
+				Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+	})
+})
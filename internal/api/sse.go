@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// SSEContentType is the Accept header value of the EventSource clients
+const SSEContentType = "text/event-stream"
+
+// SSEMarshaler renders streamed responses of the HTTP gateway as Server-Sent Events. Changes are sent
+// as events named by the change type with the cursor as the event ID, so EventSource resumes from it
+// on reconnect with the Last-Event-ID header. Heartbeats are sent as comments.
+type SSEMarshaler struct {
+	runtime.JSONPb
+}
+
+func NewSSEMarshaler() *SSEMarshaler {
+	return &SSEMarshaler{JSONPb: runtime.JSONPb{OrigName: true}}
+}
+
+func (m *SSEMarshaler) ContentType() string {
+	return SSEContentType
+}
+
+// Delimiter ends the event with the empty line
+func (m *SSEMarshaler) Delimiter() []byte {
+	return []byte("\n")
+}
+
+// Marshal receives stream chunks as {"result": message} or {"error": message} maps
+func (m *SSEMarshaler) Marshal(v interface{}) ([]byte, error) {
+	switch chunk := v.(type) {
+	case map[string]interface{}:
+		if response, ok := chunk["result"].(*pb.WatchServicesV1Response); ok {
+			return m.marshalChange(response)
+		}
+		if result, ok := chunk["result"]; ok {
+			return m.marshalEvent("", "", result)
+		}
+	case map[string]proto.Message:
+		if streamErr, ok := chunk["error"]; ok {
+			return m.marshalEvent("", "error", streamErr)
+		}
+	}
+
+	return m.marshalEvent("", "", v)
+}
+
+func (m *SSEMarshaler) marshalChange(response *pb.WatchServicesV1Response) ([]byte, error) {
+	if response.Event == nil {
+		return []byte(": heartbeat\n"), nil
+	}
+
+	// SERVICE_EVENT_TYPE_CREATED is sent as "created"
+	name := strings.ToLower(strings.TrimPrefix(response.Event.EventType.String(), "SERVICE_EVENT_TYPE_"))
+	return m.marshalEvent(response.Cursor, name, response)
+}
+
+func (m *SSEMarshaler) marshalEvent(id string, name string, v interface{}) ([]byte, error) {
+	data, err := m.JSONPb.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if len(id) > 0 {
+		buffer.WriteString("id: " + id + "\n")
+	}
+	if len(name) > 0 {
+		buffer.WriteString("event: " + name + "\n")
+	}
+	buffer.WriteString("data: ")
+	buffer.Write(data)
+	buffer.WriteString("\n")
+
+	return buffer.Bytes(), nil
+}
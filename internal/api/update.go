@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"github.com/ozonva/ova-service-api/internal/events"
 
 	"github.com/golang/protobuf/ptypes/empty"
@@ -10,11 +11,12 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/ozonva/ova-service-api/internal/eventbus"
 	"github.com/ozonva/ova-service-api/internal/models"
 	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
-func (s *GrpcApiServer) UpdateServiceV1(_ context.Context, req *pb.UpdateServiceV1Request) (*empty.Empty, error) {
+func (s *GrpcApiServer) UpdateServiceV1(ctx context.Context, req *pb.UpdateServiceV1Request) (*empty.Empty, error) {
 	log.Info().Msg("UpdateServiceV1 is called...")
 
 	if req == nil {
@@ -31,9 +33,27 @@ func (s *GrpcApiServer) UpdateServiceV1(_ context.Context, req *pb.UpdateService
 		return nil, invalidArgErr
 	}
 
-	when := extractTimeFromTimestamp(req.GetWhen())
-	updatedService, err := models.NewService(req.UserId, req.Description, req.ServiceName, req.ServiceAddress, when)
+	when, err := parseWhen(req.GetWhen(), req.WhenLocal, req.TimeZone)
+	if err != nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Service time is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in UpdateServiceV1")
+		return nil, invalidArgErr
+	}
+
+	end, err := parseEnd(req.GetEnd(), req.EndLocal, req.GetDuration(), when, req.TimeZone)
+	if err != nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Service end is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in UpdateServiceV1")
+		return nil, invalidArgErr
+	}
+
+	updatedService, err := models.NewService(s.ids, req.UserId, req.Description, req.ServiceName, req.ServiceAddress, when, end, req.TimeZone)
 
+	if errors.Is(err, models.ErrInvalidEnd) {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Service end is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in UpdateServiceV1")
+		return nil, invalidArgErr
+	}
 	if err != nil {
 		internalErr := status.Errorf(codes.Internal, "Error occurred during domain service creation: %s", err.Error())
 		log.Err(internalErr).Msg("Error occurred in UpdateServiceV1")
@@ -42,14 +62,26 @@ func (s *GrpcApiServer) UpdateServiceV1(_ context.Context, req *pb.UpdateService
 
 	updatedService.ID = serviceID
 	repoErr := s.repo.UpdateService(updatedService)
+	if conflict, ok := conflictStatus(repoErr); ok {
+		log.Err(conflict.Err()).Msg("Error occurred in UpdateServiceV1")
+		return nil, conflict.Err()
+	}
 	if repoErr != nil {
 		return nil, status.Errorf(codes.Internal, "Error occurred during saving to repo: %s", repoErr.Error())
 	}
 
-	event := events.NewServiceUpdateEvent(serviceID)
-	kafkaErr := s.producer.SendMessage(event.String())
-	if kafkaErr != nil {
-		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce Update event to Kafka: %s", kafkaErr.Error())
+	event := events.NewServiceUpdateEvent(serviceID, updatedService.UserID)
+	message, encodeErr := s.encoder.Encode(event)
+	if encodeErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to encode Update event: %s", encodeErr.Error())
+	}
+
+	messages := []eventbus.Message{message}
+	publishSpan := startPublishSpan(ctx, messages)
+	publishErr := s.writePublisher.Publish(messages[0])
+	publishSpan.Finish()
+	if publishErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred while trying to produce Update event to event bus: %s", publishErr.Error())
 	}
 
 	s.metrics.IncrementUpdateCounter()
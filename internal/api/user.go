@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+func (s *GrpcApiServer) GetUserCalendarV1(_ context.Context, req *pb.GetUserCalendarV1Request) (*pb.GetUserCalendarV1Response, error) {
+	log.Info().Msg("GetUserCalendarV1 is called...")
+
+	if req == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Request argument is nil")
+		log.Err(invalidArgErr).Msg("Error occurred in GetUserCalendarV1")
+		return nil, invalidArgErr
+	}
+
+	if s.readModel == nil {
+		preconditionErr := status.Errorf(codes.FailedPrecondition, "User calendar is served from the read model, which is disabled")
+		log.Err(preconditionErr).Msg("Error occurred in GetUserCalendarV1")
+		return nil, preconditionErr
+	}
+
+	if req.From == nil || req.To == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Both from and to of the calendar must be set")
+		log.Err(invalidArgErr).Msg("Error occurred in GetUserCalendarV1")
+		return nil, invalidArgErr
+	}
+
+	filter, err := mapIntervalFilter(req.From, req.To, false)
+	if err != nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Interval is not valid: %s", err.Error())
+		log.Err(invalidArgErr).Msg("Error occurred in GetUserCalendarV1")
+		return nil, invalidArgErr
+	}
+
+	services, readErr := s.readModel.UserCalendar(req.UserId, filter.From, filter.To)
+	if readErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred during read user calendar: %s", readErr.Error())
+	}
+
+	infos := make([]*pb.ServiceShortInfoV1Response, len(services))
+
+	for i, service := range services {
+		info, mapErr := mapServiceToServiceShortInfoV1Response(&service)
+
+		if mapErr != nil {
+			return nil, status.Errorf(codes.Internal, "can't convert domain entity \"service\" at index %d to response entity: %s", i, mapErr.Error())
+		}
+
+		infos[i] = info
+	}
+
+	return &pb.GetUserCalendarV1Response{ServiceShortInfo: infos}, nil
+}
+
+func (s *GrpcApiServer) GetUserCountersV1(_ context.Context, req *pb.GetUserCountersV1Request) (*pb.GetUserCountersV1Response, error) {
+	log.Info().Msg("GetUserCountersV1 is called...")
+
+	if req == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Request argument is nil")
+		log.Err(invalidArgErr).Msg("Error occurred in GetUserCountersV1")
+		return nil, invalidArgErr
+	}
+
+	if s.readModel == nil {
+		preconditionErr := status.Errorf(codes.FailedPrecondition, "User counters are served from the read model, which is disabled")
+		log.Err(preconditionErr).Msg("Error occurred in GetUserCountersV1")
+		return nil, preconditionErr
+	}
+
+	counters, readErr := s.readModel.UserCounters(req.UserId)
+	if readErr != nil {
+		return nil, status.Errorf(codes.Internal, "Error occurred during read user counters: %s", readErr.Error())
+	}
+
+	return &pb.GetUserCountersV1Response{
+		UserId:    counters.UserID,
+		Total:     counters.Total,
+		Scheduled: counters.Scheduled,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package api_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/projection"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+var _ = Describe("User", func() {
+	var (
+		ctx       context.Context
+		ctrl      *gomock.Controller
+		repoMock  *mocks.MockRepo
+		readModel *projection.MemoryStore
+		server    *api.GrpcApiServer
+		from      time.Time
+	)
+
+	newService := func(userID uint64, when *time.Time) models.Service {
+		service := models.Service{ID: uuid.New(), UserID: userID, ServiceName: "Car service"}
+		Expect(service.RestoreCalendar(when, nil, "Europe/Moscow")).Should(Succeed())
+		return service
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		repoMock = mocks.NewMockRepo(ctrl)
+		readModel = projection.NewMemoryStore()
+		server = api.NewGrpcApiServer(repoMock, nil, nil, nil, nil, nil).WithReadModel(readModel)
+		from = time.Date(2040, 1, 10, 0, 0, 0, 0, time.UTC)
+
+		inside := from.Add(10 * time.Hour)
+		outside := from.Add(48 * time.Hour)
+		for _, service := range []models.Service{
+			newService(1, &inside),
+			newService(1, &outside),
+			newService(1, nil),
+			newService(2, &inside),
+		} {
+			Expect(readModel.Upsert(service)).Should(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Context("on calling GetUserCalendarV1 endpoint", func() {
+		When("range is set", func() {
+			It("should return services of the user in the range from the read model", func() {
+				res, err := server.GetUserCalendarV1(ctx, &pb.GetUserCalendarV1Request{
+					UserId: 1,
+					From:   timestamppb.New(from),
+					To:     timestamppb.New(from.Add(24 * time.Hour)),
+				})
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.ServiceShortInfo).Should(HaveLen(1))
+				Expect(res.ServiceShortInfo[0].UserId).Should(BeEquivalentTo(1))
+				Expect(res.ServiceShortInfo[0].WhenLocal).Should(Equal("2040-01-10T13:00:00+03:00"))
+			})
+		})
+
+		When("range is not set", func() {
+			It("should return InvalidArgument error", func() {
+				_, err := server.GetUserCalendarV1(ctx, &pb.GetUserCalendarV1Request{UserId: 1, From: timestamppb.New(from)})
+
+				Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+
+		When("range ends before it starts", func() {
+			It("should return InvalidArgument error", func() {
+				_, err := server.GetUserCalendarV1(ctx, &pb.GetUserCalendarV1Request{
+					UserId: 1,
+					From:   timestamppb.New(from),
+					To:     timestamppb.New(from.Add(-time.Hour)),
+				})
+
+				Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+
+		When("read model is disabled", func() {
+			It("should return FailedPrecondition error", func() {
+				server := api.NewGrpcApiServer(repoMock, nil, nil, nil, nil, nil)
+
+				_, err := server.GetUserCalendarV1(ctx, &pb.GetUserCalendarV1Request{
+					UserId: 1,
+					From:   timestamppb.New(from),
+					To:     timestamppb.New(from.Add(24 * time.Hour)),
+				})
+
+				Expect(status.Code(err)).Should(Equal(codes.FailedPrecondition))
+			})
+		})
+	})
+
+	Context("on calling GetUserCountersV1 endpoint", func() {
+		When("user has services", func() {
+			It("should return counters of the user from the read model", func() {
+				res, err := server.GetUserCountersV1(ctx, &pb.GetUserCountersV1Request{UserId: 1})
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.UserId).Should(BeEquivalentTo(1))
+				Expect(res.Total).Should(BeEquivalentTo(3))
+				Expect(res.Scheduled).Should(BeEquivalentTo(2))
+			})
+		})
+
+		When("user has no services", func() {
+			It("should return zero counters", func() {
+				res, err := server.GetUserCountersV1(ctx, &pb.GetUserCountersV1Request{UserId: 3})
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.UserId).Should(BeEquivalentTo(3))
+				Expect(res.Total).Should(BeZero())
+			})
+		})
+
+		When("read model is disabled", func() {
+			It("should return FailedPrecondition error", func() {
+				server := api.NewGrpcApiServer(repoMock, nil, nil, nil, nil, nil)
+
+				_, err := server.GetUserCountersV1(ctx, &pb.GetUserCountersV1Request{UserId: 1})
+
+				Expect(status.Code(err)).Should(Equal(codes.FailedPrecondition))
+			})
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ozonva/ova-service-api/internal/watch"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// LastEventIDHeader is sent by SSE clients on reconnect, the HTTP gateway forwards it as metadata
+const LastEventIDHeader = "last-event-id"
+
+// DefaultHeartbeatInterval is the period of heartbeats sent to idle watchers
+const DefaultHeartbeatInterval = 30 * time.Second
+
+type Watcher interface {
+	Subscribe(cursor string, filter watch.Filter, buffer int) (*watch.Subscription, error)
+}
+
+// WithWatcher enables WatchServicesV1, it returns Unimplemented without the watcher.
+func (s *GrpcApiServer) WithWatcher(watcher Watcher, heartbeatInterval time.Duration) *GrpcApiServer {
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	s.watcher = watcher
+	s.heartbeatInterval = heartbeatInterval
+	return s
+}
+
+func (s *GrpcApiServer) WatchServicesV1(req *pb.WatchServicesV1Request, stream pb.ServiceAPI_WatchServicesV1Server) error {
+	log.Info().Msg("WatchServicesV1 is called...")
+
+	if req == nil {
+		invalidArgErr := status.Errorf(codes.InvalidArgument, "Request argument is nil")
+		log.Err(invalidArgErr).Msg("Error occurred in WatchServicesV1")
+		return invalidArgErr
+	}
+
+	if s.watcher == nil {
+		return status.Error(codes.Unimplemented, "Watching services is not configured")
+	}
+
+	cursor := req.Cursor
+	if len(cursor) == 0 {
+		cursor = lastEventID(stream.Context())
+	}
+
+	subscription, err := s.watcher.Subscribe(cursor, watch.Filter{UserID: req.UserId}, 0)
+	switch {
+	case errors.Is(err, watch.ErrInvalidCursor):
+		return status.Errorf(codes.InvalidArgument, "Cursor %q is not valid", cursor)
+	case errors.Is(err, watch.ErrCursorExpired):
+		return status.Error(codes.OutOfRange, "Changes after the cursor are not available anymore, reload services and watch without cursor")
+	case err != nil:
+		return status.Errorf(codes.Internal, "Error occurred while subscribing to changes: %s", err.Error())
+	}
+	defer subscription.Close()
+
+	heartbeat := time.NewTicker(s.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	lastCursor := subscription.Start()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if err = stream.Send(&pb.WatchServicesV1Response{Cursor: lastCursor}); err != nil {
+				return err
+			}
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				if errors.Is(subscription.Err(), watch.ErrSlowSubscriber) {
+					return status.Error(codes.ResourceExhausted, "Client doesn't keep up with changes, resume from the last received cursor")
+				}
+				return status.Error(codes.Unavailable, "Server is shutting down, resume from the last received cursor")
+			}
+
+			if err = stream.Send(&pb.WatchServicesV1Response{Cursor: change.Cursor, Event: change.Event}); err != nil {
+				return err
+			}
+			lastCursor = change.Cursor
+		}
+	}
+}
+
+func lastEventID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(LastEventIDHeader)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
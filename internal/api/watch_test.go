@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ozonva/ova-service-api/internal/api"
+	"github.com/ozonva/ova-service-api/internal/watch"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pb.WatchServicesV1Response
+}
+
+func (s *fakeWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchStream) Send(response *pb.WatchServicesV1Response) error {
+	s.sent <- response
+	return nil
+}
+
+var _ = Describe("Watch", func() {
+	var (
+		hub    *watch.Hub
+		server *api.GrpcApiServer
+		ctx    context.Context
+		cancel context.CancelFunc
+		stream *fakeWatchStream
+		start  string
+	)
+
+	BeforeEach(func() {
+		hub = watch.NewHub(10)
+		server = api.NewGrpcApiServer(nil, nil, nil, nil, nil, nil).WithWatcher(hub, time.Hour)
+		ctx, cancel = context.WithCancel(context.Background())
+		stream = &fakeWatchStream{ctx: ctx, sent: make(chan *pb.WatchServicesV1Response, 10)}
+
+		// Changes are ingested after the start cursor, so they are delivered regardless of the subscription moment
+		subscription, err := hub.Subscribe("", watch.Filter{}, 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		subscription.Close()
+		start = subscription.Start()
+
+		hub.Ingest(&pb.ServiceCUDEventV1{EventId: "first", UserId: 1})
+		hub.Ingest(&pb.ServiceCUDEventV1{EventId: "second", UserId: 2})
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Context("on calling WatchServices endpoint", func() {
+		When("cursor is passed", func() {
+			It("should stream changes of the user after the cursor until the client leaves", func() {
+				done := make(chan error)
+				go func() {
+					done <- server.WatchServicesV1(&pb.WatchServicesV1Request{UserId: 2, Cursor: start}, stream)
+				}()
+
+				var response *pb.WatchServicesV1Response
+				Eventually(stream.sent).Should(Receive(&response))
+				Expect(response.Event.EventId).Should(BeEquivalentTo("second"))
+				Expect(response.Cursor).ShouldNot(BeEmpty())
+
+				cancel()
+				Eventually(done).Should(Receive(BeNil()))
+			})
+		})
+
+		When("cursor is passed in Last-Event-ID header", func() {
+			It("should resume from it", func() {
+				stream.ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(api.LastEventIDHeader, start))
+				go func() {
+					_ = server.WatchServicesV1(&pb.WatchServicesV1Request{}, stream)
+				}()
+
+				var response *pb.WatchServicesV1Response
+				Eventually(stream.sent).Should(Receive(&response))
+				Expect(response.Event.EventId).Should(BeEquivalentTo("first"))
+			})
+		})
+
+		When("cursor is expired", func() {
+			It("should return OutOfRange error", func() {
+				otherServer := api.NewGrpcApiServer(nil, nil, nil, nil, nil, nil).WithWatcher(watch.NewHub(10), time.Hour)
+				watchErr := otherServer.WatchServicesV1(&pb.WatchServicesV1Request{Cursor: start}, stream)
+
+				Expect(status.Code(watchErr)).Should(Equal(codes.OutOfRange))
+			})
+		})
+
+		When("watcher is not configured", func() {
+			It("should return Unimplemented error", func() {
+				err := api.NewGrpcApiServer(nil, nil, nil, nil, nil, nil).WatchServicesV1(&pb.WatchServicesV1Request{}, stream)
+
+				Expect(status.Code(err)).Should(Equal(codes.Unimplemented))
+			})
+		})
+	})
+
+	Context("on marshaling changes as Server-Sent Events", func() {
+		It("should send change as event named by its type with cursor as ID", func() {
+			response := &pb.WatchServicesV1Response{
+				Cursor: "abc-1",
+				Event:  &pb.ServiceCUDEventV1{EventId: "first", EventType: pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED},
+			}
+
+			data, err := api.NewSSEMarshaler().Marshal(map[string]interface{}{"result": response})
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(HavePrefix("id: abc-1\nevent: created\ndata: {"))
+			Expect(string(data)).Should(ContainSubstring(`"event_id":"first"`))
+			Expect(string(data)).Should(HaveSuffix("}\n"))
+		})
+
+		It("should send heartbeat as comment", func() {
+			data, err := api.NewSSEMarshaler().Marshal(map[string]interface{}{"result": &pb.WatchServicesV1Response{Cursor: "abc-1"}})
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(Equal(": heartbeat\n"))
+		})
+	})
+})
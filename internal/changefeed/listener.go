@@ -0,0 +1,112 @@
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
+)
+
+// Reconnect delays of the listener, the delay is doubled after every failed attempt
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Handler receives events in the order the changes were committed.
+type Handler func(event events.ServiceCUDEvent) error
+
+// Encoder converts events to messages, events.Encoder satisfies it.
+type Encoder interface {
+	Encode(event events.ServiceCUDEvent) (eventbus.Message, error)
+}
+
+// Listener turns notifications of the services table trigger into events. It is the lightweight alternative
+// to events produced by the API: changes are captured by the database itself, including changes made
+// bypassing the API. Postgres doesn't keep notifications for disconnected listeners, so changes committed
+// while the listener reconnects are lost, consumers which need every change should use the event bus.
+type Listener struct {
+	dsn     string
+	handler Handler
+}
+
+func NewListener(dsn string, handler Handler) *Listener {
+	return &Listener{
+		dsn:     dsn,
+		handler: handler,
+	}
+}
+
+// Run listens for notifications until the context is canceled, the connection is restored after failures.
+func (l *Listener) Run(ctx context.Context) error {
+	delay := minReconnectDelay
+
+	for {
+		err := l.listen(ctx, func() { delay = minReconnectDelay })
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		log.Err(err).Dur("delay", delay).Msg("Change feed connection is lost, reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// listen handles notifications of the single connection, onListen is called once LISTEN succeeds
+func (l *Listener) listen(ctx context.Context, onListen func()) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	onListen()
+	log.Info().Str("channel", Channel).Msg("Change feed is listening")
+
+	for {
+		n, waitErr := conn.WaitForNotification(ctx)
+		if waitErr != nil {
+			return waitErr
+		}
+
+		event, parseErr := ParseNotification(n.Payload)
+		if parseErr != nil {
+			log.Err(parseErr).Str("payload", n.Payload).Msg("Skipping invalid change notification")
+			continue
+		}
+
+		if handleErr := l.handler(event); handleErr != nil {
+			log.Err(handleErr).Str("event", event.String()).Msg("Failed to handle change notification")
+		}
+	}
+}
+
+// PublishTo returns the handler which encodes events and publishes them, so the change feed
+// feeds the event bus or in-process subscribers of the memory broker.
+func PublishTo(publisher eventbus.Publisher, encoder Encoder) Handler {
+	return func(event events.ServiceCUDEvent) error {
+		message, err := encoder.Encode(event)
+		if err != nil {
+			return err
+		}
+
+		return publisher.Publish(message)
+	}
+}
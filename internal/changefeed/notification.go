@@ -0,0 +1,49 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+)
+
+// Channel is the notification channel the services table trigger notifies, see migrations/00002_services_notify.sql
+const Channel = "service_changes"
+
+// notification is the NOTIFY payload built by the trigger
+type notification struct {
+	Op        string    `json:"op"`
+	ServiceID uuid.UUID `json:"service_id"`
+	UserID    uint64    `json:"user_id"`
+	At        time.Time `json:"at"`
+}
+
+// ParseNotification converts the trigger payload to the event of the same shape the API produces.
+// Every notification gets the new event ID, the timestamp is the time of the database transaction.
+func ParseNotification(payload string) (events.ServiceCUDEvent, error) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return events.ServiceCUDEvent{}, fmt.Errorf("can't parse change notification: %w", err)
+	}
+
+	var event events.ServiceCUDEvent
+	switch n.Op {
+	case "INSERT":
+		event = events.NewServiceCreateEvent(n.ServiceID, n.UserID)
+	case "UPDATE":
+		event = events.NewServiceUpdateEvent(n.ServiceID, n.UserID)
+	case "DELETE":
+		event = events.NewServiceDeleteEvent(n.ServiceID, n.UserID)
+	default:
+		return events.ServiceCUDEvent{}, fmt.Errorf("unknown change operation: %q", n.Op)
+	}
+
+	if !n.At.IsZero() {
+		event.Timestamp = n.At.UTC()
+	}
+
+	return event, nil
+}
@@ -0,0 +1,48 @@
+package changefeed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+)
+
+func TestParseNotification_WhenPayloadIsBuiltByTrigger_ShouldReturnEvent(t *testing.T) {
+	serviceID := uuid.New()
+	// json_build_object renders timestamptz with the offset
+	payload := `{"op" : "UPDATE", "service_id" : "` + serviceID.String() + `", "user_id" : 7, "at" : "2021-10-19T12:30:00.123456+03:00"}`
+
+	event, err := ParseNotification(payload)
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, events.Update, event.EventType)
+	assert.Equal(t, serviceID, event.ServiceID)
+	assert.Equal(t, uint64(7), event.UserID)
+	assert.Equal(t, time.Date(2021, 10, 19, 9, 30, 0, 123456000, time.UTC), event.Timestamp)
+	assert.NotEqual(t, uuid.Nil, event.EventID, "Event ID should be generated")
+}
+
+func TestParseNotification_WhenOperationsAreMapped_ShouldUseEventTypes(t *testing.T) {
+	for op, eventType := range map[string]events.EventType{"INSERT": events.Create, "UPDATE": events.Update, "DELETE": events.Delete} {
+		event, err := ParseNotification(`{"op": "` + op + `", "service_id": "` + uuid.New().String() + `", "user_id": 1}`)
+
+		require.NoError(t, err, "No error should be returned for %s", op)
+		assert.Equal(t, eventType, event.EventType, "Unexpected event type for %s", op)
+	}
+}
+
+func TestParseNotification_WhenOperationIsUnknown_ShouldReturnError(t *testing.T) {
+	_, err := ParseNotification(`{"op": "TRUNCATE", "service_id": "` + uuid.New().String() + `", "user_id": 1}`)
+
+	assert.EqualError(t, err, `unknown change operation: "TRUNCATE"`)
+}
+
+func TestParseNotification_WhenPayloadIsNotJSON_ShouldReturnError(t *testing.T) {
+	_, err := ParseNotification("service changed")
+
+	assert.Error(t, err, "Invalid payload should be reported")
+}
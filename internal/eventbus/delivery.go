@@ -0,0 +1,47 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+// DeliveryHandler receives delivery results of messages published asynchronously, err is nil on successful delivery.
+type DeliveryHandler func(message Message, err error)
+
+// DeliveryRelay passes delivery results of the asynchronous Kafka producer to the handler bound after the producer
+// is created, e.g. to the publisher which wraps the producer. Failures are logged while no handler is bound.
+type DeliveryRelay struct {
+	sync.RWMutex
+	handler DeliveryHandler
+}
+
+func NewDeliveryRelay() *DeliveryRelay {
+	return &DeliveryRelay{}
+}
+
+// Bind sets the handler of delivery results reported after the call
+func (r *DeliveryRelay) Bind(handler DeliveryHandler) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.handler = handler
+}
+
+// OnDelivery is the kafka.DeliveryCallback of the producer
+func (r *DeliveryRelay) OnDelivery(message kafka.Message, err error) {
+	r.RLock()
+	handler := r.handler
+	r.RUnlock()
+
+	if handler != nil {
+		handler(Message(message), err)
+		return
+	}
+
+	if err != nil {
+		log.Err(err).Str("key", string(message.Key)).Msg("Failed to deliver message to Kafka, no delivery handler is bound")
+	}
+}
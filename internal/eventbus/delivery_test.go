@@ -0,0 +1,25 @@
+package eventbus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+func TestDeliveryRelay_WhenHandlerIsBound_ShouldPassDeliveryResults(t *testing.T) {
+	relay := NewDeliveryRelay()
+	relay.OnDelivery(kafka.Message{Value: []byte("before")}, fmt.Errorf("broker is down"))
+
+	var results []string
+	relay.Bind(func(message Message, err error) {
+		results = append(results, fmt.Sprintf("%s: %v", message.Value, err))
+	})
+	relay.OnDelivery(kafka.Message{Value: []byte("delivered")}, nil)
+	relay.OnDelivery(kafka.Message{Value: []byte("lost")}, fmt.Errorf("broker is down"))
+
+	assert.Equal(t, []string{"delivered: <nil>", "lost: broker is down"}, results,
+		"Results reported after the bind should be passed to the handler")
+}
@@ -0,0 +1,89 @@
+package eventbus
+
+import (
+	"fmt"
+
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+// Supported event bus drivers
+const (
+	DriverKafka  = "kafka"
+	DriverMemory = "memory"
+	DriverFile   = "file"
+	DriverStdout = "stdout"
+)
+
+// ContentTypeHeader is the message header describing the payload format
+const ContentTypeHeader = "content-type"
+
+// Message is the encoded event together with its transport metadata. Drivers which have no notion of keys
+// or headers (e.g. file sink) store them alongside the value.
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Publisher delivers messages to the configured sink. The service is able to run with any of the drivers,
+// Kafka is required only for the "kafka" one.
+type Publisher interface {
+	Publish(message Message) error
+	PublishBatch(messages []Message) error
+	Close() error
+}
+
+// KafkaPublisher is the Publisher driver producing messages to Kafka topic.
+type KafkaPublisher struct {
+	producer kafka.Producer
+}
+
+func NewKafkaPublisher(producer kafka.Producer) *KafkaPublisher {
+	return &KafkaPublisher{
+		producer: producer,
+	}
+}
+
+func (p *KafkaPublisher) Publish(message Message) error {
+	return p.producer.SendMessage(kafka.Message(message))
+}
+
+func (p *KafkaPublisher) PublishBatch(messages []Message) error {
+	kafkaMessages := make([]kafka.Message, len(messages))
+
+	for i, message := range messages {
+		kafkaMessages[i] = kafka.Message(message)
+	}
+
+	return p.producer.SendMessages(kafkaMessages)
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.producer.Close()
+}
+
+func validateMessages(messages []Message) error {
+	for _, message := range messages {
+		if len(message.Value) == 0 {
+			return fmt.Errorf("empty message is not allowed")
+		}
+	}
+
+	return nil
+}
+
+// Discard is the Publisher dropping every message. It is used when events are produced by another
+// component, e.g. by the database change feed.
+type Discard struct{}
+
+func (Discard) Publish(Message) error {
+	return nil
+}
+
+func (Discard) PublishBatch([]Message) error {
+	return nil
+}
+
+func (Discard) Close() error {
+	return nil
+}
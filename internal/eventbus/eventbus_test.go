@@ -0,0 +1,33 @@
+package eventbus_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+)
+
+func TestKafkaPublisher_WhenBatchIsPublished_ShouldSendAllMessagesToProducer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	producerMock := mocks.NewMockProducer(ctrl)
+	producerMock.EXPECT().SendMessages([]kafka.Message{
+		{Key: []byte("1"), Value: []byte("first")},
+		{Key: []byte("2"), Value: []byte("second"), Headers: map[string]string{"a": "b"}},
+	}).Return(nil).Times(1)
+	producerMock.EXPECT().Close().Return(nil).Times(1)
+
+	publisher := eventbus.NewKafkaPublisher(producerMock)
+	err := publisher.PublishBatch([]eventbus.Message{
+		{Key: []byte("1"), Value: []byte("first")},
+		{Key: []byte("2"), Value: []byte("second"), Headers: map[string]string{"a": "b"}},
+	})
+
+	require.NoError(t, err, "No error should be returned")
+	require.NoError(t, publisher.Close(), "Producer should be closed")
+}
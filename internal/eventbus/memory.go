@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryBroker is the in-process Publisher. It keeps no history: only subscribers registered before publishing
+// receive the message. It is intended for tests and local runs without the message broker.
+type MemoryBroker struct {
+	sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// Subscription receives every message published to the broker after the subscription is created.
+type Subscription struct {
+	broker   *MemoryBroker
+	messages chan Message
+	dropped  uint64
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers the new subscriber with the given buffer size. The broker never blocks publishers:
+// if subscriber does not keep up and its buffer is full, the message is dropped for that subscriber.
+func (b *MemoryBroker) Subscribe(buffer int) *Subscription {
+	b.Lock()
+	defer b.Unlock()
+
+	subscription := &Subscription{
+		broker:   b,
+		messages: make(chan Message, buffer),
+	}
+
+	if b.closed {
+		close(subscription.messages)
+		return subscription
+	}
+
+	b.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+func (b *MemoryBroker) Publish(message Message) error {
+	return b.PublishBatch([]Message{message})
+}
+
+func (b *MemoryBroker) PublishBatch(messages []Message) error {
+	if err := validateMessages(messages); err != nil {
+		return err
+	}
+
+	b.RLock()
+	defer b.RUnlock()
+
+	if b.closed {
+		return fmt.Errorf("broker is closed")
+	}
+
+	for subscription := range b.subscriptions {
+		for _, message := range messages {
+			select {
+			case subscription.messages <- message:
+			default:
+				atomic.AddUint64(&subscription.dropped, 1)
+				log.Warn().Msg("Memory broker subscriber is full, message is dropped")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close closes channels of all subscriptions.
+func (b *MemoryBroker) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+	for subscription := range b.subscriptions {
+		close(subscription.messages)
+		delete(b.subscriptions, subscription)
+	}
+
+	return nil
+}
+
+// Messages returns the channel of received messages. The channel is closed on unsubscribe or when broker is closed.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Dropped returns the number of messages lost because the subscription buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscription) Unsubscribe() {
+	s.broker.Lock()
+	defer s.broker.Unlock()
+
+	if _, ok := s.broker.subscriptions[s]; !ok {
+		return
+	}
+
+	delete(s.broker.subscriptions, s)
+	close(s.messages)
+}
@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBroker_WhenMessagesArePublished_ShouldDeliverThemToEverySubscriber(t *testing.T) {
+	broker := NewMemoryBroker()
+	first := broker.Subscribe(10)
+	second := broker.Subscribe(10)
+
+	require.NoError(t, broker.Publish(Message{Value: []byte("1")}))
+	require.NoError(t, broker.PublishBatch([]Message{{Value: []byte("2")}, {Value: []byte("3")}}))
+	require.NoError(t, broker.Close())
+
+	for _, subscription := range []*Subscription{first, second} {
+		var got []string
+		for message := range subscription.Messages() {
+			got = append(got, string(message.Value))
+		}
+		assert.Equal(t, []string{"1", "2", "3"}, got, "Every subscriber should receive all messages in order")
+	}
+}
+
+func TestMemoryBroker_WhenSubscriberIsFull_ShouldDropMessagesWithoutBlocking(t *testing.T) {
+	broker := NewMemoryBroker()
+	subscription := broker.Subscribe(1)
+
+	require.NoError(t, broker.PublishBatch([]Message{{Value: []byte("1")}, {Value: []byte("2")}}))
+
+	assert.Equal(t, uint64(1), subscription.Dropped(), "Message which doesn't fit the buffer should be dropped")
+	assert.Equal(t, "1", string((<-subscription.Messages()).Value))
+}
+
+func TestMemoryBroker_WhenUnsubscribed_ShouldStopDelivery(t *testing.T) {
+	broker := NewMemoryBroker()
+	subscription := broker.Subscribe(1)
+
+	subscription.Unsubscribe()
+	require.NoError(t, broker.Publish(Message{Value: []byte("1")}))
+
+	_, ok := <-subscription.Messages()
+	assert.False(t, ok, "Channel should be closed after unsubscribe")
+}
+
+func TestMemoryBroker_WhenClosed_ShouldReturnError(t *testing.T) {
+	broker := NewMemoryBroker()
+	require.NoError(t, broker.Close())
+
+	err := broker.Publish(Message{Value: []byte("1")})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "broker is closed", err.Error(), "Incorrect error message")
+}
+
+func TestMemoryBroker_WhenMessageIsEmpty_ShouldReturnError(t *testing.T) {
+	err := NewMemoryBroker().Publish(Message{})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "empty message is not allowed", err.Error(), "Incorrect error message")
+}
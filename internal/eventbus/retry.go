@@ -0,0 +1,404 @@
+package eventbus
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Headers added to the messages sent to the dead-letter topic
+const (
+	DeadLetterHeaderPrefix        = "dlq-"
+	DeadLetterErrorHeader         = DeadLetterHeaderPrefix + "error"
+	DeadLetterAttemptsHeader      = DeadLetterHeaderPrefix + "attempts"
+	DeadLetterFirstFailedAtHeader = DeadLetterHeaderPrefix + "first-failed-at"
+	DeadLetterLastFailedAtHeader  = DeadLetterHeaderPrefix + "last-failed-at"
+)
+
+// Default retry settings
+const (
+	DefaultRetryAttempts    = 5
+	DefaultRetryInterval    = 5 * time.Second
+	DefaultRetryMaxInterval = 5 * time.Minute
+)
+
+// RetryMetrics tracks messages which failed to publish on the first attempt.
+type RetryMetrics interface {
+	IncrementEventRetried()
+	IncrementEventDeadLettered()
+	SetEventRetryQueueSize(size int)
+}
+
+// RetryConfig contains retry settings of the RetryingPublisher. Zero values fall back to defaults.
+type RetryConfig struct {
+	// MaxAttempts is the number of delivery attempts including the first one
+	MaxAttempts int
+	// Interval is the delay before the first retry, it is doubled on every next attempt
+	Interval time.Duration
+	// MaxInterval limits the delay between attempts
+	MaxInterval time.Duration
+	// AsyncDelivery is set if the publisher returns before delivery and reports failures to HandleDelivery later,
+	// e.g. the Kafka publisher with the asynchronous producer. Messages with the key of the failed one which are
+	// published before the failure is reported are not held back, so their order is not kept.
+	AsyncDelivery bool
+}
+
+// RetryingPublisher does not lose messages when the underlying publisher fails. Failed messages are put
+// to the retry queue and retried in background with exponential backoff. Messages which exhausted attempts
+// are sent to the dead-letter publisher with the error metadata in headers.
+//
+// Publish succeeds as soon as the message is queued, so the caller doesn't report error for the change
+// already stored in the database. New messages with the key of the queued one are queued as well
+// to keep the order of events of the same entity.
+type RetryingPublisher struct {
+	publisher  Publisher
+	deadLetter Publisher
+	queue      *RetryQueue
+	config     RetryConfig
+	metrics    RetryMetrics
+	done       chan struct{}
+	wg         sync.WaitGroup
+	// retried keeps entries sent by retries until their delivery is reported, if delivery is asynchronous
+	retriedMu sync.Mutex
+	retried   map[[sha256.Size]byte]RetryEntry
+}
+
+// NewRetryingPublisher starts the background retry loop. Dead-letter publisher is optional,
+// messages which exhausted attempts are logged and dropped without it.
+func NewRetryingPublisher(publisher Publisher, deadLetter Publisher, queue *RetryQueue, config RetryConfig, metrics RetryMetrics) *RetryingPublisher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultRetryAttempts
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultRetryInterval
+	}
+	if config.MaxInterval < config.Interval {
+		config.MaxInterval = DefaultRetryMaxInterval
+	}
+
+	p := &RetryingPublisher{
+		publisher:  publisher,
+		deadLetter: deadLetter,
+		queue:      queue,
+		config:     config,
+		metrics:    metrics,
+		done:       make(chan struct{}),
+		retried:    make(map[[sha256.Size]byte]RetryEntry),
+	}
+
+	p.reportQueueSize()
+
+	p.wg.Add(1)
+	go p.run()
+
+	return p
+}
+
+func (p *RetryingPublisher) Publish(message Message) error {
+	return p.PublishBatch([]Message{message})
+}
+
+func (p *RetryingPublisher) PublishBatch(messages []Message) error {
+	if err := validateMessages(messages); err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if p.queue.HasKey(message.Key) {
+			return p.enqueue(messages, fmt.Errorf("earlier message with the same key is waiting for retry"))
+		}
+	}
+
+	var err error
+	if len(messages) == 1 {
+		err = p.publisher.Publish(messages[0])
+	} else {
+		err = p.publisher.PublishBatch(messages)
+	}
+
+	if err != nil {
+		return p.enqueue(messages, err)
+	}
+
+	return nil
+}
+
+// HandleDelivery queues the message which failed to deliver asynchronously for retry, the attempts of the message
+// sent by the retry are continued, so it goes to the dead-letter publisher once they are exhausted.
+// It is the DeliveryHandler of the publisher with RetryConfig.AsyncDelivery.
+func (p *RetryingPublisher) HandleDelivery(message Message, err error) {
+	fingerprint := messageFingerprint(message)
+
+	p.retriedMu.Lock()
+	entry, retried := p.retried[fingerprint]
+	delete(p.retried, fingerprint)
+	p.retriedMu.Unlock()
+
+	if err == nil {
+		if retried {
+			p.incrementRetried()
+		}
+		return
+	}
+
+	now := time.Now().UTC()
+	if !retried {
+		entry = RetryEntry{Message: message, FirstFailedAt: now}
+	}
+	entry.Attempts++
+	entry.LastError = err.Error()
+
+	if entry.Attempts >= p.config.MaxAttempts {
+		deadLetterErr := p.sendToDeadLetter(entry, now)
+		if deadLetterErr == nil {
+			return
+		}
+
+		// Keep the message in the queue and try the dead-letter publisher again with the next attempt
+		log.Err(deadLetterErr).Msg("Failed to send message to the dead-letter publisher")
+	}
+
+	entry.NextAttemptAt = now.Add(p.backoff(entry.Attempts))
+	if pushErr := p.queue.Push([]RetryEntry{entry}); pushErr != nil {
+		log.Err(pushErr).Str("key", string(message.Key)).Msg("Failed to put undelivered message to the retry queue, it is dropped")
+		return
+	}
+
+	log.Warn().Err(err).Msg("Failed to deliver message, it is queued for retry")
+	p.reportQueueSize()
+}
+
+// Close stops the retry loop and closes underlying publishers. Messages left in the persistent queue
+// are retried after the restart.
+func (p *RetryingPublisher) Close() error {
+	close(p.done)
+	p.wg.Wait()
+
+	err := p.publisher.Close()
+
+	if p.deadLetter != nil {
+		if deadLetterErr := p.deadLetter.Close(); deadLetterErr != nil && err == nil {
+			err = deadLetterErr
+		}
+	}
+
+	return err
+}
+
+func (p *RetryingPublisher) enqueue(messages []Message, publishErr error) error {
+	now := time.Now().UTC()
+
+	entries := make([]RetryEntry, len(messages))
+	for i, message := range messages {
+		entries[i] = RetryEntry{
+			Message:       message,
+			Attempts:      1,
+			LastError:     publishErr.Error(),
+			FirstFailedAt: now,
+			NextAttemptAt: now.Add(p.backoff(1)),
+		}
+	}
+
+	if err := p.queue.Push(entries); err != nil {
+		log.Err(err).Msg("Failed to put messages to the retry queue")
+		return publishErr
+	}
+
+	log.Warn().Err(publishErr).Int("messages", len(messages)).Msg("Failed to publish messages, they are queued for retry")
+	p.reportQueueSize()
+	return nil
+}
+
+func (p *RetryingPublisher) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.retryDue(time.Now().UTC())
+		}
+	}
+}
+
+// retryDue makes the next attempt for every message ready for it
+func (p *RetryingPublisher) retryDue(now time.Time) {
+	due := p.queue.Due(now)
+	if len(due) == 0 {
+		return
+	}
+
+	var (
+		removed    []uint64
+		updated    []RetryEntry
+		failedKeys = make(map[string]struct{})
+	)
+
+	for _, entry := range due {
+		key := string(entry.Message.Key)
+		if _, ok := failedKeys[key]; ok && len(key) > 0 {
+			continue
+		}
+
+		if p.config.AsyncDelivery {
+			// The result is reported to HandleDelivery, which queues the message again if it fails
+			p.trackRetried(entry)
+		}
+
+		err := p.publisher.Publish(entry.Message)
+		if err == nil {
+			removed = append(removed, entry.ID)
+			if !p.config.AsyncDelivery {
+				p.incrementRetried()
+			}
+			continue
+		}
+		if p.config.AsyncDelivery {
+			p.untrackRetried(entry)
+		}
+
+		entry.Attempts++
+		entry.LastError = err.Error()
+
+		if entry.Attempts >= p.config.MaxAttempts {
+			deadLetterErr := p.sendToDeadLetter(entry, now)
+			if deadLetterErr == nil {
+				removed = append(removed, entry.ID)
+				continue
+			}
+
+			// Keep the message in the queue and try the dead-letter publisher again with the next attempt
+			log.Err(deadLetterErr).Msg("Failed to send message to the dead-letter publisher")
+		}
+
+		entry.NextAttemptAt = now.Add(p.backoff(entry.Attempts))
+		updated = append(updated, entry)
+		failedKeys[key] = struct{}{}
+	}
+
+	if err := p.queue.Apply(removed, updated); err != nil {
+		log.Err(err).Msg("Failed to persist the retry queue")
+	}
+
+	p.reportQueueSize()
+}
+
+func (p *RetryingPublisher) sendToDeadLetter(entry RetryEntry, now time.Time) error {
+	if p.deadLetter == nil {
+		log.Error().
+			Str("key", string(entry.Message.Key)).
+			Bytes("value", entry.Message.Value).
+			Str("error", entry.LastError).
+			Msg("Message exhausted publish attempts and is dropped, dead-letter publisher is not configured")
+		p.incrementDeadLettered()
+		return nil
+	}
+
+	if err := p.deadLetter.Publish(NewDeadLetterMessage(entry, now)); err != nil {
+		return err
+	}
+
+	p.incrementDeadLettered()
+	return nil
+}
+
+func (p *RetryingPublisher) trackRetried(entry RetryEntry) {
+	p.retriedMu.Lock()
+	defer p.retriedMu.Unlock()
+
+	p.retried[messageFingerprint(entry.Message)] = entry
+}
+
+func (p *RetryingPublisher) untrackRetried(entry RetryEntry) {
+	p.retriedMu.Lock()
+	defer p.retriedMu.Unlock()
+
+	delete(p.retried, messageFingerprint(entry.Message))
+}
+
+// messageFingerprint identifies the message by its key and value, values of events are unique
+func messageFingerprint(message Message) [sha256.Size]byte {
+	hash := sha256.New()
+	hash.Write(message.Key)
+	hash.Write([]byte{0})
+	hash.Write(message.Value)
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], hash.Sum(nil))
+	return fingerprint
+}
+
+func (p *RetryingPublisher) backoff(attempts int) time.Duration {
+	interval := p.config.Interval
+	for i := 1; i < attempts && interval < p.config.MaxInterval; i++ {
+		interval *= 2
+	}
+
+	if interval > p.config.MaxInterval {
+		interval = p.config.MaxInterval
+	}
+
+	return interval
+}
+
+func (p *RetryingPublisher) incrementRetried() {
+	if p.metrics != nil {
+		p.metrics.IncrementEventRetried()
+	}
+}
+
+func (p *RetryingPublisher) incrementDeadLettered() {
+	if p.metrics != nil {
+		p.metrics.IncrementEventDeadLettered()
+	}
+}
+
+func (p *RetryingPublisher) reportQueueSize() {
+	if p.metrics != nil {
+		p.metrics.SetEventRetryQueueSize(p.queue.Len())
+	}
+}
+
+// NewDeadLetterMessage copies the failed message and adds the error metadata headers.
+func NewDeadLetterMessage(entry RetryEntry, failedAt time.Time) Message {
+	headers := make(map[string]string, len(entry.Message.Headers)+4)
+	for key, value := range entry.Message.Headers {
+		headers[key] = value
+	}
+
+	headers[DeadLetterErrorHeader] = entry.LastError
+	headers[DeadLetterAttemptsHeader] = strconv.Itoa(entry.Attempts)
+	headers[DeadLetterFirstFailedAtHeader] = entry.FirstFailedAt.UTC().Format(time.RFC3339Nano)
+	headers[DeadLetterLastFailedAtHeader] = failedAt.UTC().Format(time.RFC3339Nano)
+
+	return Message{
+		Key:     entry.Message.Key,
+		Value:   entry.Message.Value,
+		Headers: headers,
+	}
+}
+
+// StripDeadLetterHeaders returns the message as it was before it was sent to the dead-letter topic.
+func StripDeadLetterHeaders(message Message) Message {
+	headers := make(map[string]string, len(message.Headers))
+	for key, value := range message.Headers {
+		if !strings.HasPrefix(key, DeadLetterHeaderPrefix) {
+			headers[key] = value
+		}
+	}
+
+	return Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+	}
+}
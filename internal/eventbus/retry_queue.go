@@ -0,0 +1,218 @@
+package eventbus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultRetryQueueCapacity is the max number of messages waiting for retry.
+const DefaultRetryQueueCapacity = 10000
+
+// RetryEntry is the message which failed to publish together with the delivery attempts state.
+type RetryEntry struct {
+	ID            uint64    `json:"id"`
+	Message       Message   `json:"message"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// RetryQueue keeps messages waiting for retry in the order they failed. If the path is set, the queue is
+// persisted to the JSONL file on every change and loaded back on start, so messages survive the restart.
+// The file must not be shared between service instances.
+type RetryQueue struct {
+	sync.Mutex
+	path     string
+	capacity int
+	entries  []RetryEntry
+	nextID   uint64
+}
+
+func NewRetryQueue(path string, capacity int) (*RetryQueue, error) {
+	if capacity <= 0 {
+		capacity = DefaultRetryQueueCapacity
+	}
+
+	queue := &RetryQueue{
+		path:     path,
+		capacity: capacity,
+		nextID:   1,
+	}
+
+	if err := queue.load(); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+func (q *RetryQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+
+	return len(q.entries)
+}
+
+// HasKey reports whether some message with the key is waiting for retry. Messages without key are never blocked.
+func (q *RetryQueue) HasKey(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	for _, entry := range q.entries {
+		if bytes.Equal(entry.Message.Key, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Push appends entries to the queue, either all of them or none if the capacity is exceeded.
+func (q *RetryQueue) Push(entries []RetryEntry) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if len(q.entries)+len(entries) > q.capacity {
+		return fmt.Errorf("retry queue is full")
+	}
+
+	size, nextID := len(q.entries), q.nextID
+	for _, entry := range entries {
+		entry.ID = q.nextID
+		q.nextID++
+		q.entries = append(q.entries, entry)
+	}
+
+	// Entries which are not persisted must not be reported as queued
+	if err := q.persist(); err != nil {
+		q.entries, q.nextID = q.entries[:size], nextID
+		return err
+	}
+
+	return nil
+}
+
+// Due returns copies of entries ready for the next attempt. Entry is not returned if some earlier entry
+// with the same key is not ready yet, so messages of the same entity are retried in order.
+func (q *RetryQueue) Due(now time.Time) []RetryEntry {
+	q.Lock()
+	defer q.Unlock()
+
+	blocked := make(map[string]struct{})
+	var due []RetryEntry
+
+	for _, entry := range q.entries {
+		key := string(entry.Message.Key)
+		if _, ok := blocked[key]; ok && len(key) > 0 {
+			continue
+		}
+
+		if entry.NextAttemptAt.After(now) {
+			blocked[key] = struct{}{}
+			continue
+		}
+
+		due = append(due, entry)
+	}
+
+	return due
+}
+
+// Apply removes delivered entries and replaces updated ones keeping their position in the queue.
+func (q *RetryQueue) Apply(removed []uint64, updated []RetryEntry) error {
+	q.Lock()
+	defer q.Unlock()
+
+	removedIDs := make(map[uint64]struct{}, len(removed))
+	for _, id := range removed {
+		removedIDs[id] = struct{}{}
+	}
+
+	updatedEntries := make(map[uint64]RetryEntry, len(updated))
+	for _, entry := range updated {
+		updatedEntries[entry.ID] = entry
+	}
+
+	entries := q.entries[:0]
+	for _, entry := range q.entries {
+		if _, ok := removedIDs[entry.ID]; ok {
+			continue
+		}
+
+		if updatedEntry, ok := updatedEntries[entry.ID]; ok {
+			entry = updatedEntry
+		}
+
+		entries = append(entries, entry)
+	}
+	q.entries = entries
+
+	return q.persist()
+}
+
+func (q *RetryQueue) load() error {
+	if len(q.path) == 0 {
+		return nil
+	}
+
+	file, err := os.Open(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Messages are stored base64-encoded, so lines may be much longer than the default limit
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var entry RetryEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("can't parse retry queue file %s: %w", q.path, err)
+		}
+
+		q.entries = append(q.entries, entry)
+		if entry.ID >= q.nextID {
+			q.nextID = entry.ID + 1
+		}
+	}
+
+	return scanner.Err()
+}
+
+// persist rewrites the file through the temporary one, so the queue is never left half-written
+func (q *RetryQueue) persist() error {
+	if len(q.path) == 0 {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, entry := range q.entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	tmp := q.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buffer.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, q.path)
+}
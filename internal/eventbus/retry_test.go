@@ -0,0 +1,218 @@
+package eventbus
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
+)
+
+// flakyPublisher fails while failing is set and records delivered message values
+type flakyPublisher struct {
+	sync.Mutex
+	failing   bool
+	delivered []string
+	closed    bool
+}
+
+func (p *flakyPublisher) Publish(message Message) error {
+	return p.PublishBatch([]Message{message})
+}
+
+func (p *flakyPublisher) PublishBatch(messages []Message) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.failing {
+		return fmt.Errorf("broker is down")
+	}
+
+	for _, message := range messages {
+		p.delivered = append(p.delivered, string(message.Value))
+	}
+	return nil
+}
+
+func (p *flakyPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func (p *flakyPublisher) setFailing(failing bool) {
+	p.Lock()
+	defer p.Unlock()
+	p.failing = failing
+}
+
+// newTestRetryingPublisher disables the background loop by the long interval, tests call retryDue directly
+func newTestRetryingPublisher(t *testing.T, publisher, deadLetter Publisher, maxAttempts int) (*RetryingPublisher, *RetryQueue) {
+	queue, err := NewRetryQueue("", 0)
+	require.NoError(t, err, "Queue should be created")
+
+	config := RetryConfig{MaxAttempts: maxAttempts, Interval: time.Hour, MaxInterval: time.Hour}
+	return NewRetryingPublisher(publisher, deadLetter, queue, config, nil), queue
+}
+
+func TestRetryingPublisher_WhenPublishFails_ShouldQueueMessageAndRetryLater(t *testing.T) {
+	publisher := &flakyPublisher{failing: true}
+	retrying, queue := newTestRetryingPublisher(t, publisher, nil, 3)
+	defer retrying.Close()
+
+	require.NoError(t, retrying.Publish(Message{Key: []byte("1"), Value: []byte("created")}), "Queued message should not be reported as failed")
+	require.Equal(t, 1, queue.Len(), "Message should be queued")
+
+	publisher.setFailing(false)
+	retrying.retryDue(time.Now().Add(2 * time.Hour))
+
+	assert.Equal(t, []string{"created"}, publisher.delivered, "Message should be delivered by retry")
+	assert.Equal(t, 0, queue.Len(), "Delivered message should be removed from the queue")
+}
+
+func TestRetryingPublisher_WhenKeyIsQueued_ShouldQueueNextMessagesWithSameKey(t *testing.T) {
+	publisher := &flakyPublisher{failing: true}
+	retrying, queue := newTestRetryingPublisher(t, publisher, nil, 3)
+	defer retrying.Close()
+
+	require.NoError(t, retrying.Publish(Message{Key: []byte("1"), Value: []byte("created")}))
+	publisher.setFailing(false)
+	require.NoError(t, retrying.Publish(Message{Key: []byte("1"), Value: []byte("updated")}))
+	require.NoError(t, retrying.Publish(Message{Key: []byte("2"), Value: []byte("other")}))
+
+	assert.Equal(t, []string{"other"}, publisher.delivered, "Message of the other entity should not wait")
+	assert.Equal(t, 2, queue.Len(), "Message should wait for the earlier one with the same key")
+
+	retrying.retryDue(time.Now().Add(2 * time.Hour))
+
+	assert.Equal(t, []string{"other", "created", "updated"}, publisher.delivered, "Messages should be retried in order")
+}
+
+func TestRetryingPublisher_WhenAttemptsAreExhausted_ShouldSendMessageToDeadLetter(t *testing.T) {
+	publisher := &flakyPublisher{failing: true}
+	deadLetter := NewMemoryBroker()
+	subscription := deadLetter.Subscribe(1)
+	retrying, queue := newTestRetryingPublisher(t, publisher, deadLetter, 2)
+	defer retrying.Close()
+
+	require.NoError(t, retrying.Publish(Message{Key: []byte("1"), Value: []byte("created"), Headers: map[string]string{"event-type": "created"}}))
+	retrying.retryDue(time.Now().Add(2 * time.Hour))
+
+	require.Equal(t, 0, queue.Len(), "Dead-lettered message should be removed from the queue")
+	message := <-subscription.Messages()
+	assert.Equal(t, "created", string(message.Value))
+	assert.Equal(t, "created", message.Headers["event-type"], "Original headers should be kept")
+	assert.Equal(t, "broker is down", message.Headers[DeadLetterErrorHeader])
+	assert.Equal(t, "2", message.Headers[DeadLetterAttemptsHeader])
+	assert.Contains(t, message.Headers, DeadLetterFirstFailedAtHeader)
+
+	assert.Equal(t, map[string]string{"event-type": "created"}, StripDeadLetterHeaders(message).Headers,
+		"Stripped message should have original headers only")
+}
+
+// newTestAsyncRetryingPublisher wraps the asynchronous producer on the sarama mock the way the service does
+func newTestAsyncRetryingPublisher(t *testing.T, deadLetter Publisher, maxAttempts int) (*RetryingPublisher, *RetryQueue, *mocks.AsyncProducer) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	mock := mocks.NewAsyncProducer(t, config)
+
+	relay := NewDeliveryRelay()
+	producer := kafka.WrapAsyncProducer("services", mock, relay.OnDelivery, nil)
+
+	queue, err := NewRetryQueue("", 0)
+	require.NoError(t, err, "Queue should be created")
+
+	retryConfig := RetryConfig{MaxAttempts: maxAttempts, Interval: time.Hour, MaxInterval: time.Hour, AsyncDelivery: true}
+	retrying := NewRetryingPublisher(NewKafkaPublisher(producer), deadLetter, queue, retryConfig, nil)
+	relay.Bind(retrying.HandleDelivery)
+
+	return retrying, queue, mock
+}
+
+func TestRetryingPublisher_WhenAsyncDeliveryFails_ShouldQueueMessage(t *testing.T) {
+	retrying, queue, mock := newTestAsyncRetryingPublisher(t, nil, 3)
+	defer retrying.Close()
+	mock.ExpectInputAndFail(fmt.Errorf("broker is down"))
+
+	require.NoError(t, retrying.Publish(Message{Key: []byte("1"), Value: []byte("created")}), "Send should succeed before delivery")
+
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, time.Second, time.Millisecond, "Undelivered message should be queued")
+	due := queue.Due(time.Now().Add(2 * time.Hour))
+	require.Len(t, due, 1)
+	assert.Equal(t, "created", string(due[0].Message.Value))
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "broker is down", due[0].LastError)
+}
+
+func TestRetryingPublisher_WhenAsyncRetriesFail_ShouldSendMessageToDeadLetter(t *testing.T) {
+	deadLetter := NewMemoryBroker()
+	subscription := deadLetter.Subscribe(1)
+	retrying, queue, mock := newTestAsyncRetryingPublisher(t, deadLetter, 2)
+	defer retrying.Close()
+	mock.ExpectInputAndFail(fmt.Errorf("broker is down"))
+	mock.ExpectInputAndFail(fmt.Errorf("broker is still down"))
+
+	require.NoError(t, retrying.Publish(Message{Key: []byte("1"), Value: []byte("created")}))
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, time.Second, time.Millisecond, "Undelivered message should be queued")
+
+	retrying.retryDue(time.Now().Add(2 * time.Hour))
+
+	select {
+	case message := <-subscription.Messages():
+		assert.Equal(t, "created", string(message.Value))
+		assert.Equal(t, "broker is still down", message.Headers[DeadLetterErrorHeader])
+		assert.Equal(t, "2", message.Headers[DeadLetterAttemptsHeader], "Attempts of the retry should be continued")
+	case <-time.After(time.Second):
+		t.Fatal("Message should be sent to the dead-letter publisher")
+	}
+	assert.Equal(t, 0, queue.Len(), "Dead-lettered message should not be queued")
+}
+
+func TestRetryingPublisher_WhenClosed_ShouldCloseUnderlyingPublishers(t *testing.T) {
+	publisher := &flakyPublisher{}
+	deadLetter := &flakyPublisher{}
+	retrying, _ := newTestRetryingPublisher(t, publisher, deadLetter, 1)
+
+	require.NoError(t, retrying.Close())
+
+	assert.True(t, publisher.closed, "Publisher should be closed")
+	assert.True(t, deadLetter.closed, "Dead-letter publisher should be closed")
+}
+
+func TestRetryQueue_WhenPathIsSet_ShouldRestoreEntriesAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "retry.jsonl")
+	queue, err := NewRetryQueue(path, 0)
+	require.NoError(t, err, "Queue should be created")
+
+	require.NoError(t, queue.Push([]RetryEntry{
+		{Message: Message{Key: []byte("1"), Value: []byte("first")}},
+		{Message: Message{Key: []byte("2"), Value: []byte("second")}},
+	}))
+	require.NoError(t, queue.Apply([]uint64{1}, nil))
+
+	restored, err := NewRetryQueue(path, 0)
+	require.NoError(t, err, "Queue should be restored")
+	require.NoError(t, restored.Push([]RetryEntry{{Message: Message{Value: []byte("third")}}}))
+
+	due := restored.Due(time.Now())
+	require.Len(t, due, 2, "Persisted and new entries should be in the queue")
+	assert.Equal(t, "second", string(due[0].Message.Value))
+	assert.Equal(t, uint64(3), due[1].ID, "IDs should not be reused after restart")
+}
+
+func TestRetryQueue_WhenCapacityIsExceeded_ShouldRejectEntries(t *testing.T) {
+	queue, _ := NewRetryQueue("", 1)
+
+	err := queue.Push([]RetryEntry{{}, {}})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "retry queue is full", err.Error(), "Incorrect error message")
+	assert.Equal(t, 0, queue.Len(), "No entries should be queued")
+}
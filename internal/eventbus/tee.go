@@ -0,0 +1,51 @@
+package eventbus
+
+import "github.com/rs/zerolog/log"
+
+// Tee publishes messages to the primary publisher and copies successfully published ones to the secondary.
+// Failures of the secondary publisher are logged and not returned, it must not fail the primary flow.
+type Tee struct {
+	primary   Publisher
+	secondary Publisher
+}
+
+func NewTee(primary Publisher, secondary Publisher) *Tee {
+	return &Tee{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+func (t *Tee) Publish(message Message) error {
+	if err := t.primary.Publish(message); err != nil {
+		return err
+	}
+
+	if err := t.secondary.Publish(message); err != nil {
+		log.Err(err).Msg("Failed to copy message to the secondary publisher")
+	}
+
+	return nil
+}
+
+func (t *Tee) PublishBatch(messages []Message) error {
+	if err := t.primary.PublishBatch(messages); err != nil {
+		return err
+	}
+
+	if err := t.secondary.PublishBatch(messages); err != nil {
+		log.Err(err).Msg("Failed to copy messages to the secondary publisher")
+	}
+
+	return nil
+}
+
+func (t *Tee) Close() error {
+	err := t.primary.Close()
+
+	if secondaryErr := t.secondary.Close(); secondaryErr != nil && err == nil {
+		err = secondaryErr
+	}
+
+	return err
+}
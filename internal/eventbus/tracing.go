@@ -0,0 +1,22 @@
+package eventbus
+
+import (
+	"github.com/opentracing/opentracing-go"
+)
+
+// InjectSpanContext writes span context to the message headers, so consumers are able to continue the trace.
+// The tracer must be the one which started the span.
+func InjectSpanContext(tracer opentracing.Tracer, spanContext opentracing.SpanContext, message *Message) error {
+	if message.Headers == nil {
+		message.Headers = make(map[string]string)
+	}
+
+	return tracer.Inject(spanContext, opentracing.TextMap, opentracing.TextMapCarrier(message.Headers))
+}
+
+// ExtractSpanContext reads span context injected by the producer with the same kind of tracer. The result should be
+// used as a parent (opentracing.FollowsFrom) of the consumer span started by the tracer.
+// opentracing.ErrSpanContextNotFound is returned if there is no context.
+func ExtractSpanContext(tracer opentracing.Tracer, message Message) (opentracing.SpanContext, error) {
+	return tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(message.Headers))
+}
@@ -0,0 +1,35 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractSpanContext_WhenContextIsInjected_ShouldContinueTrace(t *testing.T) {
+	// The tracer is not the global one, so it has to be passed to both sides
+	tracer := mocktracer.New()
+
+	span := tracer.StartSpan("PublishEvents")
+	message := Message{Value: []byte("value")}
+
+	require.NoError(t, InjectSpanContext(tracer, span.Context(), &message), "Span context should be injected")
+	assert.NotEmpty(t, message.Headers, "Span context should be written to headers")
+
+	spanContext, err := ExtractSpanContext(tracer, message)
+	require.NoError(t, err, "Span context should be extracted")
+
+	expected := span.Context().(mocktracer.MockSpanContext)
+	got := spanContext.(mocktracer.MockSpanContext)
+	assert.Equal(t, expected.TraceID, got.TraceID, "Trace ID should be preserved")
+	assert.Equal(t, expected.SpanID, got.SpanID, "Span ID should be preserved")
+}
+
+func TestExtractSpanContext_WhenNoContext_ShouldReturnNotFoundError(t *testing.T) {
+	_, err := ExtractSpanContext(mocktracer.New(), Message{Value: []byte("value")})
+
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+}
@@ -0,0 +1,103 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// WriterSink is the Publisher writing every message as a single JSON line. JSON payloads are embedded as is
+// to keep the output readable, any other payload (e.g. protobuf) is stored base64-encoded.
+type WriterSink struct {
+	sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+type record struct {
+	Key         string            `json:"key,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Value       json.RawMessage   `json:"value,omitempty"`
+	ValueBase64 []byte            `json:"value_base64,omitempty"`
+}
+
+// NewStdoutSink writes messages to the standard output, the stream is not closed on Close.
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout, nil)
+}
+
+// NewFileSink appends messages to the JSONL file, the file is created if it doesn't exist.
+func NewFileSink(path string) (*WriterSink, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("file path is required for the file sink")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterSink(file, file), nil
+}
+
+// NewWriterSink writes messages to the writer. Closer is optional and is called on Close.
+func NewWriterSink(writer io.Writer, closer io.Closer) *WriterSink {
+	return &WriterSink{
+		writer: writer,
+		closer: closer,
+	}
+}
+
+func (s *WriterSink) Publish(message Message) error {
+	return s.PublishBatch([]Message{message})
+}
+
+func (s *WriterSink) PublishBatch(messages []Message) error {
+	if err := validateMessages(messages); err != nil {
+		return err
+	}
+
+	// Encode the whole batch first to write either all messages or nothing
+	var builder strings.Builder
+	for _, message := range messages {
+		line, err := json.Marshal(newRecord(message))
+		if err != nil {
+			return err
+		}
+
+		builder.Write(line)
+		builder.WriteByte('\n')
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	_, err := io.WriteString(s.writer, builder.String())
+	return err
+}
+
+func (s *WriterSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+func newRecord(message Message) record {
+	rec := record{
+		Key:     string(message.Key),
+		Headers: message.Headers,
+	}
+
+	if strings.HasPrefix(message.Headers[ContentTypeHeader], "application/json") && json.Valid(message.Value) {
+		rec.Value = message.Value
+	} else {
+		rec.ValueBase64 = message.Value
+	}
+
+	return rec
+}
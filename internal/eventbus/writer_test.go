@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterSink_WhenMessagesArePublished_ShouldWriteJSONLines(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := NewWriterSink(&buffer, nil)
+
+	err := sink.PublishBatch([]Message{
+		{Key: []byte("1"), Value: []byte(`{"a":1}`), Headers: map[string]string{ContentTypeHeader: "application/json"}},
+		{Key: []byte("2"), Value: []byte{0x0a, 0x01}, Headers: map[string]string{ContentTypeHeader: "application/x-protobuf"}},
+	})
+
+	require.NoError(t, err, "No error should be returned")
+	expected := `{"key":"1","headers":{"content-type":"application/json"},"value":{"a":1}}` + "\n" +
+		`{"key":"2","headers":{"content-type":"application/x-protobuf"},"value_base64":"CgE="}` + "\n"
+	assert.Equal(t, expected, buffer.String(), "JSON payload should be embedded, binary payload should be base64-encoded")
+}
+
+func TestFileSink_WhenMessagesArePublished_ShouldAppendThemToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err, "File sink should be created")
+		require.NoError(t, sink.Publish(Message{Value: []byte(`"event"`), Headers: map[string]string{ContentTypeHeader: "application/json"}}))
+		require.NoError(t, sink.Close(), "File should be closed")
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "File should be readable")
+	assert.Equal(t, "{\"headers\":{\"content-type\":\"application/json\"},\"value\":\"event\"}\n"+
+		"{\"headers\":{\"content-type\":\"application/json\"},\"value\":\"event\"}\n", string(data))
+}
+
+func TestNewFileSink_WhenPathIsEmpty_ShouldReturnError(t *testing.T) {
+	_, err := NewFileSink("")
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "file path is required for the file sink", err.Error(), "Incorrect error message")
+}
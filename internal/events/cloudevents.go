@@ -0,0 +1,89 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+)
+
+// DefaultCloudEventsSource is used when no source is configured.
+const DefaultCloudEventsSource = "/ova-service-api"
+
+// CloudEvents attribute headers as defined by the Kafka protocol binding (binary content mode).
+// The "datacontenttype" attribute is mapped to the regular content-type header.
+const (
+	CloudEventsSpecVersion = "1.0"
+
+	CloudEventsSpecVersionHeader = "ce_specversion"
+	CloudEventsIDHeader          = "ce_id"
+	CloudEventsSourceHeader      = "ce_source"
+	CloudEventsTypeHeader        = "ce_type"
+	CloudEventsSubjectHeader     = "ce_subject"
+	CloudEventsTimeHeader        = "ce_time"
+	// CloudEventsReplayHeader is the extension attribute set for replayed events
+	CloudEventsReplayHeader = "ce_replay"
+)
+
+var cloudEventsTypes = map[EventType]string{
+	Create: "ova.service.created",
+	Update: "ova.service.updated",
+	Delete: "ova.service.deleted",
+}
+
+// CloudEventsType returns the CloudEvents "type" attribute for the event type.
+func CloudEventsType(eventType EventType) (string, error) {
+	ceType, ok := cloudEventsTypes[eventType]
+	if !ok {
+		return "", fmt.Errorf("event type %s has no CloudEvents type", eventType.String())
+	}
+
+	return ceType, nil
+}
+
+// cloudEventsEncoder wraps payload produced by the data encoder into CloudEvents 1.0 message.
+// The payload is kept as is, so data content type is the one set by the data encoder.
+type cloudEventsEncoder struct {
+	source      string
+	dataEncoder Encoder
+}
+
+func newCloudEventsEncoder(source string, dataEncoder Encoder) cloudEventsEncoder {
+	if len(source) == 0 {
+		source = DefaultCloudEventsSource
+	}
+
+	return cloudEventsEncoder{
+		source:      source,
+		dataEncoder: dataEncoder,
+	}
+}
+
+func (e cloudEventsEncoder) Encode(event ServiceCUDEvent) (eventbus.Message, error) {
+	ceType, err := CloudEventsType(event.EventType)
+	if err != nil {
+		return eventbus.Message{}, err
+	}
+
+	message, err := e.dataEncoder.Encode(event)
+	if err != nil {
+		return eventbus.Message{}, err
+	}
+
+	if message.Headers == nil {
+		message.Headers = make(map[string]string)
+	}
+
+	message.Headers[CloudEventsSpecVersionHeader] = CloudEventsSpecVersion
+	message.Headers[CloudEventsIDHeader] = event.EventID.String()
+	message.Headers[CloudEventsSourceHeader] = e.source
+	message.Headers[CloudEventsTypeHeader] = ceType
+	message.Headers[CloudEventsSubjectHeader] = event.ServiceID.String()
+	message.Headers[CloudEventsTimeHeader] = event.Timestamp.UTC().Format(time.RFC3339Nano)
+
+	if event.Replay {
+		message.Headers[CloudEventsReplayHeader] = "true"
+	}
+
+	return message, nil
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudEventsEncoder_WhenValidEvent_ShouldSetAttributeHeaders(t *testing.T) {
+	encoder, err := NewEncoder(EncoderConfig{Format: FormatJSON, Envelope: EnvelopeCloudEvents, Source: "/test"})
+	require.NoError(t, err, "No error should be returned for known envelope")
+
+	event := NewServiceCreateEvent(serviceID, userID)
+	message, err := encoder.Encode(event)
+	require.NoError(t, err, "No error should be returned for valid event")
+
+	expected := map[string]string{
+		CloudEventsSpecVersionHeader: "1.0",
+		CloudEventsIDHeader:          event.EventID.String(),
+		CloudEventsSourceHeader:      "/test",
+		CloudEventsTypeHeader:        "ova.service.created",
+		CloudEventsSubjectHeader:     serviceID.String(),
+		CloudEventsTimeHeader:        event.Timestamp.Format(time.RFC3339Nano),
+		ContentTypeHeader:            JSONContentType,
+		EventTypeHeader:              "SERVICE_EVENT_TYPE_CREATED",
+		SchemaVersionHeader:          SchemaVersion,
+	}
+	assert.Equal(t, expected, message.Headers, "CloudEvents attributes should be passed in headers")
+	assert.Contains(t, string(message.Value), serviceID.String(), "Payload should be encoded by the data encoder")
+}
+
+func TestCloudEventsEncoder_WhenSourceIsEmpty_ShouldUseDefaultSource(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{Envelope: EnvelopeCloudEvents})
+
+	message, err := encoder.Encode(NewServiceDeleteEvent(serviceID, userID))
+
+	require.NoError(t, err, "No error should be returned for valid event")
+	assert.Equal(t, DefaultCloudEventsSource, message.Headers[CloudEventsSourceHeader])
+	assert.Equal(t, "ova.service.deleted", message.Headers[CloudEventsTypeHeader])
+	assert.Equal(t, ProtobufContentType, message.Headers[ContentTypeHeader])
+}
+
+func TestCloudEventsEncoder_WhenUnknownEventType_ShouldReturnError(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{Envelope: EnvelopeCloudEvents})
+	event := NewServiceCreateEvent(serviceID, userID)
+	event.EventType = 0
+
+	_, err := encoder.Encode(event)
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "event type SERVICE_EVENT_TYPE_UNSPECIFIED has no CloudEvents type", err.Error())
+}
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+)
+
+// Supported event formats
+const (
+	FormatProtobuf = "protobuf"
+	FormatJSON     = "json"
+)
+
+const (
+	ContentTypeHeader   = eventbus.ContentTypeHeader
+	ProtobufContentType = "application/x-protobuf"
+	JSONContentType     = "application/json"
+
+	EventTypeHeader     = "event-type"
+	SchemaVersionHeader = "schema-version"
+	// SchemaVersion is the version of the payload schema, see ServiceCUDEventV1 in api/ova-service-api/events.proto
+	SchemaVersion = "1"
+	// ReplayHeader is set to "true" for replayed events, so consumers can filter them without decoding the payload
+	ReplayHeader = "replay"
+)
+
+// Encoder serializes ServiceCUDEvent to the event bus message. Content type of the payload is passed in the message headers,
+// so consumers are able to choose the proper decoder.
+type Encoder interface {
+	Encode(event ServiceCUDEvent) (eventbus.Message, error)
+}
+
+// Supported event envelopes
+const (
+	EnvelopeNative      = "native"
+	EnvelopeCloudEvents = "cloudevents"
+)
+
+// Supported partition keys. Events with the same key are produced to the same partition and thus consumed in order.
+const (
+	KeyByServiceID = "service_id"
+	KeyByUserID    = "user_id"
+)
+
+// EncoderConfig describes how events are serialized. Empty values fall back to protobuf payload in the native envelope.
+type EncoderConfig struct {
+	// Format of the event payload
+	Format string
+	// Envelope wrapping the payload
+	Envelope string
+	// Source is the CloudEvents "source" attribute, used only with the CloudEvents envelope
+	Source string
+	// KeyBy selects the event attribute used as the message key, service ID by default
+	KeyBy string
+}
+
+// NewEncoder returns the encoder for the given configuration.
+func NewEncoder(config EncoderConfig) (Encoder, error) {
+	var dataEncoder Encoder
+
+	switch config.Format {
+	case "", FormatProtobuf:
+		dataEncoder = protobufEncoder{}
+	case FormatJSON:
+		dataEncoder = jsonEncoder{}
+	default:
+		return nil, fmt.Errorf("unknown event format: %q", config.Format)
+	}
+
+	var keyFunc func(event ServiceCUDEvent) []byte
+
+	switch config.KeyBy {
+	case "", KeyByServiceID:
+		keyFunc = serviceIDKey
+	case KeyByUserID:
+		keyFunc = userIDKey
+	default:
+		return nil, fmt.Errorf("unknown partition key: %q", config.KeyBy)
+	}
+
+	switch config.Envelope {
+	case "", EnvelopeNative:
+		return keyedEncoder{encoder: dataEncoder, keyFunc: keyFunc}, nil
+	case EnvelopeCloudEvents:
+		return keyedEncoder{encoder: newCloudEventsEncoder(config.Source, dataEncoder), keyFunc: keyFunc}, nil
+	default:
+		return nil, fmt.Errorf("unknown event envelope: %q", config.Envelope)
+	}
+}
+
+// EncodeAll encodes events one by one and stops on the first error.
+func EncodeAll(encoder Encoder, events []ServiceCUDEvent) ([]eventbus.Message, error) {
+	messages := make([]eventbus.Message, len(events))
+
+	for i, event := range events {
+		message, err := encoder.Encode(event)
+		if err != nil {
+			return nil, err
+		}
+
+		messages[i] = message
+	}
+
+	return messages, nil
+}
+
+// keyedEncoder sets the message key, so all events of the same entity land in the same partition.
+type keyedEncoder struct {
+	encoder Encoder
+	keyFunc func(event ServiceCUDEvent) []byte
+}
+
+func (e keyedEncoder) Encode(event ServiceCUDEvent) (eventbus.Message, error) {
+	message, err := e.encoder.Encode(event)
+	if err != nil {
+		return eventbus.Message{}, err
+	}
+
+	message.Key = e.keyFunc(event)
+	return message, nil
+}
+
+func serviceIDKey(event ServiceCUDEvent) []byte {
+	return []byte(event.ServiceID.String())
+}
+
+func userIDKey(event ServiceCUDEvent) []byte {
+	return []byte(strconv.FormatUint(event.UserID, 10))
+}
+
+type protobufEncoder struct{}
+
+func (protobufEncoder) Encode(event ServiceCUDEvent) (eventbus.Message, error) {
+	value, err := proto.Marshal(event.ToProto())
+	if err != nil {
+		return eventbus.Message{}, err
+	}
+
+	return newMessage(event, value, ProtobufContentType), nil
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(event ServiceCUDEvent) (eventbus.Message, error) {
+	value, err := protojson.Marshal(event.ToProto())
+	if err != nil {
+		return eventbus.Message{}, err
+	}
+
+	return newMessage(event, value, JSONContentType), nil
+}
+
+func newMessage(event ServiceCUDEvent, value []byte, contentType string) eventbus.Message {
+	headers := map[string]string{
+		ContentTypeHeader:   contentType,
+		EventTypeHeader:     event.EventType.String(),
+		SchemaVersionHeader: SchemaVersion,
+	}
+
+	if event.Replay {
+		headers[ReplayHeader] = "true"
+	}
+
+	return eventbus.Message{
+		Value:   value,
+		Headers: headers,
+	}
+}
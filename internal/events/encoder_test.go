@@ -0,0 +1,124 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+var (
+	serviceID = uuid.MustParse("d6fa505c-6072-4a45-bdae-86e6b13d7342")
+	userID    = uint64(42)
+)
+
+func TestNewEncoder_WhenUnknownFormat_ShouldReturnError(t *testing.T) {
+	_, err := NewEncoder(EncoderConfig{Format: "xml"})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "unknown event format: \"xml\"", err.Error(), "Incorrect error message")
+}
+
+func TestNewEncoder_WhenUnknownEnvelope_ShouldReturnError(t *testing.T) {
+	_, err := NewEncoder(EncoderConfig{Envelope: "avro"})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "unknown event envelope: \"avro\"", err.Error(), "Incorrect error message")
+}
+
+func TestProtobufEncoder_WhenValidEvent_ShouldEncodeToProtobuf(t *testing.T) {
+	encoder, err := NewEncoder(EncoderConfig{})
+	require.NoError(t, err, "Empty format should fall back to protobuf")
+
+	event := NewServiceUpdateEvent(serviceID, userID)
+	message, err := encoder.Encode(event)
+	require.NoError(t, err, "No error should be returned for valid event")
+	assert.Equal(t, ProtobufContentType, message.Headers[ContentTypeHeader], "Content type header should be set")
+	assert.Equal(t, "SERVICE_EVENT_TYPE_UPDATED", message.Headers[EventTypeHeader], "Event type header should be set")
+	assert.Equal(t, SchemaVersion, message.Headers[SchemaVersionHeader], "Schema version header should be set")
+
+	var got pb.ServiceCUDEventV1
+	require.NoError(t, proto.Unmarshal(message.Value, &got), "Message should contain protobuf payload")
+	assert.Equal(t, event.EventID.String(), got.EventId)
+	assert.Equal(t, pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED, got.EventType)
+	assert.Equal(t, serviceID.String(), got.ServiceId)
+	assert.Equal(t, userID, got.UserId)
+	assert.True(t, event.Timestamp.Equal(got.Timestamp.AsTime()), "Timestamp should be preserved")
+}
+
+func TestJSONEncoder_WhenValidEvent_ShouldEncodeEventTypeAsEnumName(t *testing.T) {
+	encoder, err := NewEncoder(EncoderConfig{Format: FormatJSON})
+	require.NoError(t, err, "No error should be returned for known format")
+
+	message, err := encoder.Encode(NewServiceDeleteEvent(serviceID, userID))
+	require.NoError(t, err, "No error should be returned for valid event")
+	assert.Equal(t, JSONContentType, message.Headers[ContentTypeHeader], "Content type header should be set")
+	assert.Contains(t, string(message.Value), `"SERVICE_EVENT_TYPE_DELETED"`, "Event type should be encoded as enum name")
+
+	var got pb.ServiceCUDEventV1
+	require.NoError(t, protojson.Unmarshal(message.Value, &got), "Message should contain JSON payload")
+	assert.Equal(t, serviceID.String(), got.ServiceId)
+}
+
+func TestEncodeAll_WhenValidEvents_ShouldEncodeEveryEvent(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{Format: FormatProtobuf})
+	events := []ServiceCUDEvent{NewServiceCreateEvent(serviceID, userID), NewServiceCreateEvent(serviceID, userID)}
+
+	messages, err := EncodeAll(encoder, events)
+
+	require.NoError(t, err, "No error should be returned for valid events")
+	assert.Len(t, messages, 2, "Every event should be encoded")
+}
+
+func TestNewEncoder_WhenUnknownPartitionKey_ShouldReturnError(t *testing.T) {
+	_, err := NewEncoder(EncoderConfig{KeyBy: "email"})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "unknown partition key: \"email\"", err.Error(), "Incorrect error message")
+}
+
+func TestEncoder_WhenKeyByIsEmpty_ShouldUseServiceIDAsKey(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{Envelope: EnvelopeCloudEvents})
+
+	message, err := encoder.Encode(NewServiceCreateEvent(serviceID, userID))
+
+	require.NoError(t, err, "No error should be returned for valid event")
+	assert.Equal(t, serviceID.String(), string(message.Key), "Service ID should be used as key")
+}
+
+func TestEncoder_WhenKeyByUserID_ShouldUseUserIDAsKey(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{KeyBy: KeyByUserID})
+
+	message, err := encoder.Encode(NewServiceUpdateEvent(serviceID, userID))
+
+	require.NoError(t, err, "No error should be returned for valid event")
+	assert.Equal(t, "42", string(message.Key), "User ID should be used as key")
+}
+
+func TestEncoder_WhenReplayEvent_ShouldMarkPayloadAndHeaders(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{Envelope: EnvelopeCloudEvents})
+
+	message, err := encoder.Encode(NewServiceReplayEvent(serviceID, userID))
+	require.NoError(t, err, "No error should be returned for valid event")
+
+	var got pb.ServiceCUDEventV1
+	require.NoError(t, proto.Unmarshal(message.Value, &got), "Message should contain protobuf payload")
+	assert.True(t, got.Replay, "Replay flag should be set in payload")
+	assert.Equal(t, pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED, got.EventType, "Replayed event should be create event")
+	assert.Equal(t, "true", message.Headers[ReplayHeader], "Replay header should be set")
+	assert.Equal(t, "true", message.Headers[CloudEventsReplayHeader], "Replay extension should be set")
+}
+
+func TestEncoder_WhenLiveEvent_ShouldNotSetReplayHeader(t *testing.T) {
+	encoder, _ := NewEncoder(EncoderConfig{})
+
+	message, err := encoder.Encode(NewServiceCreateEvent(serviceID, userID))
+
+	require.NoError(t, err, "No error should be returned for valid event")
+	assert.NotContains(t, message.Headers, ReplayHeader, "Replay header should be absent for live events")
+}
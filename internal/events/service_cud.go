@@ -1,43 +1,70 @@
 package events
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
 )
 
+type EventType = pb.ServiceEventTypeV1
+
 // Event types
 const (
-	Create = iota
-	Update
-	Delete
+	Create = pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED
+	Update = pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED
+	Delete = pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_DELETED
 )
 
 // ServiceCUDEvent should be more complicated in real life, e.g. should contain full created service entity,
 // but we will only provide serviceID to keep it simple for now.
 type ServiceCUDEvent struct {
 	EventID   uuid.UUID
-	EventType int
+	EventType EventType
 	ServiceID uuid.UUID
+	UserID    uint64
 	Timestamp time.Time
+	// Replay is set for events re-emitted from the database, consumers may skip side effects like notifications
+	Replay bool
+}
+
+func NewServiceCreateEvent(serviceID uuid.UUID, userID uint64) ServiceCUDEvent {
+	return newServiceCUDEvent(Create, serviceID, userID)
 }
 
-func NewServiceCreateEvent(serviceID uuid.UUID) ServiceCUDEvent {
-	return newServiceCUDEvent(Create, serviceID)
+func NewServiceUpdateEvent(serviceID uuid.UUID, userID uint64) ServiceCUDEvent {
+	return newServiceCUDEvent(Update, serviceID, userID)
 }
 
-func NewServiceUpdateEvent(serviceID uuid.UUID) ServiceCUDEvent {
-	return newServiceCUDEvent(Update, serviceID)
+func NewServiceDeleteEvent(serviceID uuid.UUID, userID uint64) ServiceCUDEvent {
+	return newServiceCUDEvent(Delete, serviceID, userID)
 }
 
-func NewServiceDeleteEvent(serviceID uuid.UUID) ServiceCUDEvent {
-	return newServiceCUDEvent(Delete, serviceID)
+// NewServiceReplayEvent returns the create event for the already stored service.
+func NewServiceReplayEvent(serviceID uuid.UUID, userID uint64) ServiceCUDEvent {
+	event := newServiceCUDEvent(Create, serviceID, userID)
+	event.Replay = true
+	return event
+}
+
+// ToProto maps the event to the versioned wire schema defined in api/ova-service-api/events.proto.
+func (event ServiceCUDEvent) ToProto() *pb.ServiceCUDEventV1 {
+	return &pb.ServiceCUDEventV1{
+		EventId:   event.EventID.String(),
+		EventType: event.EventType,
+		ServiceId: event.ServiceID.String(),
+		UserId:    event.UserID,
+		Timestamp: timestamppb.New(event.Timestamp),
+		Replay:    event.Replay,
+	}
 }
 
 func (event ServiceCUDEvent) String() string {
-	res, err := json.Marshal(event)
+	res, err := protojson.Marshal(event.ToProto())
 
 	// Do not return error to satisfy stringer interface.
 	// Actually we should not have errors on marshalling ServiceCUDEvent structure.
@@ -49,11 +76,12 @@ func (event ServiceCUDEvent) String() string {
 	return string(res)
 }
 
-func newServiceCUDEvent(eventType int, serviceID uuid.UUID) ServiceCUDEvent {
+func newServiceCUDEvent(eventType EventType, serviceID uuid.UUID, userID uint64) ServiceCUDEvent {
 	return ServiceCUDEvent{
 		EventID:   uuid.New(),
 		EventType: eventType,
 		ServiceID: serviceID,
+		UserID:    userID,
 		Timestamp: time.Now().UTC(),
 	}
 }
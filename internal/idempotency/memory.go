@@ -0,0 +1,95 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	fingerprint string
+	// response is nil until the request is completed
+	response  []byte
+	expiresAt time.Time
+}
+
+type memoryKey struct {
+	scope string
+	key   string
+}
+
+// MemoryStore keeps keys of the single instance, use PostgresStore if requests are balanced across instances
+type MemoryStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[memoryKey]*memoryEntry
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[memoryKey]*memoryEntry),
+	}
+}
+
+func (s *MemoryStore) Begin(scope string, key string, fingerprint string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	id := memoryKey{scope: scope, key: key}
+
+	entry, ok := s.entries[id]
+	if !ok || now.After(entry.expiresAt) {
+		s.entries[id] = &memoryEntry{fingerprint: fingerprint, expiresAt: now.Add(s.ttl)}
+		return nil, nil
+	}
+
+	if entry.fingerprint != fingerprint {
+		return nil, ErrConflict
+	}
+	if entry.response == nil {
+		return nil, ErrInProgress
+	}
+
+	return entry.response, nil
+}
+
+func (s *MemoryStore) Complete(scope string, key string, response []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[memoryKey{scope: scope, key: key}]; ok {
+		// Empty response still marks the request as completed
+		entry.response = append(make([]byte, 0, len(response)), response...)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Release(scope string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := memoryKey{scope: scope, key: key}
+	if entry, ok := s.entries[id]; ok && entry.response == nil {
+		delete(s.entries, id)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Purge() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	store := NewMemoryStore(time.Hour)
+	store.now = func() time.Time {
+		return *now
+	}
+	return store
+}
+
+func TestMemoryStore_WhenKeyIsCompleted_ShouldReturnResponse(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	response, err := store.Begin("Create", "key", "fingerprint")
+	require.NoError(t, err, "Key should be claimed")
+	require.Nil(t, response, "Claimed key should have no response")
+	require.NoError(t, store.Complete("Create", "key", []byte("response")))
+
+	response, err = store.Begin("Create", "key", "fingerprint")
+
+	require.NoError(t, err, "Repeated request should succeed")
+	assert.Equal(t, []byte("response"), response, "Stored response should be returned")
+}
+
+func TestMemoryStore_WhenResponseIsEmpty_ShouldReturnNonNilResponse(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	_, _ = store.Begin("Create", "key", "fingerprint")
+	require.NoError(t, store.Complete("Create", "key", nil))
+
+	response, err := store.Begin("Create", "key", "fingerprint")
+
+	require.NoError(t, err, "Repeated request should succeed")
+	assert.NotNil(t, response, "Empty response should be distinguished from the claimed key")
+}
+
+func TestMemoryStore_WhenKeyIsInProgress_ShouldReturnErrInProgress(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	_, _ = store.Begin("Create", "key", "fingerprint")
+	_, err := store.Begin("Create", "key", "fingerprint")
+
+	assert.ErrorIs(t, err, ErrInProgress)
+}
+
+func TestMemoryStore_WhenFingerprintDiffers_ShouldReturnErrConflict(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	_, _ = store.Begin("Create", "key", "fingerprint")
+	_, err := store.Begin("Create", "key", "other")
+
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestMemoryStore_WhenScopesDiffer_ShouldClaimBothKeys(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	_, _ = store.Begin("Create", "key", "fingerprint")
+	_, err := store.Begin("MultiCreate", "key", "other")
+
+	assert.NoError(t, err, "Keys of different scopes should not conflict")
+}
+
+func TestMemoryStore_WhenKeyIsReleased_ShouldClaimItAgain(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	_, _ = store.Begin("Create", "key", "fingerprint")
+	require.NoError(t, store.Release("Create", "key"))
+
+	_, err := store.Begin("Create", "key", "other")
+
+	assert.NoError(t, err, "Released key should be claimed")
+}
+
+func TestMemoryStore_WhenKeyIsExpired_ShouldClaimItAgainAndPurgeIt(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	_, _ = store.Begin("Create", "expired", "fingerprint")
+	require.NoError(t, store.Complete("Create", "expired", []byte("response")))
+	_, _ = store.Begin("Create", "purged", "fingerprint")
+	now = now.Add(2 * time.Hour)
+
+	response, err := store.Begin("Create", "expired", "other")
+	require.NoError(t, err, "Expired key should be claimed")
+	assert.Nil(t, response, "Response of the expired key should not be returned")
+
+	require.NoError(t, store.Purge())
+	assert.Len(t, store.entries, 1, "Expired keys should be purged")
+}
+
+func TestFingerprint_ShouldIgnoreIdempotencyKeys(t *testing.T) {
+	first, err := Fingerprint(&pb.MultiCreateServiceV1Request{
+		CreateService:  []*pb.CreateServiceV1Request{{UserId: 1, IdempotencyKey: "item"}},
+		IdempotencyKey: "first",
+	})
+	require.NoError(t, err)
+	second, err := Fingerprint(&pb.MultiCreateServiceV1Request{
+		CreateService:  []*pb.CreateServiceV1Request{{UserId: 1}},
+		IdempotencyKey: "second",
+	})
+	require.NoError(t, err)
+	other, err := Fingerprint(&pb.MultiCreateServiceV1Request{
+		CreateService: []*pb.CreateServiceV1Request{{UserId: 2}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second, "Keys should not change the fingerprint")
+	assert.NotEqual(t, first, other, "Payload should change the fingerprint")
+}
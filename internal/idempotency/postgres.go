@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/rs/zerolog/log"
+)
+
+// PostgresStore keeps keys in the idempotency_keys table of migrations/00003_idempotency_keys.sql,
+// so they are shared by all instances
+type PostgresStore struct {
+	ctx context.Context
+	db  *sql.DB
+	ttl time.Duration
+}
+
+func NewPostgresStore(ctx context.Context, dsn string, ttl time.Duration) (*PostgresStore, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		log.Err(err).Msg("Can't load pgx driver")
+		return nil, err
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		log.Err(err).Msg("Failed to connect to database")
+		return nil, err
+	}
+
+	return &PostgresStore{
+		ctx: ctx,
+		db:  db,
+		ttl: ttl,
+	}, nil
+}
+
+func (s *PostgresStore) Begin(scope string, key string, fingerprint string) ([]byte, error) {
+	// The expired key is claimed again, the live one is left untouched and nothing is returned
+	query := `INSERT INTO idempotency_keys (scope, key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, now() + $4 * interval '1 millisecond')
+		ON CONFLICT (scope, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, response = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now()
+		RETURNING key`
+
+	var claimed string
+	err := s.db.QueryRowContext(s.ctx, query, scope, key, fingerprint, s.ttl.Milliseconds()).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("Failed to claim idempotency key")
+		return nil, err
+	}
+
+	var (
+		storedFingerprint string
+		response          []byte
+	)
+	err = s.db.QueryRowContext(s.ctx, `SELECT fingerprint, response FROM idempotency_keys WHERE scope = $1 AND key = $2`,
+		scope, key).Scan(&storedFingerprint, &response)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The key is released concurrently
+		return nil, ErrInProgress
+	}
+	if err != nil {
+		log.Err(err).Msg("Failed to load idempotency key")
+		return nil, err
+	}
+
+	if storedFingerprint != fingerprint {
+		return nil, ErrConflict
+	}
+	if response == nil {
+		return nil, ErrInProgress
+	}
+
+	return response, nil
+}
+
+func (s *PostgresStore) Complete(scope string, key string, response []byte) error {
+	if response == nil {
+		response = []byte{}
+	}
+
+	_, err := s.db.ExecContext(s.ctx, `UPDATE idempotency_keys SET response = $3 WHERE scope = $1 AND key = $2`,
+		scope, key, response)
+	if err != nil {
+		log.Err(err).Msg("Failed to store idempotent response")
+	}
+
+	return err
+}
+
+func (s *PostgresStore) Release(scope string, key string) error {
+	_, err := s.db.ExecContext(s.ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND response IS NULL`,
+		scope, key)
+	if err != nil {
+		log.Err(err).Msg("Failed to release idempotency key")
+	}
+
+	return err
+}
+
+func (s *PostgresStore) Purge() error {
+	_, err := s.db.ExecContext(s.ctx, `DELETE FROM idempotency_keys WHERE expires_at < now()`)
+	if err != nil {
+		log.Err(err).Msg("Failed to purge expired idempotency keys")
+	}
+
+	return err
+}
+
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
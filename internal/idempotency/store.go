@@ -0,0 +1,75 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultTTL is the time the response is returned to repeated requests
+const DefaultTTL = 24 * time.Hour
+
+// MaxKeyLength is the max length of the idempotency key
+const MaxKeyLength = 255
+
+// keyField is the request field with the idempotency key, it is not the part of the fingerprint
+const keyField protoreflect.Name = "idempotency_key"
+
+var (
+	// ErrInProgress is returned when the request with the same key is being processed, the client should retry later
+	ErrInProgress = errors.New("request with the same idempotency key is in progress")
+	// ErrConflict is returned when the key is reused with the different request
+	ErrConflict = errors.New("idempotency key is already used with a different request")
+)
+
+// Store keeps responses by the idempotency key. Keys are unique within the scope, e.g. the RPC name.
+type Store interface {
+	// Begin claims the key for the request with the fingerprint. It returns the stored response if the request
+	// with the same key is completed, ErrInProgress if it is being processed and ErrConflict if it has
+	// the different fingerprint. Both the response and the error are nil if the key is claimed.
+	Begin(scope string, key string, fingerprint string) ([]byte, error)
+	// Complete stores the response of the claimed key
+	Complete(scope string, key string, response []byte) error
+	// Release frees the claimed key of the failed request, so the request can be retried
+	Release(scope string, key string) error
+	// Purge removes expired keys
+	Purge() error
+}
+
+// Fingerprint identifies the request payload regardless of idempotency keys, including keys of nested messages
+func Fingerprint(request proto.Message) (string, error) {
+	clone := proto.Clone(request)
+	clearKeys(clone.ProtoReflect())
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(clone)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func clearKeys(message protoreflect.Message) {
+	if field := message.Descriptor().Fields().ByName(keyField); field != nil {
+		message.Clear(field)
+	}
+
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.Message() == nil || field.IsMap():
+		case field.IsList():
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				clearKeys(list.Get(i).Message())
+			}
+		default:
+			clearKeys(value.Message())
+		}
+		return true
+	})
+}
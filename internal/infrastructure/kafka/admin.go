@@ -0,0 +1,155 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/rs/zerolog/log"
+)
+
+const retentionConfig = "retention.ms"
+
+// TopicSpec describes the desired topic layout. Zero values are left to the broker defaults on creation
+// and are not validated for the existing topic.
+type TopicSpec struct {
+	Name              string
+	Partitions        int32
+	ReplicationFactor int16
+	Retention         time.Duration
+}
+
+// EnsureTopic creates the topic if it doesn't exist, otherwise checks that the existing topic matches the spec.
+// Existing topics are never altered, mismatches are reported as error, so the operator decides how to fix them.
+func EnsureTopic(client ClientConfig, spec TopicSpec) error {
+	config := sarama.NewConfig()
+	// DescribeConfigs requests used to validate retention are supported by brokers starting from 0.11
+	config.Version = sarama.V1_0_0_0
+	if err := client.Apply(config); err != nil {
+		return err
+	}
+
+	admin, err := sarama.NewClusterAdmin(client.Brokers, config)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	return ensureTopic(admin, spec)
+}
+
+func ensureTopic(admin sarama.ClusterAdmin, spec TopicSpec) error {
+	topics, err := admin.DescribeTopics([]string{spec.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, topic := range topics {
+		if topic.Name != spec.Name || errors.Is(topic.Err, sarama.ErrUnknownTopicOrPartition) {
+			continue
+		}
+
+		if topic.Err != sarama.ErrNoError {
+			return fmt.Errorf("can't describe topic %s: %w", spec.Name, topic.Err)
+		}
+
+		return validateTopic(admin, topic, spec)
+	}
+
+	return createTopic(admin, spec)
+}
+
+func createTopic(admin sarama.ClusterAdmin, spec TopicSpec) error {
+	// -1 asks broker to use its defaults
+	detail := &sarama.TopicDetail{
+		NumPartitions:     -1,
+		ReplicationFactor: -1,
+	}
+
+	if spec.Partitions > 0 {
+		detail.NumPartitions = spec.Partitions
+	}
+	if spec.ReplicationFactor > 0 {
+		detail.ReplicationFactor = spec.ReplicationFactor
+	}
+	if spec.Retention > 0 {
+		retention := strconv.FormatInt(spec.Retention.Milliseconds(), 10)
+		detail.ConfigEntries = map[string]*string{retentionConfig: &retention}
+	}
+
+	err := admin.CreateTopic(spec.Name, detail, false)
+	// Another instance may create the topic concurrently
+	if errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't create topic %s: %w", spec.Name, err)
+	}
+
+	log.Info().Str("topic", spec.Name).Msg("Kafka topic is created")
+	return nil
+}
+
+func validateTopic(admin sarama.ClusterAdmin, topic *sarama.TopicMetadata, spec TopicSpec) error {
+	var mismatches []string
+
+	if spec.Partitions > 0 && int32(len(topic.Partitions)) != spec.Partitions {
+		mismatches = append(mismatches, fmt.Sprintf("%d partitions instead of %d", len(topic.Partitions), spec.Partitions))
+	}
+
+	if spec.ReplicationFactor > 0 {
+		for _, partition := range topic.Partitions {
+			if int16(len(partition.Replicas)) != spec.ReplicationFactor {
+				mismatches = append(mismatches, fmt.Sprintf("replication factor %d instead of %d",
+					len(partition.Replicas), spec.ReplicationFactor))
+				break
+			}
+		}
+	}
+
+	if spec.Retention > 0 {
+		retention, err := topicRetention(admin, spec.Name)
+		if err != nil {
+			return err
+		}
+
+		if retention != spec.Retention {
+			mismatches = append(mismatches, fmt.Sprintf("retention %s instead of %s", retention, spec.Retention))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("topic %s doesn't match the desired settings: %s", spec.Name, strings.Join(mismatches, ", "))
+	}
+
+	return nil
+}
+
+func topicRetention(admin sarama.ClusterAdmin, topic string) (time.Duration, error) {
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.TopicResource,
+		Name:        topic,
+		ConfigNames: []string{retentionConfig},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can't describe topic %s config: %w", topic, err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name != retentionConfig {
+			continue
+		}
+
+		ms, parseErr := strconv.ParseInt(entry.Value, 10, 64)
+		if parseErr != nil {
+			return 0, fmt.Errorf("invalid %s of topic %s: %q", retentionConfig, topic, entry.Value)
+		}
+
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	return 0, fmt.Errorf("%s of topic %s is not returned by broker", retentionConfig, topic)
+}
@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminBroker returns the mock broker with the single partition test topic retained for 5 seconds.
+// Topics other than the test one are unknown to the broker.
+func newAdminBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetController(broker.BrokerID()).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(testTopic, 0, broker.BrokerID()),
+		"CreateTopicsRequest":    sarama.NewMockCreateTopicsResponse(t),
+		"DescribeConfigsRequest": sarama.NewMockDescribeConfigsResponse(t),
+		"ApiVersionsRequest":     sarama.NewMockApiVersionsResponse(t),
+	})
+
+	return broker
+}
+
+func TestEnsureTopic_WhenTopicDoesNotExist_ShouldCreateItWithSpec(t *testing.T) {
+	broker := newAdminBroker(t)
+	defer broker.Close()
+
+	spec := TopicSpec{Name: "new-topic", Partitions: 3, ReplicationFactor: 1, Retention: 24 * time.Hour}
+	err := EnsureTopic(ClientConfig{Brokers: []string{broker.Addr()}}, spec)
+	require.NoError(t, err, "No error should be returned")
+
+	var createRequests []*sarama.CreateTopicsRequest
+	for _, exchange := range broker.History() {
+		if request, ok := exchange.Request.(*sarama.CreateTopicsRequest); ok {
+			createRequests = append(createRequests, request)
+		}
+	}
+
+	require.Len(t, createRequests, 1, "Topic should be created once")
+	detail := createRequests[0].TopicDetails["new-topic"]
+	require.NotNil(t, detail, "Topic should be created with the spec name")
+	assert.Equal(t, int32(3), detail.NumPartitions)
+	assert.Equal(t, int16(1), detail.ReplicationFactor)
+	require.NotNil(t, detail.ConfigEntries[retentionConfig], "Retention should be set")
+	assert.Equal(t, "86400000", *detail.ConfigEntries[retentionConfig])
+}
+
+func TestEnsureTopic_WhenTopicMatchesSpec_ShouldNotCreateIt(t *testing.T) {
+	broker := newAdminBroker(t)
+	defer broker.Close()
+
+	spec := TopicSpec{Name: testTopic, Partitions: 1, ReplicationFactor: 1, Retention: 5 * time.Second}
+	err := EnsureTopic(ClientConfig{Brokers: []string{broker.Addr()}}, spec)
+	require.NoError(t, err, "No error should be returned")
+
+	for _, exchange := range broker.History() {
+		_, ok := exchange.Request.(*sarama.CreateTopicsRequest)
+		assert.False(t, ok, "Existing topic should not be created")
+	}
+}
+
+func TestEnsureTopic_WhenTopicDoesNotMatchSpec_ShouldReturnMismatches(t *testing.T) {
+	broker := newAdminBroker(t)
+	defer broker.Close()
+
+	spec := TopicSpec{Name: testTopic, Partitions: 6, Retention: time.Hour}
+	err := EnsureTopic(ClientConfig{Brokers: []string{broker.Addr()}}, spec)
+
+	require.Error(t, err, "Mismatch should be reported")
+	assert.Contains(t, err.Error(), "1 partitions instead of 6")
+	assert.Contains(t, err.Error(), "retention 5s instead of 1h0m0s")
+}
+
+func TestClientConfig_WhenSASLMechanismIsUnknown_ShouldReturnError(t *testing.T) {
+	client := ClientConfig{SASL: SASLConfig{Mechanism: "GSSAPI", User: "user"}}
+
+	err := client.Apply(sarama.NewConfig())
+
+	assert.EqualError(t, err, `unknown SASL mechanism: "GSSAPI"`)
+}
+
+func TestClientConfig_WhenSCRAMIsSet_ShouldConfigureSASL(t *testing.T) {
+	client := ClientConfig{
+		ClientID: "ova-service-api",
+		SASL:     SASLConfig{Mechanism: SASLMechanismSCRAMSHA512, User: "user", Password: "secret"},
+	}
+	config := sarama.NewConfig()
+
+	require.NoError(t, client.Apply(config), "No error should be returned")
+
+	assert.Equal(t, "ova-service-api", config.ClientID)
+	assert.True(t, config.Net.SASL.Enable, "SASL should be enabled")
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), config.Net.SASL.Mechanism)
+	require.NotNil(t, config.Net.SASL.SCRAMClientGeneratorFunc, "SCRAM client should be set")
+	assert.NoError(t, config.Net.SASL.SCRAMClientGeneratorFunc().Begin("user", "secret", ""))
+	assert.NoError(t, config.Validate(), "Config should be valid")
+}
+
+func TestClientConfig_WhenCAFileDoesNotExist_ShouldReturnError(t *testing.T) {
+	client := ClientConfig{TLS: TLSConfig{Enabled: true, CAFile: "does-not-exist.pem"}}
+
+	err := client.Apply(sarama.NewConfig())
+
+	assert.Error(t, err, "Missing CA file should be reported")
+}
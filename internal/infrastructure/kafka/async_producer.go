@@ -0,0 +1,206 @@
+package kafka
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/rs/zerolog/log"
+)
+
+// DeliveryCallback is called once for every message sent with AsyncProducer, err is nil on successful delivery.
+// It is called from the producer goroutines, so it must be safe for concurrent use and must not block for long.
+type DeliveryCallback func(message Message, err error)
+
+// ProducerMetrics tracks the state of messages which are accepted by the producer but not acknowledged by brokers yet.
+type ProducerMetrics interface {
+	IncrementKafkaInFlight()
+	DecrementKafkaInFlight()
+	IncrementKafkaFailed()
+}
+
+// AsyncProducerConfig contains batching settings of the AsyncProducer. Zero values keep sarama defaults.
+type AsyncProducerConfig struct {
+	// BatchSize is the number of messages which triggers the flush
+	BatchSize int
+	// BatchBytes is the size of messages in bytes which triggers the flush
+	BatchBytes int
+	// Linger is the longest time messages are buffered before the flush
+	Linger time.Duration
+	// Compression codec: none, gzip, snappy, lz4 or zstd
+	Compression string
+}
+
+// AsyncProducer does not wait for the brokers acknowledgment, messages are batched and sent in background.
+// SendMessage and SendMessages return as soon as messages are enqueued, delivery result is reported to the callback.
+type AsyncProducer struct {
+	sync.RWMutex
+	topic      string
+	producer   sarama.AsyncProducer
+	onDelivery DeliveryCallback
+	metrics    ProducerMetrics
+	closed     bool
+	wg         sync.WaitGroup
+}
+
+func NewAsyncProducer(topic string, client ClientConfig, config AsyncProducerConfig, onDelivery DeliveryCallback, metrics ProducerMetrics) (*AsyncProducer, error) {
+	saramaConfig, err := newAsyncConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = client.Apply(saramaConfig); err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewAsyncProducer(client.Brokers, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return WrapAsyncProducer(topic, producer, onDelivery, metrics), nil
+}
+
+// WrapAsyncProducer returns AsyncProducer sending messages with the already created sarama producer, e.g. the mock.
+// The producer must return errors, successes are reported only if it returns them as well.
+func WrapAsyncProducer(topic string, producer sarama.AsyncProducer, onDelivery DeliveryCallback, metrics ProducerMetrics) *AsyncProducer {
+	if onDelivery == nil {
+		onDelivery = logDeliveryFailure
+	}
+
+	kap := &AsyncProducer{
+		topic:      topic,
+		producer:   producer,
+		onDelivery: onDelivery,
+		metrics:    metrics,
+	}
+
+	kap.wg.Add(2)
+	go kap.handleSuccesses()
+	go kap.handleErrors()
+
+	return kap
+}
+
+func newAsyncConfig(config AsyncProducerConfig) (*sarama.Config, error) {
+	saramaConfig := newConfig()
+	saramaConfig.Producer.Return.Errors = true
+	saramaConfig.Producer.Flush.Messages = config.BatchSize
+	saramaConfig.Producer.Flush.Bytes = config.BatchBytes
+	saramaConfig.Producer.Flush.Frequency = config.Linger
+
+	switch config.Compression {
+	case "", "none":
+		saramaConfig.Producer.Compression = sarama.CompressionNone
+	case "gzip":
+		saramaConfig.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		saramaConfig.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		saramaConfig.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		// ZSTD is supported by brokers starting from 2.1.0
+		saramaConfig.Version = sarama.V2_1_0_0
+		saramaConfig.Producer.Compression = sarama.CompressionZSTD
+	default:
+		return nil, fmt.Errorf("unknown compression codec: %q", config.Compression)
+	}
+
+	if err := saramaConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return saramaConfig, nil
+}
+
+func (kap *AsyncProducer) SendMessage(message Message) error {
+	if len(message.Value) == 0 {
+		return fmt.Errorf("empty message is not allowed")
+	}
+
+	return kap.enqueue([]Message{message})
+}
+
+func (kap *AsyncProducer) SendMessages(messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	for _, message := range messages {
+		if len(message.Value) == 0 {
+			return fmt.Errorf("some of the messages are empty")
+		}
+	}
+
+	return kap.enqueue(messages)
+}
+
+// Close flushes buffered messages and waits until delivery results of all of them are reported.
+func (kap *AsyncProducer) Close() error {
+	kap.Lock()
+	if kap.closed {
+		kap.Unlock()
+		return nil
+	}
+	kap.closed = true
+	kap.Unlock()
+
+	kap.producer.AsyncClose()
+	kap.wg.Wait()
+	return nil
+}
+
+func (kap *AsyncProducer) enqueue(messages []Message) error {
+	// Read lock prevents sending to the input channel after it is closed by Close.
+	kap.RLock()
+	defer kap.RUnlock()
+
+	if kap.closed {
+		return fmt.Errorf("producer is closed")
+	}
+
+	for _, message := range messages {
+		msg := prepareMessage(kap.topic, message)
+		msg.Metadata = message
+
+		if kap.metrics != nil {
+			kap.metrics.IncrementKafkaInFlight()
+		}
+		kap.producer.Input() <- msg
+	}
+
+	return nil
+}
+
+func (kap *AsyncProducer) handleSuccesses() {
+	defer kap.wg.Done()
+
+	for msg := range kap.producer.Successes() {
+		if kap.metrics != nil {
+			kap.metrics.DecrementKafkaInFlight()
+		}
+
+		kap.onDelivery(msg.Metadata.(Message), nil)
+	}
+}
+
+func (kap *AsyncProducer) handleErrors() {
+	defer kap.wg.Done()
+
+	for producerErr := range kap.producer.Errors() {
+		if kap.metrics != nil {
+			kap.metrics.DecrementKafkaInFlight()
+			kap.metrics.IncrementKafkaFailed()
+		}
+
+		message, _ := producerErr.Msg.Metadata.(Message)
+		kap.onDelivery(message, producerErr.Err)
+	}
+}
+
+func logDeliveryFailure(message Message, err error) {
+	if err != nil {
+		log.Err(err).Str("key", string(message.Key)).Msg("Failed to deliver message to Kafka")
+	}
+}
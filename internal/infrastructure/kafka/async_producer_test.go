@@ -0,0 +1,117 @@
+package kafka
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingMetrics struct {
+	sync.Mutex
+	inFlight int
+	failed   int
+}
+
+func (m *countingMetrics) IncrementKafkaInFlight() {
+	m.Lock()
+	defer m.Unlock()
+	m.inFlight++
+}
+
+func (m *countingMetrics) DecrementKafkaInFlight() {
+	m.Lock()
+	defer m.Unlock()
+	m.inFlight--
+}
+
+func (m *countingMetrics) IncrementKafkaFailed() {
+	m.Lock()
+	defer m.Unlock()
+	m.failed++
+}
+
+type deliveryRecorder struct {
+	sync.Mutex
+	delivered []string
+	failed    []string
+}
+
+func (r *deliveryRecorder) onDelivery(message Message, err error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err != nil {
+		r.failed = append(r.failed, string(message.Value))
+		return
+	}
+	r.delivered = append(r.delivered, string(message.Value))
+}
+
+func newMockAsyncProducer(t *testing.T, recorder *deliveryRecorder, metrics *countingMetrics) (*AsyncProducer, *mocks.AsyncProducer) {
+	config, err := newAsyncConfig(AsyncProducerConfig{})
+	require.NoError(t, err, "Default config should be valid")
+
+	mock := mocks.NewAsyncProducer(t, config)
+	return WrapAsyncProducer(testTopic, mock, recorder.onDelivery, metrics), mock
+}
+
+func TestAsyncProducer_WhenMessagesAreDelivered_ShouldReportSuccessToCallback(t *testing.T) {
+	recorder := &deliveryRecorder{}
+	metrics := &countingMetrics{}
+	producer, mock := newMockAsyncProducer(t, recorder, metrics)
+	mock.ExpectInputAndSucceed()
+	mock.ExpectInputAndSucceed()
+
+	err := producer.SendMessages([]Message{{Value: []byte("first")}, {Value: []byte("second")}})
+	require.NoError(t, err, "No error should be returned")
+	require.NoError(t, producer.Close(), "Producer should be closed without error")
+
+	assert.Equal(t, []string{"first", "second"}, recorder.delivered, "Every message should be reported as delivered")
+	assert.Empty(t, recorder.failed, "No message should be reported as failed")
+	assert.Equal(t, 0, metrics.inFlight, "No messages should be in flight after close")
+	assert.Equal(t, 0, metrics.failed, "Failed counter should not be incremented")
+}
+
+func TestAsyncProducer_WhenDeliveryFails_ShouldReportErrorToCallback(t *testing.T) {
+	recorder := &deliveryRecorder{}
+	metrics := &countingMetrics{}
+	producer, mock := newMockAsyncProducer(t, recorder, metrics)
+	mock.ExpectInputAndFail(fmt.Errorf("broker is down"))
+
+	require.NoError(t, producer.SendMessage(Message{Value: []byte("lost")}), "Error should be reported asynchronously")
+	require.NoError(t, producer.Close(), "Producer should be closed without error")
+
+	assert.Equal(t, []string{"lost"}, recorder.failed, "Message should be reported as failed")
+	assert.Equal(t, 0, metrics.inFlight, "No messages should be in flight after close")
+	assert.Equal(t, 1, metrics.failed, "Failed counter should be incremented")
+}
+
+func TestAsyncProducer_WhenClosed_ShouldRejectMessages(t *testing.T) {
+	producer, _ := newMockAsyncProducer(t, &deliveryRecorder{}, &countingMetrics{})
+	require.NoError(t, producer.Close(), "Producer should be closed without error")
+
+	err := producer.SendMessage(Message{Value: []byte("late")})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "producer is closed", err.Error(), "Incorrect error message")
+}
+
+func TestNewAsyncConfig_WhenUnknownCompression_ShouldReturnError(t *testing.T) {
+	_, err := newAsyncConfig(AsyncProducerConfig{Compression: "brotli"})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "unknown compression codec: \"brotli\"", err.Error(), "Incorrect error message")
+}
+
+func TestNewAsyncConfig_WhenBatchingIsConfigured_ShouldApplySettings(t *testing.T) {
+	config, err := newAsyncConfig(AsyncProducerConfig{BatchSize: 100, Linger: 5, Compression: "zstd"})
+
+	require.NoError(t, err, "Valid config should be created")
+	assert.Equal(t, 100, config.Producer.Flush.Messages)
+	assert.EqualValues(t, 5, config.Producer.Flush.Frequency)
+	assert.Equal(t, "zstd", config.Producer.Compression.String())
+}
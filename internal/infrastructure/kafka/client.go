@@ -0,0 +1,123 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Shopify/sarama"
+)
+
+// SASL mechanisms supported by ClientConfig
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismSCRAMSHA256 = "SCRAM-SHA-256"
+	SASLMechanismSCRAMSHA512 = "SCRAM-SHA-512"
+)
+
+// ClientConfig contains connection settings shared by producers, consumers and the cluster admin.
+// Zero value of TLS and SASL settings means plaintext connection without authentication.
+type ClientConfig struct {
+	Brokers []string
+	// ClientID is sent to brokers with every request, it shows up in broker logs and quotas
+	ClientID string
+	TLS      TLSConfig
+	SASL     SASLConfig
+}
+
+type TLSConfig struct {
+	Enabled bool
+	// CAFile is the PEM bundle of the broker certificate authorities, system roots are used if it is empty
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and key, they are required by brokers with mutual TLS only
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+type SASLConfig struct {
+	// Mechanism is one of PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512, SASL is disabled if it is empty
+	Mechanism string
+	User      string
+	Password  string
+}
+
+// Apply sets connection settings of the sarama config.
+func (c ClientConfig) Apply(config *sarama.Config) error {
+	if len(c.ClientID) > 0 {
+		config.ClientID = c.ClientID
+	}
+
+	if c.TLS.Enabled {
+		tlsConfig, err := c.TLS.build()
+		if err != nil {
+			return err
+		}
+
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	return c.SASL.apply(config)
+}
+
+func (c TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if len(c.CAFile) > 0 {
+		ca, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read Kafka CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in Kafka CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(c.CertFile) > 0 || len(c.KeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load Kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c SASLConfig) apply(config *sarama.Config) error {
+	switch c.Mechanism {
+	case "":
+		return nil
+	case SASLMechanismPlain:
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case SASLMechanismSCRAMSHA256:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashGenerator: sha256Generator}
+		}
+	case SASLMechanismSCRAMSHA512:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashGenerator: sha512Generator}
+		}
+	default:
+		return fmt.Errorf("unknown SASL mechanism: %q", c.Mechanism)
+	}
+
+	if len(c.User) == 0 {
+		return fmt.Errorf("SASL user is required")
+	}
+
+	config.Net.SASL.Enable = true
+	config.Net.SASL.User = c.User
+	config.Net.SASL.Password = c.Password
+	return nil
+}
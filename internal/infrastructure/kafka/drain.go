@@ -0,0 +1,124 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+)
+
+// DrainTopic passes every message of the topic to the handler, starting from the offsets committed by the group
+// and up to the end of partitions at the moment of the call. Offsets of the handled messages are committed,
+// so they are not drained twice. Draining stops on the first handler error, the failed message is drained next time.
+func DrainTopic(ctx context.Context, clientConfig ClientConfig, topic string, groupID string, handler func(Message) error) (int, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	if err := clientConfig.Apply(config); err != nil {
+		return 0, err
+	}
+
+	client, err := sarama.NewClient(clientConfig.Brokers, config)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return 0, err
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, err
+	}
+	defer consumer.Close()
+
+	offsetManager, err := sarama.NewOffsetManagerFromClient(groupID, client)
+	if err != nil {
+		return 0, err
+	}
+	// Offsets are committed on close
+	defer offsetManager.Close()
+
+	drained := 0
+	for _, partition := range partitions {
+		count, drainErr := drainPartition(ctx, client, consumer, offsetManager, topic, partition, handler)
+		drained += count
+
+		if drainErr != nil {
+			return drained, drainErr
+		}
+	}
+
+	return drained, nil
+}
+
+func drainPartition(ctx context.Context, client sarama.Client, consumer sarama.Consumer, offsetManager sarama.OffsetManager,
+	topic string, partition int32, handler func(Message) error) (int, error) {
+	// High water mark is the offset of the next produced message, so messages produced after this call are not drained
+	highWaterMark, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, err
+	}
+
+	partitionOffsets, err := offsetManager.ManagePartition(topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer partitionOffsets.Close()
+
+	offset, _ := partitionOffsets.NextOffset()
+	if offset == sarama.OffsetOldest {
+		if offset, err = client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+			return 0, err
+		}
+	}
+
+	if offset >= highWaterMark {
+		return 0, nil
+	}
+
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer partitionConsumer.Close()
+
+	drained := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return drained, ctx.Err()
+		case consumerErr := <-partitionConsumer.Errors():
+			return drained, consumerErr
+		case msg, ok := <-partitionConsumer.Messages():
+			if !ok {
+				return drained, nil
+			}
+
+			if err = handler(newMessage(msg)); err != nil {
+				return drained, err
+			}
+
+			partitionOffsets.MarkOffset(msg.Offset+1, "")
+			drained++
+
+			if msg.Offset+1 >= highWaterMark {
+				return drained, nil
+			}
+		}
+	}
+}
+
+func newMessage(msg *sarama.ConsumerMessage) Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	return Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
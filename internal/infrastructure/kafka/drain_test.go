@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGroup = "drain"
+
+// newDrainBroker returns the mock broker serving the single partition topic with two messages
+// starting from the committed offset 1 and the high water mark 3.
+func newDrainBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(testTopic, 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset(testTopic, 0, sarama.OffsetOldest, 0).
+			SetOffset(testTopic, 0, sarama.OffsetNewest, 3),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, testGroup, broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset(testGroup, testTopic, 0, 1, "", sarama.ErrNoError),
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+		"FetchRequest": sarama.NewMockFetchResponse(t, 1).
+			SetMessage(testTopic, 0, 1, sarama.StringEncoder("first")).
+			SetMessage(testTopic, 0, 2, sarama.StringEncoder("second")).
+			SetHighWaterMark(testTopic, 0, 3),
+	})
+
+	return broker
+}
+
+func TestDrainTopic_WhenGroupHasCommittedOffset_ShouldDrainFromItUpToHighWaterMark(t *testing.T) {
+	broker := newDrainBroker(t)
+	defer broker.Close()
+
+	var drained []string
+	count, err := DrainTopic(context.Background(), ClientConfig{Brokers: []string{broker.Addr()}}, testTopic, testGroup, func(message Message) error {
+		drained = append(drained, string(message.Value))
+		return nil
+	})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"first", "second"}, drained, "Messages should be drained in order")
+}
+
+func TestDrainTopic_WhenHandlerFails_ShouldStopDraining(t *testing.T) {
+	broker := newDrainBroker(t)
+	defer broker.Close()
+
+	count, err := DrainTopic(context.Background(), ClientConfig{Brokers: []string{broker.Addr()}}, testTopic, testGroup, func(message Message) error {
+		return fmt.Errorf("main topic is unavailable")
+	})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "main topic is unavailable", err.Error(), "Incorrect error message")
+	assert.Equal(t, 0, count, "Failed message should not be counted")
+}
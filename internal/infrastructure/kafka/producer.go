@@ -2,12 +2,23 @@ package kafka
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/Shopify/sarama"
 )
 
+// Message is a single record to be produced to the topic. Headers are sent as Kafka record headers.
+// Messages with the same key are produced to the same partition, messages without key are spread randomly.
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
 type Producer interface {
-	SendMessage(message string) error
-	SendMessages(messages []string) error
+	SendMessage(message Message) error
+	SendMessages(messages []Message) error
+	Close() error
 }
 
 type SyncProducer struct {
@@ -15,13 +26,13 @@ type SyncProducer struct {
 	producer sarama.SyncProducer
 }
 
-func NewSyncProducer(topic string, brokers []string) (*SyncProducer, error) {
-	config := sarama.NewConfig()
-	config.Producer.Partitioner = sarama.NewRandomPartitioner
-	config.Producer.RequiredAcks = sarama.WaitForAll
-	config.Producer.Return.Successes = true
+func NewSyncProducer(topic string, client ClientConfig) (*SyncProducer, error) {
+	config := newConfig()
+	if err := client.Apply(config); err != nil {
+		return nil, err
+	}
 
-	producer, err := sarama.NewSyncProducer(brokers, config)
+	producer, err := sarama.NewSyncProducer(client.Brokers, config)
 
 	if err != nil {
 		return nil, err
@@ -35,8 +46,45 @@ func NewSyncProducer(topic string, brokers []string) (*SyncProducer, error) {
 	return &ksp, err
 }
 
-func (ksp SyncProducer) SendMessage(message string) error {
-	if len(message) == 0 {
+// NewIdempotentProducer returns SyncProducer which doesn't duplicate messages on internal retries.
+// Brokers deduplicate messages by the producer ID and sequence numbers, so Kafka 0.11 or newer is required.
+func NewIdempotentProducer(topic string, client ClientConfig) (*SyncProducer, error) {
+	config := newIdempotentConfig()
+	if err := client.Apply(config); err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewSyncProducer(client.Brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyncProducer{
+		topic:    topic,
+		producer: producer,
+	}, nil
+}
+
+func newConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	// Hash partitioner keeps all messages with the same key in the same partition,
+	// so consumers receive changes of the single entity in the order they were produced.
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+	return config
+}
+
+func newIdempotentConfig() *sarama.Config {
+	config := newConfig()
+	config.Producer.Idempotent = true
+	// Sequence numbers are assigned per connection, so requests must not be reordered by pipelining
+	config.Net.MaxOpenRequests = 1
+	return config
+}
+
+func (ksp SyncProducer) SendMessage(message Message) error {
+	if len(message.Value) == 0 {
 		return fmt.Errorf("empty message is not allowed")
 	}
 
@@ -45,31 +93,73 @@ func (ksp SyncProducer) SendMessage(message string) error {
 	return err
 }
 
-func (ksp SyncProducer) SendMessages(messages []string) error {
+func (ksp SyncProducer) SendMessages(messages []Message) error {
 	if len(messages) == 0 {
 		return nil
 	}
 
+	msgs, err := prepareMessages(ksp.topic, messages)
+	if err != nil {
+		return err
+	}
+
+	err = ksp.producer.SendMessages(msgs)
+	return err
+}
+
+func (ksp SyncProducer) Close() error {
+	return ksp.producer.Close()
+}
+
+func prepareMessages(topic string, messages []Message) ([]*sarama.ProducerMessage, error) {
 	msgs := make([]*sarama.ProducerMessage, len(messages))
 
 	for i, message := range messages {
-		if len(message) == 0 {
-			return fmt.Errorf("some of the messages are empty")
+		if len(message.Value) == 0 {
+			return nil, fmt.Errorf("some of the messages are empty")
 		}
 
-		msg := prepareMessage(ksp.topic, message)
-		msgs[i] = msg
+		msgs[i] = prepareMessage(topic, message)
 	}
 
-	err := ksp.producer.SendMessages(msgs)
-	return err
+	return msgs, nil
 }
 
-func prepareMessage(topic string, message string) *sarama.ProducerMessage {
+func prepareMessage(topic string, message Message) *sarama.ProducerMessage {
 	msg := &sarama.ProducerMessage{
 		Topic:     topic,
 		Partition: -1,
-		Value:     sarama.StringEncoder(message),
+		Value:     sarama.ByteEncoder(message.Value),
+		Headers:   prepareHeaders(message.Headers),
+	}
+
+	// Nil key encoder is treated by sarama as an absent key, while empty ByteEncoder is still hashed.
+	if len(message.Key) > 0 {
+		msg.Key = sarama.ByteEncoder(message.Key)
 	}
+
 	return msg
 }
+
+func prepareHeaders(headers map[string]string) []sarama.RecordHeader {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	// Sort keys to produce headers in a stable order, maps do not guarantee any.
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	recordHeaders := make([]sarama.RecordHeader, len(keys))
+	for i, key := range keys {
+		recordHeaders[i] = sarama.RecordHeader{
+			Key:   []byte(key),
+			Value: []byte(headers[key]),
+		}
+	}
+
+	return recordHeaders
+}
@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTopic      = "services"
+	testPartitions = 12
+)
+
+type producedMessage struct {
+	key       string
+	value     string
+	partition int32
+}
+
+// newRecordingProducer returns SyncProducer backed by the sarama mock which partitions messages
+// with the real producer config and records every produced message in the produced slice.
+func newRecordingProducer(t *testing.T, expected int, produced *[]producedMessage) (SyncProducer, *mocks.SyncProducer) {
+	mock := mocks.NewSyncProducer(t, newConfig())
+	mock.SetDefaultPartitions(testPartitions)
+
+	for i := 0; i < expected; i++ {
+		mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			var key string
+			if msg.Key != nil {
+				keyBytes, _ := msg.Key.Encode()
+				key = string(keyBytes)
+			}
+			value, _ := msg.Value.Encode()
+
+			*produced = append(*produced, producedMessage{key: key, value: string(value), partition: msg.Partition})
+			return nil
+		})
+	}
+
+	return SyncProducer{topic: testTopic, producer: mock}, mock
+}
+
+func TestSyncProducer_WhenMessagesShareKey_ShouldProduceThemToSamePartitionInOrder(t *testing.T) {
+	keys := []string{"service-1", "service-2", "service-3", "service-4"}
+	changes := []string{"create", "update", "delete"}
+
+	messages := make([]Message, 0, len(keys)*len(changes))
+	for _, change := range changes {
+		for _, key := range keys {
+			messages = append(messages, Message{Key: []byte(key), Value: []byte(fmt.Sprintf("%s:%s", key, change))})
+		}
+	}
+
+	var produced []producedMessage
+	producer, mock := newRecordingProducer(t, len(messages), &produced)
+
+	require.NoError(t, producer.SendMessages(messages), "No error should be returned")
+	require.NoError(t, mock.Close(), "All expectations should be satisfied")
+
+	partitions := make(map[string]int32)
+	values := make(map[string][]string)
+	for _, msg := range produced {
+		if partition, ok := partitions[msg.key]; ok {
+			assert.Equal(t, partition, msg.partition, "Messages with key %q should be produced to the same partition", msg.key)
+		}
+		partitions[msg.key] = msg.partition
+		values[msg.key] = append(values[msg.key], msg.value)
+	}
+
+	for _, key := range keys {
+		expected := []string{key + ":create", key + ":update", key + ":delete"}
+		assert.Equal(t, expected, values[key], "Messages with key %q should keep the produced order", key)
+	}
+}
+
+func TestSyncProducer_WhenSendMessage_ShouldPassKeyAndHeaders(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, newConfig())
+	mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, _ := msg.Key.Encode()
+		assert.Equal(t, "service-1", string(key))
+		assert.Equal(t, []sarama.RecordHeader{
+			{Key: []byte("a"), Value: []byte("1")},
+			{Key: []byte("b"), Value: []byte("2")},
+		}, msg.Headers, "Headers should be sorted by key")
+		return nil
+	})
+	producer := SyncProducer{topic: testTopic, producer: mock}
+
+	err := producer.SendMessage(Message{
+		Key:     []byte("service-1"),
+		Value:   []byte("value"),
+		Headers: map[string]string{"b": "2", "a": "1"},
+	})
+
+	require.NoError(t, err, "No error should be returned")
+	require.NoError(t, mock.Close(), "All expectations should be satisfied")
+}
+
+func TestSyncProducer_WhenMessageIsEmpty_ShouldReturnError(t *testing.T) {
+	producer := SyncProducer{topic: testTopic, producer: mocks.NewSyncProducer(t, newConfig())}
+
+	err := producer.SendMessage(Message{Key: []byte("service-1")})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "empty message is not allowed", err.Error(), "Incorrect error message")
+}
+
+func TestPrepareMessage_WhenKeyIsEmpty_ShouldNotSetKey(t *testing.T) {
+	msg := prepareMessage(testTopic, Message{Value: []byte("value")})
+
+	assert.Nil(t, msg.Key, "Absent key should be passed to sarama as nil")
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/rs/zerolog/log"
+)
+
+// TransactionalProducer produces every SendMessages batch in a single Kafka transaction, consumers with
+// read_committed isolation level see either all messages of the batch or none of them. The producer is idempotent,
+// so internal retries do not duplicate messages either.
+//
+// Transactional ID must be unique for the service instance and stable across its restarts: brokers use it
+// to abort transactions left unfinished by the previous incarnation of the instance and to fence it off.
+// Transactions are serialized, so the producer handles one batch at a time.
+type TransactionalProducer struct {
+	sync.Mutex
+	topic    string
+	producer sarama.SyncProducer
+}
+
+func NewTransactionalProducer(topic string, client ClientConfig, transactionalID string) (*TransactionalProducer, error) {
+	if len(transactionalID) == 0 {
+		return nil, fmt.Errorf("transactional ID is required")
+	}
+
+	config := newTransactionalConfig(transactionalID)
+	if err := client.Apply(config); err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewSyncProducer(client.Brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransactionalProducer(topic, producer), nil
+}
+
+func newTransactionalProducer(topic string, producer sarama.SyncProducer) *TransactionalProducer {
+	return &TransactionalProducer{
+		topic:    topic,
+		producer: producer,
+	}
+}
+
+func newTransactionalConfig(transactionalID string) *sarama.Config {
+	config := newIdempotentConfig()
+	config.Producer.Transaction.ID = transactionalID
+	return config
+}
+
+func (ktp *TransactionalProducer) SendMessage(message Message) error {
+	if len(message.Value) == 0 {
+		return fmt.Errorf("empty message is not allowed")
+	}
+
+	return ktp.SendMessages([]Message{message})
+}
+
+func (ktp *TransactionalProducer) SendMessages(messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	msgs, err := prepareMessages(ktp.topic, messages)
+	if err != nil {
+		return err
+	}
+
+	ktp.Lock()
+	defer ktp.Unlock()
+
+	if err = ktp.producer.BeginTxn(); err != nil {
+		return err
+	}
+
+	if err = ktp.producer.SendMessages(msgs); err != nil {
+		ktp.abort()
+		return err
+	}
+
+	if err = ktp.producer.CommitTxn(); err != nil {
+		ktp.abort()
+		return err
+	}
+
+	return nil
+}
+
+func (ktp *TransactionalProducer) Close() error {
+	return ktp.producer.Close()
+}
+
+// abort rolls back the current transaction, messages already written to partitions are skipped by read_committed consumers
+func (ktp *TransactionalProducer) abort() {
+	if err := ktp.producer.AbortTxn(); err != nil {
+		log.Err(err).Msg("Failed to abort Kafka transaction")
+	}
+}
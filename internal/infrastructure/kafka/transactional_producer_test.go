@@ -0,0 +1,140 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTransactionalID = "ova-service-api-test"
+
+// txnRecorder records transaction calls made to the mock producer
+type txnRecorder struct {
+	*mocks.SyncProducer
+	calls []string
+}
+
+func (r *txnRecorder) BeginTxn() error {
+	r.calls = append(r.calls, "begin")
+	return r.SyncProducer.BeginTxn()
+}
+
+func (r *txnRecorder) CommitTxn() error {
+	r.calls = append(r.calls, "commit")
+	return r.SyncProducer.CommitTxn()
+}
+
+func (r *txnRecorder) AbortTxn() error {
+	r.calls = append(r.calls, "abort")
+	return r.SyncProducer.AbortTxn()
+}
+
+func newRecordingTransactionalProducer(t *testing.T) (*TransactionalProducer, *txnRecorder) {
+	recorder := &txnRecorder{SyncProducer: mocks.NewSyncProducer(t, newTransactionalConfig(testTransactionalID))}
+	return newTransactionalProducer(testTopic, recorder), recorder
+}
+
+func TestTransactionalProducer_WhenBatchIsSent_ShouldCommitTransaction(t *testing.T) {
+	producer, recorder := newRecordingTransactionalProducer(t)
+	recorder.ExpectSendMessageAndSucceed()
+	recorder.ExpectSendMessageAndSucceed()
+
+	err := producer.SendMessages([]Message{{Value: []byte("first")}, {Value: []byte("second")}})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, []string{"begin", "commit"}, recorder.calls, "Batch should be sent in a single transaction")
+}
+
+func TestTransactionalProducer_WhenSendFails_ShouldAbortTransaction(t *testing.T) {
+	producer, recorder := newRecordingTransactionalProducer(t)
+	recorder.ExpectSendMessageAndSucceed()
+	recorder.ExpectSendMessageAndFail(fmt.Errorf("broker is down"))
+
+	err := producer.SendMessages([]Message{{Value: []byte("first")}, {Value: []byte("second")}})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, []string{"begin", "abort"}, recorder.calls, "Partially sent batch should be aborted")
+}
+
+func TestTransactionalProducer_WhenMessageIsEmpty_ShouldNotBeginTransaction(t *testing.T) {
+	producer, recorder := newRecordingTransactionalProducer(t)
+
+	err := producer.SendMessages([]Message{{Value: []byte("first")}, {}})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "some of the messages are empty", err.Error(), "Incorrect error message")
+	assert.Empty(t, recorder.calls, "Transaction should not be started")
+}
+
+func TestNewTransactionalProducer_WhenTransactionalIDIsEmpty_ShouldReturnError(t *testing.T) {
+	_, err := NewTransactionalProducer(testTopic, ClientConfig{Brokers: []string{"localhost:9092"}}, "")
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "transactional ID is required", err.Error(), "Incorrect error message")
+}
+
+// newTransactionalBroker returns the mock broker acting as the leader of the single partition topic
+// and the transaction coordinator.
+func newTransactionalBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetController(broker.BrokerID()).
+			SetLeader(testTopic, 0, broker.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorTransaction, testTransactionalID, broker),
+		"InitProducerIDRequest": sarama.NewMockWrapper(&sarama.InitProducerIDResponse{
+			ProducerID: 1000,
+		}),
+		"AddPartitionsToTxnRequest": sarama.NewMockWrapper(&sarama.AddPartitionsToTxnResponse{
+			Errors: map[string][]*sarama.PartitionError{testTopic: {{Partition: 0}}},
+		}),
+		// Produce v3 is the first version with transactions, it is used by the default protocol version
+		"ProduceRequest": sarama.NewMockProduceResponse(t).SetVersion(3),
+		"EndTxnRequest":  sarama.NewMockWrapper(&sarama.EndTxnResponse{}),
+	})
+
+	return broker
+}
+
+func TestTransactionalProducer_WhenSentToBroker_ShouldProduceBatchInCommittedTransaction(t *testing.T) {
+	broker := newTransactionalBroker(t)
+	defer broker.Close()
+
+	producer, err := NewTransactionalProducer(testTopic, ClientConfig{Brokers: []string{broker.Addr()}}, testTransactionalID)
+	require.NoError(t, err, "Producer should be initialized by the coordinator")
+
+	err = producer.SendMessages([]Message{
+		{Key: []byte("service-1"), Value: []byte("first")},
+		{Key: []byte("service-2"), Value: []byte("second")},
+	})
+	require.NoError(t, err, "No error should be returned")
+	require.NoError(t, producer.Close(), "Producer should be closed without error")
+
+	var (
+		produceRequests []*sarama.ProduceRequest
+		endTxnRequests  []*sarama.EndTxnRequest
+	)
+	for _, exchange := range broker.History() {
+		switch request := exchange.Request.(type) {
+		case *sarama.ProduceRequest:
+			produceRequests = append(produceRequests, request)
+		case *sarama.EndTxnRequest:
+			endTxnRequests = append(endTxnRequests, request)
+		}
+	}
+
+	require.Len(t, produceRequests, 1, "Batch should be produced with a single request")
+	require.NotNil(t, produceRequests[0].TransactionalID, "Batch should be produced in transaction")
+	assert.Equal(t, testTransactionalID, *produceRequests[0].TransactionalID)
+
+	require.Len(t, endTxnRequests, 1, "Transaction should be finished once")
+	assert.True(t, endTxnRequests[0].TransactionResult, "Transaction should be committed")
+	assert.Equal(t, int64(1000), endTxnRequests[0].ProducerID, "Producer ID assigned by the coordinator should be used")
+}
@@ -1,19 +1,38 @@
 package metrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type Metrics interface {
 	IncrementCreateCounter()
 	IncrementMultiCreateCounter()
+	IncrementImportCounter()
 	IncrementUpdateCounter()
 	IncrementRemoveCounter()
+	IncrementKafkaInFlight()
+	DecrementKafkaInFlight()
+	IncrementKafkaFailed()
+	IncrementEventRetried()
+	IncrementEventDeadLettered()
+	SetEventRetryQueueSize(size int)
+	SetReadModelLag(lag time.Duration)
 }
 
 type PrometheusMetrics struct {
 	createCounter      prometheus.Counter
 	multiCreateCounter prometheus.Counter
+	importCounter      prometheus.Counter
 	updateCounter      prometheus.Counter
 	removeCounter      prometheus.Counter
+	kafkaInFlight      prometheus.Gauge
+	kafkaFailedCounter prometheus.Counter
+	retriedCounter     prometheus.Counter
+	deadLetterCounter  prometheus.Counter
+	retryQueueSize     prometheus.Gauge
+	readModelLag       prometheus.Gauge
 }
 
 func NewPrometheusMetrics() *PrometheusMetrics {
@@ -27,6 +46,11 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 		Help: "Number of successfully handled MultiCreate requests",
 	})
 
+	importCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "grpc_request_import_succeed_count",
+		Help: "Number of completed Import streams",
+	})
+
 	updateCounter := prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "grpc_request_update_succeed_count",
 		Help: "Number of successfully handled Update requests",
@@ -37,13 +61,51 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 		Help: "Number of successfully handled Remove requests",
 	})
 
-	prometheus.MustRegister(createCounter, multiCreateCounter, updateCounter, removeCounter)
+	kafkaInFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_producer_in_flight_messages",
+		Help: "Number of messages accepted by Kafka producer and not yet acknowledged by brokers",
+	})
+
+	kafkaFailedCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "kafka_producer_failed_count",
+		Help: "Number of messages which Kafka producer failed to deliver",
+	})
+
+	retriedCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "event_publish_retried_count",
+		Help: "Number of events delivered from the retry queue",
+	})
+
+	deadLetterCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "event_publish_dead_lettered_count",
+		Help: "Number of events sent to the dead-letter topic after exhausting publish attempts",
+	})
+
+	retryQueueSize := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "event_retry_queue_size",
+		Help: "Number of events waiting in the retry queue",
+	})
+
+	readModelLag := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "read_model_lag_seconds",
+		Help: "Time between the last projected event and its projection to the read model",
+	})
+
+	prometheus.MustRegister(createCounter, multiCreateCounter, importCounter, updateCounter, removeCounter, kafkaInFlight, kafkaFailedCounter,
+		retriedCounter, deadLetterCounter, retryQueueSize, readModelLag)
 
 	return &PrometheusMetrics{
 		createCounter:      createCounter,
 		multiCreateCounter: multiCreateCounter,
+		importCounter:      importCounter,
 		updateCounter:      updateCounter,
 		removeCounter:      removeCounter,
+		kafkaInFlight:      kafkaInFlight,
+		kafkaFailedCounter: kafkaFailedCounter,
+		retriedCounter:     retriedCounter,
+		deadLetterCounter:  deadLetterCounter,
+		retryQueueSize:     retryQueueSize,
+		readModelLag:       readModelLag,
 	}
 }
 
@@ -55,6 +117,10 @@ func (m *PrometheusMetrics) IncrementMultiCreateCounter() {
 	m.multiCreateCounter.Inc()
 }
 
+func (m *PrometheusMetrics) IncrementImportCounter() {
+	m.importCounter.Inc()
+}
+
 func (m *PrometheusMetrics) IncrementUpdateCounter() {
 	m.updateCounter.Inc()
 }
@@ -62,3 +128,31 @@ func (m *PrometheusMetrics) IncrementUpdateCounter() {
 func (m *PrometheusMetrics) IncrementRemoveCounter() {
 	m.removeCounter.Inc()
 }
+
+func (m *PrometheusMetrics) IncrementKafkaInFlight() {
+	m.kafkaInFlight.Inc()
+}
+
+func (m *PrometheusMetrics) DecrementKafkaInFlight() {
+	m.kafkaInFlight.Dec()
+}
+
+func (m *PrometheusMetrics) IncrementKafkaFailed() {
+	m.kafkaFailedCounter.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementEventRetried() {
+	m.retriedCounter.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementEventDeadLettered() {
+	m.deadLetterCounter.Inc()
+}
+
+func (m *PrometheusMetrics) SetEventRetryQueueSize(size int) {
+	m.retryQueueSize.Set(float64(size))
+}
+
+func (m *PrometheusMetrics) SetReadModelLag(lag time.Duration) {
+	m.readModelLag.Set(lag.Seconds())
+}
@@ -0,0 +1,83 @@
+package tracer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor starts the server span for every gRPC call. If the caller passed its span context
+// in the metadata, the span continues the caller's trace, so the whole request may be followed in Jaeger.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		span := startServerSpan(ctx, info.FullMethod)
+		defer span.Finish()
+
+		res, err := handler(opentracing.ContextWithSpan(ctx, span), req)
+		markError(span, err)
+
+		return res, err
+	}
+}
+
+// StreamServerInterceptor starts the server span for every streaming gRPC call the same way as
+// UnaryServerInterceptor, the span lasts until the stream is closed.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		span := startServerSpan(stream.Context(), info.FullMethod)
+		defer span.Finish()
+
+		err := handler(srv, &serverStream{ServerStream: stream, ctx: opentracing.ContextWithSpan(stream.Context(), span)})
+		markError(span, err)
+
+		return err
+	}
+}
+
+func startServerSpan(ctx context.Context, method string) opentracing.Span {
+	tracer := opentracing.GlobalTracer()
+
+	var parent opentracing.SpanContext
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// Absent or malformed span context just starts the new trace
+		parent, _ = tracer.Extract(opentracing.TextMap, metadataCarrier(md))
+	}
+
+	return tracer.StartSpan(method, ext.RPCServerOption(parent))
+}
+
+func markError(span opentracing.Span, err error) {
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogKV("error", err.Error())
+	}
+}
+
+// serverStream replaces the context of the wrapped stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier allows to read span context from gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for key, values := range c {
+		for _, value := range values {
+			if err := handler(strings.ToLower(key), value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
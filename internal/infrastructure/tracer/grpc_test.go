@@ -0,0 +1,75 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func useMockTracer(t *testing.T) *mocktracer.MockTracer {
+	previous := opentracing.GlobalTracer()
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+	t.Cleanup(func() { opentracing.SetGlobalTracer(previous) })
+
+	return tracer
+}
+
+func TestUnaryServerInterceptor_ShouldPassSpanToHandler(t *testing.T) {
+	tracer := useMockTracer(t)
+	info := &grpc.UnaryServerInfo{FullMethod: "/ova.service.ServiceAPI/CreateServiceV1"}
+
+	var got opentracing.Span
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		got = opentracing.SpanFromContext(ctx)
+		return nil, nil
+	}
+
+	_, err := UnaryServerInterceptor()(context.Background(), nil, info, handler)
+
+	require.NoError(t, err, "No error should be returned")
+	require.Len(t, tracer.FinishedSpans(), 1, "Span should be finished")
+	assert.Equal(t, tracer.FinishedSpans()[0], got, "Span should be passed to the handler")
+	assert.Equal(t, info.FullMethod, tracer.FinishedSpans()[0].OperationName, "Span should be named after the method")
+}
+
+func TestStreamServerInterceptor_WhenCallerPassedSpan_ShouldContinueItsTrace(t *testing.T) {
+	tracer := useMockTracer(t)
+	parent := tracer.StartSpan("client")
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(parent.Context(), opentracing.TextMap, carrier))
+	stream := &fakeServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.New(carrier))}
+	info := &grpc.StreamServerInfo{FullMethod: "/ova.service.ServiceAPI/ExportServicesV1", IsServerStream: true}
+
+	var got opentracing.Span
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		got = opentracing.SpanFromContext(stream.Context())
+		return fmt.Errorf("client left")
+	}
+
+	err := StreamServerInterceptor()(nil, stream, info, handler)
+
+	require.Error(t, err, "Handler error should be returned")
+	require.Len(t, tracer.FinishedSpans(), 1, "Span should be finished")
+	span := tracer.FinishedSpans()[0]
+	assert.Equal(t, span, got, "Span should be passed to the handler in the stream context")
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).TraceID, span.SpanContext.TraceID,
+		"Span should continue the caller's trace")
+	assert.Equal(t, true, span.Tag("error"), "Span should be marked as failed")
+}
@@ -5,3 +5,4 @@ package internal
 //go:generate mockgen -destination=./mocks/saver_mock.go -package=mocks github.com/ozonva/ova-service-api/internal/saver Saver
 //go:generate mockgen -destination=./mocks/producer_mock.go -package=mocks github.com/ozonva/ova-service-api/internal/infrastructure/kafka Producer
 //go:generate mockgen -destination=./mocks/metrics_mock.go -package=mocks github.com/ozonva/ova-service-api/internal/infrastructure/metrics Metrics
+//go:generate mockgen -destination=./mocks/publisher_mock.go -package=mocks github.com/ozonva/ova-service-api/internal/eventbus Publisher
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -33,6 +34,18 @@ func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
 	return m.recorder
 }
 
+// DecrementKafkaInFlight mocks base method.
+func (m *MockMetrics) DecrementKafkaInFlight() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DecrementKafkaInFlight")
+}
+
+// DecrementKafkaInFlight indicates an expected call of DecrementKafkaInFlight.
+func (mr *MockMetricsMockRecorder) DecrementKafkaInFlight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementKafkaInFlight", reflect.TypeOf((*MockMetrics)(nil).DecrementKafkaInFlight))
+}
+
 // IncrementCreateCounter mocks base method.
 func (m *MockMetrics) IncrementCreateCounter() {
 	m.ctrl.T.Helper()
@@ -45,6 +58,66 @@ func (mr *MockMetricsMockRecorder) IncrementCreateCounter() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementCreateCounter", reflect.TypeOf((*MockMetrics)(nil).IncrementCreateCounter))
 }
 
+// IncrementEventDeadLettered mocks base method.
+func (m *MockMetrics) IncrementEventDeadLettered() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementEventDeadLettered")
+}
+
+// IncrementEventDeadLettered indicates an expected call of IncrementEventDeadLettered.
+func (mr *MockMetricsMockRecorder) IncrementEventDeadLettered() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementEventDeadLettered", reflect.TypeOf((*MockMetrics)(nil).IncrementEventDeadLettered))
+}
+
+// IncrementEventRetried mocks base method.
+func (m *MockMetrics) IncrementEventRetried() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementEventRetried")
+}
+
+// IncrementEventRetried indicates an expected call of IncrementEventRetried.
+func (mr *MockMetricsMockRecorder) IncrementEventRetried() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementEventRetried", reflect.TypeOf((*MockMetrics)(nil).IncrementEventRetried))
+}
+
+// IncrementImportCounter mocks base method.
+func (m *MockMetrics) IncrementImportCounter() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementImportCounter")
+}
+
+// IncrementImportCounter indicates an expected call of IncrementImportCounter.
+func (mr *MockMetricsMockRecorder) IncrementImportCounter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementImportCounter", reflect.TypeOf((*MockMetrics)(nil).IncrementImportCounter))
+}
+
+// IncrementKafkaFailed mocks base method.
+func (m *MockMetrics) IncrementKafkaFailed() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementKafkaFailed")
+}
+
+// IncrementKafkaFailed indicates an expected call of IncrementKafkaFailed.
+func (mr *MockMetricsMockRecorder) IncrementKafkaFailed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementKafkaFailed", reflect.TypeOf((*MockMetrics)(nil).IncrementKafkaFailed))
+}
+
+// IncrementKafkaInFlight mocks base method.
+func (m *MockMetrics) IncrementKafkaInFlight() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementKafkaInFlight")
+}
+
+// IncrementKafkaInFlight indicates an expected call of IncrementKafkaInFlight.
+func (mr *MockMetricsMockRecorder) IncrementKafkaInFlight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementKafkaInFlight", reflect.TypeOf((*MockMetrics)(nil).IncrementKafkaInFlight))
+}
+
 // IncrementMultiCreateCounter mocks base method.
 func (m *MockMetrics) IncrementMultiCreateCounter() {
 	m.ctrl.T.Helper()
@@ -80,3 +153,27 @@ func (mr *MockMetricsMockRecorder) IncrementUpdateCounter() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementUpdateCounter", reflect.TypeOf((*MockMetrics)(nil).IncrementUpdateCounter))
 }
+
+// SetEventRetryQueueSize mocks base method.
+func (m *MockMetrics) SetEventRetryQueueSize(arg0 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEventRetryQueueSize", arg0)
+}
+
+// SetEventRetryQueueSize indicates an expected call of SetEventRetryQueueSize.
+func (mr *MockMetricsMockRecorder) SetEventRetryQueueSize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEventRetryQueueSize", reflect.TypeOf((*MockMetrics)(nil).SetEventRetryQueueSize), arg0)
+}
+
+// SetReadModelLag mocks base method.
+func (m *MockMetrics) SetReadModelLag(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReadModelLag", arg0)
+}
+
+// SetReadModelLag indicates an expected call of SetReadModelLag.
+func (mr *MockMetricsMockRecorder) SetReadModelLag(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadModelLag", reflect.TypeOf((*MockMetrics)(nil).SetReadModelLag), arg0)
+}
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	kafka "github.com/ozonva/ova-service-api/internal/infrastructure/kafka"
 )
 
 // MockProducer is a mock of Producer interface.
@@ -33,8 +34,22 @@ func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockProducer) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockProducerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockProducer)(nil).Close))
+}
+
 // SendMessage mocks base method.
-func (m *MockProducer) SendMessage(arg0 string) error {
+func (m *MockProducer) SendMessage(arg0 kafka.Message) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendMessage", arg0)
 	ret0, _ := ret[0].(error)
@@ -48,7 +63,7 @@ func (mr *MockProducerMockRecorder) SendMessage(arg0 interface{}) *gomock.Call {
 }
 
 // SendMessages mocks base method.
-func (m *MockProducer) SendMessages(arg0 []string) error {
+func (m *MockProducer) SendMessages(arg0 []kafka.Message) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendMessages", arg0)
 	ret0, _ := ret[0].(error)
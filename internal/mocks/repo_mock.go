@@ -10,6 +10,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	models "github.com/ozonva/ova-service-api/internal/models"
+	repo "github.com/ozonva/ova-service-api/internal/repo"
 )
 
 // MockRepo is a mock of Repo interface.
@@ -64,6 +65,20 @@ func (mr *MockRepoMockRecorder) DescribeService(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeService", reflect.TypeOf((*MockRepo)(nil).DescribeService), arg0)
 }
 
+// ExportServices mocks base method.
+func (m *MockRepo) ExportServices(arg0 models.IntervalFilter, arg1, arg2 uint64, arg3 func(models.Service) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportServices", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportServices indicates an expected call of ExportServices.
+func (mr *MockRepoMockRecorder) ExportServices(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportServices", reflect.TypeOf((*MockRepo)(nil).ExportServices), arg0, arg1, arg2, arg3)
+}
+
 // ListServices mocks base method.
 func (m *MockRepo) ListServices(arg0, arg1 uint64) ([]models.Service, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +94,51 @@ func (mr *MockRepoMockRecorder) ListServices(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServices", reflect.TypeOf((*MockRepo)(nil).ListServices), arg0, arg1)
 }
 
+// ListServicesAfter mocks base method.
+func (m *MockRepo) ListServicesAfter(arg0 *repo.ServiceCursor, arg1 uint64) ([]models.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServicesAfter", arg0, arg1)
+	ret0, _ := ret[0].([]models.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServicesAfter indicates an expected call of ListServicesAfter.
+func (mr *MockRepoMockRecorder) ListServicesAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServicesAfter", reflect.TypeOf((*MockRepo)(nil).ListServicesAfter), arg0, arg1)
+}
+
+// ListServicesByInterval mocks base method.
+func (m *MockRepo) ListServicesByInterval(arg0 models.IntervalFilter, arg1, arg2 uint64) ([]models.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServicesByInterval", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServicesByInterval indicates an expected call of ListServicesByInterval.
+func (mr *MockRepoMockRecorder) ListServicesByInterval(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServicesByInterval", reflect.TypeOf((*MockRepo)(nil).ListServicesByInterval), arg0, arg1, arg2)
+}
+
+// ListServicesForReplay mocks base method.
+func (m *MockRepo) ListServicesForReplay(arg0 repo.ReplayFilter) ([]models.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServicesForReplay", arg0)
+	ret0, _ := ret[0].([]models.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServicesForReplay indicates an expected call of ListServicesForReplay.
+func (mr *MockRepoMockRecorder) ListServicesForReplay(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServicesForReplay", reflect.TypeOf((*MockRepo)(nil).ListServicesForReplay), arg0)
+}
+
 // RemoveService mocks base method.
 func (m *MockRepo) RemoveService(arg0 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// IDGenerator generates IDs of new services
+type IDGenerator interface {
+	NewID() (uuid.UUID, error)
+}
+
+// IDGeneratorFunc adapts the function to IDGenerator
+type IDGeneratorFunc func() (uuid.UUID, error)
+
+func (f IDGeneratorFunc) NewID() (uuid.UUID, error) {
+	return f()
+}
+
+var (
+	// UUIDv7 IDs start with the creation time in milliseconds, so new rows are appended to the end of
+	// the primary key index and IDs sort in the creation order. Services get them by default.
+	UUIDv7 IDGenerator = IDGeneratorFunc(uuid.NewV7)
+	// UUIDv4 IDs are random
+	UUIDv4 IDGenerator = IDGeneratorFunc(uuid.NewRandom)
+)
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// IntervalFilter selects services by their time. The service takes the [start, end) range, the service without
+// the end takes its start instant only, services without time never match.
+type IntervalFilter struct {
+	// From and To bound the [From, To) range, zero values leave it unbounded
+	From time.Time
+	To   time.Time
+	// Within selects services which take place entirely inside the range, overlapping ones are selected otherwise
+	Within bool
+	// ServiceName and ServiceAddress select services with the name and at the address, empty values select any
+	ServiceName    string
+	ServiceAddress string
+}
+
+func (filter IntervalFilter) Validate() error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return fmt.Errorf("end of the interval must be after its start")
+	}
+
+	return nil
+}
+
+// Matches reports whether the service time matches the filter
+func (filter IntervalFilter) Matches(service *Service) bool {
+	if service.WhenUTC == nil {
+		return false
+	}
+	if len(filter.ServiceName) > 0 && service.ServiceName != filter.ServiceName {
+		return false
+	}
+	if len(filter.ServiceAddress) > 0 && service.ServiceAddress != filter.ServiceAddress {
+		return false
+	}
+
+	start := *service.WhenUTC
+	end := start
+	if service.EndUTC != nil {
+		end = *service.EndUTC
+	}
+
+	if filter.Within {
+		return (filter.From.IsZero() || !start.Before(filter.From)) &&
+			(filter.To.IsZero() || !end.After(filter.To))
+	}
+
+	if !filter.To.IsZero() && !start.Before(filter.To) {
+		return false
+	}
+	if filter.From.IsZero() {
+		return true
+	}
+
+	// The instant overlaps the range it is in, the range overlaps another one if it ends after its start
+	if service.EndUTC == nil {
+		return !start.Before(filter.From)
+	}
+	return end.After(filter.From)
+}
+
+// Overlaps reports whether times of services overlap. The service with the end takes the [start, end) range and
+// the one without it takes its start instant, services without time never overlap.
+func (service *Service) Overlaps(other *Service) bool {
+	if service.WhenUTC == nil || other.WhenUTC == nil {
+		return false
+	}
+
+	return !endsBefore(service, other) && !endsBefore(other, service)
+}
+
+// endsBefore reports whether the service is over by the start of the other one
+func endsBefore(service *Service, other *Service) bool {
+	if service.EndUTC == nil {
+		return service.WhenUTC.Before(*other.WhenUTC)
+	}
+	return !service.EndUTC.After(*other.WhenUTC)
+}
@@ -1,36 +1,52 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrInvalidEnd is wrapped by errors returned for the end of the service inconsistent with its start
+var ErrInvalidEnd = errors.New("invalid end of the service")
+
 type Service struct {
 	ID             uuid.UUID
 	UserID         uint64
 	Description    string
 	ServiceName    string
 	ServiceAddress string
-	WhenLocal      *time.Time
-	WhenUTC        *time.Time
+	// TimeZone is the IANA name of the zone of the service address, e.g. "Europe/Moscow"
+	TimeZone string
+	// WhenLocal is WhenUTC in TimeZone, both are set by the calendar methods only
+	WhenLocal *time.Time
+	WhenUTC   *time.Time
+	// EndLocal and EndUTC are the end of the service, the service is the single instant if they are nil
+	EndLocal *time.Time
+	EndUTC   *time.Time
 }
 
-func NewService(userID uint64, description string, serviceName string, serviceAddress string, when *time.Time) (*Service, error) {
+// NewService validates the new service and gives it the ID of the generator
+func NewService(ids IDGenerator, userID uint64, description string, serviceName string, serviceAddress string, when *time.Time, end *time.Time, timeZone string) (*Service, error) {
 	if userID == 0 {
 		return nil, fmt.Errorf("can't create service entry for non-existing user")
 	}
 
+	id, err := ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("can't generate service ID: %w", err)
+	}
+
 	service := &Service{
-		ID:             uuid.New(),
+		ID:             id,
 		UserID:         userID,
 		Description:    description,
 		ServiceName:    serviceName,
 		ServiceAddress: serviceAddress,
 	}
 
-	err := service.UpdateCalendar(when)
+	err = service.UpdateCalendar(when, end, timeZone)
 
 	if err != nil {
 		return nil, err
@@ -48,24 +64,62 @@ func (service *Service) UpdateService(serviceName string, serviceAddress string)
 	service.ServiceAddress = serviceAddress
 }
 
-func (service *Service) UpdateCalendar(when *time.Time) error {
-	if when == nil {
-		service.WhenLocal = nil
-		service.WhenUTC = nil
-		return nil
+// UpdateCalendar sets the start and the optional end of the service in the time zone,
+// empty zone is DefaultTimeZone
+func (service *Service) UpdateCalendar(when *time.Time, end *time.Time, timeZone string) error {
+	if when != nil && !when.After(time.Now()) {
+		return fmt.Errorf("can't update calendar to the date in the past")
 	}
 
-	if !when.After(time.Now()) {
-		return fmt.Errorf("can't update calendar to the date in the past")
+	return service.RestoreCalendar(when, end, timeZone)
+}
+
+// RestoreCalendar sets the time of the stored service, the time in the past is allowed unlike UpdateCalendar
+func (service *Service) RestoreCalendar(when *time.Time, end *time.Time, timeZone string) error {
+	if len(timeZone) == 0 {
+		timeZone = DefaultTimeZone
 	}
 
-	service.WhenLocal = when
-	utc := when.UTC()
-	service.WhenUTC = &utc
+	location, err := LoadTimeZone(timeZone)
+	if err != nil {
+		return err
+	}
+
+	if end != nil {
+		if when == nil {
+			return fmt.Errorf("%w: can't set it without the start", ErrInvalidEnd)
+		}
+		if !end.After(*when) {
+			return fmt.Errorf("%w: it must be after the start", ErrInvalidEnd)
+		}
+	}
+
+	service.TimeZone = timeZone
+	service.WhenLocal, service.WhenUTC = inLocation(when, location)
+	service.EndLocal, service.EndUTC = inLocation(end, location)
 
 	return nil
 }
 
+// Duration is zero for services without the end
+func (service *Service) Duration() time.Duration {
+	if service.WhenUTC == nil || service.EndUTC == nil {
+		return 0
+	}
+
+	return service.EndUTC.Sub(*service.WhenUTC)
+}
+
+func inLocation(t *time.Time, location *time.Location) (local *time.Time, utc *time.Time) {
+	if t == nil {
+		return nil, nil
+	}
+
+	inLocation := t.In(location)
+	inUTC := t.UTC()
+	return &inLocation, &inUTC
+}
+
 func (service *Service) String() string {
 	var (
 		local time.Time
@@ -86,7 +140,10 @@ func (service *Service) String() string {
 	Description: 	%s
 	ServiceName: 	%s
 	ServiceAddress: %s
+	TimeZone: 		%s
 	WhenLocal: 		%v
 	WhenUTC: 		%v
-`, service.ID, service.UserID, service.Description, service.ServiceName, service.ServiceAddress, local, utc)
+	Duration: 		%v
+`, service.ID, service.UserID, service.Description, service.ServiceName, service.ServiceAddress, service.TimeZone, local, utc,
+		service.Duration())
 }
@@ -17,7 +17,7 @@ var (
 )
 
 func TestService_WhenValidArguments_ShouldCreateNewService(t *testing.T) {
-	got, err := NewService(1, "description", "name", "address", &tomorrowLocal)
+	got, err := NewService(UUIDv7, 1, "description", "name", "address", &tomorrowLocal, nil, "")
 
 	require.NoError(t, err, "No error should be returned for valid arguments")
 	require.NotNil(t, got, "Valid Service structure should be created")
@@ -27,7 +27,7 @@ func TestService_WhenValidArguments_ShouldCreateNewService(t *testing.T) {
 }
 
 func TestService_WhenEmptyCalendar_ShouldCreateNewServiceWithEmptyCalendar(t *testing.T) {
-	got, err := NewService(1, "description", "name", "address", nil)
+	got, err := NewService(UUIDv7, 1, "description", "name", "address", nil, nil, "")
 
 	require.NoError(t, err, "No error should be returned for valid arguments")
 	require.NotNil(t, got, "Valid Service structure should be created")
@@ -36,7 +36,7 @@ func TestService_WhenEmptyCalendar_ShouldCreateNewServiceWithEmptyCalendar(t *te
 }
 
 func TestService_WhenEmptyUserID_ShouldReturnError(t *testing.T) {
-	_, err := NewService(0, "description", "name", "address", nil)
+	_, err := NewService(UUIDv7, 0, "description", "name", "address", nil, nil, "")
 
 	require.Errorf(t, err, "Error should be returned")
 	assert.Equal(t, "can't create service entry for non-existing user",
@@ -48,7 +48,7 @@ func TestService_WhenProvidedDateInThePast_ShouldReturnError(t *testing.T) {
 		ID: uuid.New(),
 	}
 
-	err := got.UpdateCalendar(&yesterdayLocal)
+	err := got.UpdateCalendar(&yesterdayLocal, nil, "")
 
 	require.Errorf(t, err, "Error should be returned")
 	assert.Equal(t, "can't update calendar to the date in the past",
@@ -62,6 +62,7 @@ func TestService_ShouldBeAbleToPrintItself(t *testing.T) {
 		Description:    "TO 123",
 		ServiceName:    "Best TO ever",
 		ServiceAddress: "In the middle of nowhere",
+		TimeZone:       "Europe/Moscow",
 		WhenLocal:      &tomorrowLocal,
 		WhenUTC:        &tomorrowUTC,
 	}
@@ -74,8 +75,206 @@ func TestService_ShouldBeAbleToPrintItself(t *testing.T) {
 	Description: 	TO 123
 	ServiceName: 	Best TO ever
 	ServiceAddress: In the middle of nowhere
+	TimeZone: 		Europe/Moscow
 	WhenLocal: 		%v
 	WhenUTC: 		%v
+	Duration: 		0s
 `, service.WhenLocal, service.WhenUTC)
 	assert.Equal(t, expected, got, "Service printed itself in the wrong format")
 }
+
+func TestService_WhenTimeZoneIsSet_ShouldComputeLocalTimeInIt(t *testing.T) {
+	when := time.Now().AddDate(1, 0, 0).UTC()
+
+	got, err := NewService(UUIDv7, 1, "description", "name", "address", &when, nil, "Asia/Tokyo")
+
+	require.NoError(t, err, "No error should be returned for valid arguments")
+	assert.Equal(t, "Asia/Tokyo", got.TimeZone, "Time zone should be kept")
+	assert.Equal(t, "Asia/Tokyo", got.WhenLocal.Location().String(), "Local date should be in the service zone")
+	assert.True(t, when.Equal(*got.WhenLocal), "Local date should be the same instant")
+	assert.True(t, when.Equal(*got.WhenUTC), "UTC date should be set properly")
+}
+
+func TestService_WhenTimeZoneIsEmpty_ShouldUseDefaultTimeZone(t *testing.T) {
+	got, err := NewService(UUIDv7, 1, "description", "name", "address", &tomorrowLocal, nil, "")
+
+	require.NoError(t, err, "No error should be returned for valid arguments")
+	assert.Equal(t, DefaultTimeZone, got.TimeZone, "Default time zone should be set")
+	assert.Equal(t, time.UTC.String(), got.WhenLocal.Location().String(), "Local date should be in UTC")
+}
+
+func TestService_WhenTimeZoneIsUnknown_ShouldReturnError(t *testing.T) {
+	for _, zone := range []string{"Mars/Olympus_Mons", "Local", "+03:00"} {
+		_, err := NewService(UUIDv7, 1, "description", "name", "address", &tomorrowLocal, nil, zone)
+
+		assert.ErrorIs(t, err, ErrUnknownTimeZone, "Zone %q should be rejected", zone)
+	}
+}
+
+func TestLocalTime_ShouldResolveWallClockAcrossDSTTransitions(t *testing.T) {
+	newYork, err := LoadTimeZone("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		wall     time.Time
+		expected string
+	}{
+		{"winter time", time.Date(2030, 1, 15, 10, 0, 0, 0, time.UTC), "2030-01-15T10:00:00-05:00"},
+		{"summer time", time.Date(2030, 7, 15, 10, 0, 0, 0, time.UTC), "2030-07-15T10:00:00-04:00"},
+		{"repeated time gets the earlier instant", time.Date(2030, 11, 3, 1, 30, 0, 0, time.UTC), "2030-11-03T01:30:00-04:00"},
+		{"zone of the wall clock is ignored", time.Date(2030, 1, 15, 10, 0, 0, 0, time.FixedZone("", 3600)), "2030-01-15T10:00:00-05:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LocalTime(tt.wall, newYork)
+
+			require.NoError(t, err, "Local time should be resolved")
+			assert.Equal(t, tt.expected, got.Format(time.RFC3339))
+		})
+	}
+}
+
+func TestLocalTime_WhenTimeIsSkippedByDSTTransition_ShouldReturnError(t *testing.T) {
+	newYork, err := LoadTimeZone("America/New_York")
+	require.NoError(t, err)
+
+	_, err = LocalTime(time.Date(2030, 3, 10, 2, 30, 0, 0, time.UTC), newYork)
+
+	assert.ErrorIs(t, err, ErrNonexistentLocalTime)
+}
+
+func TestService_WhenEndIsSet_ShouldComputeDurationInZone(t *testing.T) {
+	when := time.Now().AddDate(1, 0, 0).UTC()
+	end := when.Add(90 * time.Minute)
+
+	got, err := NewService(UUIDv7, 1, "description", "name", "address", &when, &end, "Asia/Tokyo")
+
+	require.NoError(t, err, "No error should be returned for valid arguments")
+	assert.Equal(t, 90*time.Minute, got.Duration(), "Duration should be the time between start and end")
+	assert.Equal(t, "Asia/Tokyo", got.EndLocal.Location().String(), "End should be in the zone of the service")
+	assert.True(t, end.Equal(*got.EndUTC), "End should be kept")
+}
+
+func TestService_WhenEndIsNotAfterStart_ShouldReturnErrInvalidEnd(t *testing.T) {
+	for _, end := range []time.Time{tomorrowLocal, tomorrowLocal.Add(-time.Hour)} {
+		end := end
+		_, err := NewService(UUIDv7, 1, "description", "name", "address", &tomorrowLocal, &end, "")
+
+		assert.ErrorIs(t, err, ErrInvalidEnd, "End %v should be rejected", end)
+	}
+}
+
+func TestService_WhenEndIsSetWithoutStart_ShouldReturnErrInvalidEnd(t *testing.T) {
+	_, err := NewService(UUIDv7, 1, "description", "name", "address", nil, &tomorrowLocal, "")
+
+	assert.ErrorIs(t, err, ErrInvalidEnd)
+}
+
+func TestIntervalFilter_ShouldMatchServicesByTheirTime(t *testing.T) {
+	at := func(hour int) *time.Time {
+		t := time.Date(2040, 1, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	service := func(when, end *time.Time) *Service {
+		return &Service{WhenUTC: when, EndUTC: end}
+	}
+	overlapping := IntervalFilter{From: *at(10), To: *at(12)}
+	within := IntervalFilter{From: *at(10), To: *at(12), Within: true}
+
+	cases := []struct {
+		name    string
+		service *Service
+		filter  IntervalFilter
+		matches bool
+	}{
+		{"without time", service(nil, nil), IntervalFilter{}, false},
+		{"unbounded", service(at(1), nil), IntervalFilter{}, true},
+		{"instant inside", service(at(11), nil), overlapping, true},
+		{"instant at the start", service(at(10), nil), overlapping, true},
+		{"instant at the end", service(at(12), nil), overlapping, false},
+		{"range over the start", service(at(9), at(11)), overlapping, true},
+		{"range ending at the start", service(at(9), at(10)), overlapping, false},
+		{"range over the end", service(at(11), at(13)), overlapping, true},
+		{"range around", service(at(9), at(13)), overlapping, true},
+		{"range inside", service(at(10), at(12)), within, true},
+		{"range over the end within", service(at(11), at(13)), within, false},
+		{"range over the start within", service(at(9), at(11)), within, false},
+		{"instant at the end within", service(at(12), nil), within, true},
+		{"open start", service(at(1), at(11)), IntervalFilter{To: *at(12), Within: true}, true},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.matches, c.filter.Matches(c.service), c.name)
+	}
+}
+
+func TestService_ShouldOverlapServicesSharingTime(t *testing.T) {
+	at := func(hour int) *time.Time {
+		t := time.Date(2040, 1, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	service := &Service{WhenUTC: at(10), EndUTC: at(12)}
+
+	assert.True(t, service.Overlaps(&Service{WhenUTC: at(11), EndUTC: at(13)}), "Crossing range should overlap")
+	assert.True(t, service.Overlaps(&Service{WhenUTC: at(10)}), "Instant at the start should overlap")
+	assert.False(t, service.Overlaps(&Service{WhenUTC: at(12)}), "Instant at the end should not overlap")
+	assert.False(t, service.Overlaps(&Service{WhenUTC: at(8), EndUTC: at(10)}), "Adjacent range should not overlap")
+	assert.True(t, (&Service{WhenUTC: at(9)}).Overlaps(&Service{WhenUTC: at(9)}), "Equal instants should overlap")
+	assert.False(t, service.Overlaps(&Service{}), "Service without time should not overlap")
+}
+
+func TestIntervalFilter_WhenToIsNotAfterFrom_ShouldBeInvalid(t *testing.T) {
+	from := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Error(t, IntervalFilter{From: from, To: from}.Validate())
+	assert.NoError(t, IntervalFilter{From: from}.Validate(), "Unbounded interval should be valid")
+}
+
+func TestService_ShouldGenerateTimeOrderedIDs(t *testing.T) {
+	first, err := NewService(UUIDv7, 1, "description", "name", "address", nil, nil, "")
+	require.NoError(t, err, "No error should be returned for valid arguments")
+	time.Sleep(2 * time.Millisecond)
+	second, err := NewService(UUIDv7, 1, "description", "name", "address", nil, nil, "")
+	require.NoError(t, err, "No error should be returned for valid arguments")
+
+	assert.Equal(t, uuid.Version(7), first.ID.Version(), "UUIDv7 should be generated by default")
+	assert.Less(t, first.ID.String(), second.ID.String(), "Later service should get the greater ID")
+}
+
+func TestService_ShouldUseIDOfGenerator(t *testing.T) {
+	t.Parallel()
+	id := uuid.MustParse("85ae1287-9dc8-4e4a-9214-35c3debbdfba")
+	generator := IDGeneratorFunc(func() (uuid.UUID, error) {
+		return id, nil
+	})
+
+	got, err := NewService(generator, 1, "description", "name", "address", nil, nil, "")
+
+	require.NoError(t, err, "No error should be returned for valid arguments")
+	assert.Equal(t, id, got.ID, "ID of the generator should be used")
+}
+
+func TestService_WhenIDGeneratorFails_ShouldReturnError(t *testing.T) {
+	t.Parallel()
+	generator := IDGeneratorFunc(func() (uuid.UUID, error) {
+		return uuid.Nil, fmt.Errorf("entropy is exhausted")
+	})
+
+	_, err := NewService(generator, 1, "description", "name", "address", nil, nil, "")
+
+	assert.EqualError(t, err, "can't generate service ID: entropy is exhausted")
+}
+
+func BenchmarkIDGenerator_UUIDv4(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = UUIDv4.NewID()
+	}
+}
+
+func BenchmarkIDGenerator_UUIDv7(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = UUIDv7.NewID()
+	}
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeZone is the zone of services created without one
+const DefaultTimeZone = "UTC"
+
+var (
+	// ErrUnknownTimeZone is wrapped by errors returned for names missing in the IANA time zone database
+	ErrUnknownTimeZone = errors.New("unknown time zone")
+	// ErrNonexistentLocalTime is wrapped by errors returned for local times skipped by the DST transition
+	ErrNonexistentLocalTime = errors.New("local time doesn't exist in the time zone")
+)
+
+// locations caches loaded zones, time.LoadLocation reads the zone database on every call
+var locations sync.Map
+
+// LoadTimeZone returns the location of the IANA zone name, e.g. "Europe/Moscow". Empty name is DefaultTimeZone.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if len(name) == 0 {
+		name = DefaultTimeZone
+	}
+
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+
+	// "Local" is the zone of the server, exactly what the zone of the service must not depend on
+	if name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTimeZone, name)
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTimeZone, name)
+	}
+
+	locations.Store(name, location)
+	return location, nil
+}
+
+// LocalTime returns the instant of the wall clock time in the location, the zone of wall is ignored.
+// The time skipped by the DST transition is rejected with ErrNonexistentLocalTime, the earlier instant is returned
+// for the time repeated by the transition, i.e. the time before the clocks are turned back.
+func LocalTime(wall time.Time, location *time.Location) (time.Time, error) {
+	wallUTC := wallClock(wall)
+
+	// Offsets can differ only if there is a transition around the time, transitions are never closer than a day
+	var (
+		resolved time.Time
+		found    bool
+	)
+	for _, probe := range []time.Time{wallUTC.Add(-24 * time.Hour), wallUTC.Add(24 * time.Hour)} {
+		_, offset := probe.In(location).Zone()
+		candidate := wallUTC.Add(-time.Duration(offset) * time.Second).In(location)
+
+		if !wallClock(candidate).Equal(wallUTC) {
+			continue
+		}
+		if !found || candidate.Before(resolved) {
+			resolved, found = candidate, true
+		}
+	}
+
+	if !found {
+		return time.Time{}, fmt.Errorf("%w: %s in %s", ErrNonexistentLocalTime, wallUTC.Format("2006-01-02T15:04:05"), location)
+	}
+
+	return resolved, nil
+}
+
+// wallClock returns the wall clock time of t in UTC
+func wallClock(t time.Time) time.Time {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return time.Date(year, month, day, hour, minute, second, t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,172 @@
+package projection
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// MemoryStore keeps the read model in process. It is lost on restart, so it has to be rebuilt on start,
+// and every service instance has to consume all events with its own consumer group.
+type MemoryStore struct {
+	sync.RWMutex
+	services map[uuid.UUID]models.Service
+	counters map[uint64]UserCounters
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		services: make(map[uuid.UUID]models.Service),
+		counters: make(map[uint64]UserCounters),
+	}
+}
+
+func (s *MemoryStore) Upsert(service models.Service) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if previous, ok := s.services[service.ID]; ok {
+		s.count(previous, -1)
+	}
+
+	s.services[service.ID] = service
+	s.count(service, 1)
+	return nil
+}
+
+func (s *MemoryStore) Remove(serviceID uuid.UUID) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if previous, ok := s.services[serviceID]; ok {
+		s.count(previous, -1)
+		delete(s.services, serviceID)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Reset() error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.services = make(map[uuid.UUID]models.Service)
+	s.counters = make(map[uint64]UserCounters)
+	return nil
+}
+
+// ListServices orders services the same way as the primary repo: by time descending, services without time first
+func (s *MemoryStore) ListServices(limit uint64, offset uint64) ([]models.Service, error) {
+	return s.listServices(func(*models.Service) bool { return true }, limit, offset)
+}
+
+func (s *MemoryStore) ListServicesByInterval(filter models.IntervalFilter, limit uint64, offset uint64) ([]models.Service, error) {
+	return s.listServices(filter.Matches, limit, offset)
+}
+
+func (s *MemoryStore) listServices(matches func(service *models.Service) bool, limit uint64, offset uint64) ([]models.Service, error) {
+	s.RLock()
+	services := make([]models.Service, 0, len(s.services))
+	for _, service := range s.services {
+		if matches(&service) {
+			services = append(services, service)
+		}
+	}
+	s.RUnlock()
+
+	sort.Slice(services, func(i, j int) bool {
+		left, right := services[i].WhenUTC, services[j].WhenUTC
+		switch {
+		case left == nil && right == nil:
+		case left == nil:
+			return true
+		case right == nil:
+			return false
+		case !left.Equal(*right):
+			return left.After(*right)
+		}
+
+		return bytes.Compare(services[i].ID[:], services[j].ID[:]) < 0
+	})
+
+	if offset >= uint64(len(services)) {
+		return []models.Service{}, nil
+	}
+	services = services[offset:]
+
+	if limit < uint64(len(services)) {
+		services = services[:limit]
+	}
+
+	return services, nil
+}
+
+func (s *MemoryStore) DescribeService(serviceID uuid.UUID) (*models.Service, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	service, ok := s.services[serviceID]
+	if !ok {
+		return nil, notFoundError(serviceID)
+	}
+
+	return &service, nil
+}
+
+func (s *MemoryStore) UserCalendar(userID uint64, from, to time.Time) ([]models.Service, error) {
+	filter := models.IntervalFilter{From: from, To: to}
+
+	s.RLock()
+	services := make([]models.Service, 0)
+	for _, service := range s.services {
+		if service.UserID == userID && filter.Matches(&service) {
+			services = append(services, service)
+		}
+	}
+	s.RUnlock()
+
+	sort.Slice(services, func(i, j int) bool {
+		if !services[i].WhenUTC.Equal(*services[j].WhenUTC) {
+			return services[i].WhenUTC.Before(*services[j].WhenUTC)
+		}
+
+		return bytes.Compare(services[i].ID[:], services[j].ID[:]) < 0
+	})
+
+	return services, nil
+}
+
+func (s *MemoryStore) UserCounters(userID uint64) (UserCounters, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	counters, ok := s.counters[userID]
+	if !ok {
+		return UserCounters{UserID: userID}, nil
+	}
+
+	return counters, nil
+}
+
+// count adds (delta = 1) or subtracts (delta = -1) the service from counters of its user
+func (s *MemoryStore) count(service models.Service, delta int) {
+	counters := s.counters[service.UserID]
+	counters.UserID = service.UserID
+	counters.Total = uint64(int(counters.Total) + delta)
+
+	if service.WhenUTC != nil {
+		counters.Scheduled = uint64(int(counters.Scheduled) + delta)
+	}
+
+	if counters.Total == 0 {
+		delete(s.counters, service.UserID)
+		return
+	}
+
+	s.counters[service.UserID] = counters
+}
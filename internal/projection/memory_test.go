@@ -0,0 +1,113 @@
+package projection_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/projection"
+)
+
+func newScheduledService(userID uint64, when time.Time) models.Service {
+	return models.Service{ID: uuid.New(), UserID: userID, WhenLocal: &when, WhenUTC: &when}
+}
+
+func TestMemoryStore_WhenServiceMovesToAnotherUser_ShouldUpdateCountersOfBoth(t *testing.T) {
+	store := projection.NewMemoryStore()
+	service := newScheduledService(1, time.Now().UTC())
+	require.NoError(t, store.Upsert(service))
+	require.NoError(t, store.Upsert(models.Service{ID: uuid.New(), UserID: 1}))
+
+	service.UserID = 2
+	require.NoError(t, store.Upsert(service))
+
+	first, err := store.UserCounters(1)
+	require.NoError(t, err)
+	assert.Equal(t, projection.UserCounters{UserID: 1, Total: 1, Scheduled: 0}, first)
+
+	second, err := store.UserCounters(2)
+	require.NoError(t, err)
+	assert.Equal(t, projection.UserCounters{UserID: 2, Total: 1, Scheduled: 1}, second)
+}
+
+func TestMemoryStore_WhenServiceIsRemovedTwice_ShouldCountItOnce(t *testing.T) {
+	store := projection.NewMemoryStore()
+	service := newScheduledService(1, time.Now().UTC())
+	require.NoError(t, store.Upsert(service))
+	require.NoError(t, store.Upsert(service))
+
+	require.NoError(t, store.Remove(service.ID))
+	require.NoError(t, store.Remove(service.ID))
+
+	counters, err := store.UserCounters(1)
+	require.NoError(t, err)
+	assert.Equal(t, projection.UserCounters{UserID: 1}, counters)
+
+	_, err = store.DescribeService(service.ID)
+	assert.Error(t, err, "Removed service should not be found")
+}
+
+func TestMemoryStore_WhenCalendarIsRequested_ShouldReturnUserServicesInRangeOrderedByTime(t *testing.T) {
+	store := projection.NewMemoryStore()
+	from := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	late := newScheduledService(1, from.Add(48*time.Hour))
+	early := newScheduledService(1, from)
+	outOfRange := newScheduledService(1, from.Add(30*24*time.Hour))
+	otherUser := newScheduledService(2, from)
+
+	for _, service := range []models.Service{late, early, outOfRange, otherUser, {ID: uuid.New(), UserID: 1}} {
+		require.NoError(t, store.Upsert(service))
+	}
+
+	calendar, err := store.UserCalendar(1, from, from.Add(7*24*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.Service{early, late}, calendar)
+}
+
+func TestMemoryStore_WhenListed_ShouldOrderLikePrimaryRepoAndPage(t *testing.T) {
+	store := projection.NewMemoryStore()
+	now := time.Now().UTC()
+	unscheduled := models.Service{ID: uuid.New(), UserID: 1}
+	early := newScheduledService(1, now)
+	late := newScheduledService(1, now.Add(time.Hour))
+
+	for _, service := range []models.Service{early, unscheduled, late} {
+		require.NoError(t, store.Upsert(service))
+	}
+
+	all, err := store.ListServices(^uint64(0), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Service{unscheduled, late, early}, all)
+
+	page, err := store.ListServices(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Service{late}, page)
+
+	empty, err := store.ListServices(10, 5)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestMemoryStore_WhenServiceRunsIntoCalendarRange_ShouldReturnIt(t *testing.T) {
+	store := projection.NewMemoryStore()
+	from := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	running := newScheduledService(1, from.Add(-time.Hour))
+	end := from.Add(time.Hour)
+	running.EndUTC, running.EndLocal = &end, &end
+	over := newScheduledService(1, from.Add(-2*time.Hour))
+	over.EndUTC, over.EndLocal = &from, &from
+
+	for _, service := range []models.Service{running, over} {
+		require.NoError(t, store.Upsert(service))
+	}
+
+	calendar, err := store.UserCalendar(1, from, from.Add(24*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.Service{running}, calendar, "Service ending at the range start should not be returned")
+}
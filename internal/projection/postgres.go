@@ -0,0 +1,366 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// schema is created on start rather than with migrations: the read model is disposable and can be
+// rebuilt from the primary database at any moment, so it may live in a separate database.
+const schema = `
+CREATE TABLE IF NOT EXISTS read_services
+(
+  id UUID PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  description VARCHAR(4000) NULL,
+  service_name VARCHAR(1000) NULL,
+  service_address VARCHAR(1000) NULL,
+  time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  when_utc TIMESTAMPTZ NULL,
+  end_utc TIMESTAMPTZ NULL
+);
+
+-- Read models of the previous schema kept the wall clock of the server zone in when_local,
+-- they are upgraded the same way as the primary table by migrations/00004_service_time_zones.sql
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.columns
+             WHERE table_schema = current_schema() AND table_name = 'read_services' AND column_name = 'when_local') THEN
+    ALTER TABLE read_services ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+    ALTER TABLE read_services ALTER COLUMN when_utc TYPE TIMESTAMPTZ USING when_utc AT TIME ZONE 'UTC';
+    ALTER TABLE read_services DROP COLUMN when_local;
+  END IF;
+END $$;
+
+ALTER TABLE read_services ADD COLUMN IF NOT EXISTS end_utc TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS read_services_user_calendar_idx ON read_services (user_id, when_utc);
+
+CREATE TABLE IF NOT EXISTS read_user_counters
+(
+  user_id BIGINT PRIMARY KEY,
+  total BIGINT NOT NULL,
+  scheduled BIGINT NOT NULL
+);`
+
+const selectColumns = `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
+			FROM read_services`
+
+type PostgresStore struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		log.Err(err).Msg("Can't load pgx driver")
+		return nil, err
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		log.Err(err).Msg("Failed to connect to read model database")
+		return nil, err
+	}
+
+	if _, err = db.ExecContext(ctx, schema); err != nil {
+		log.Err(err).Msg("Failed to create read model schema")
+		return nil, err
+	}
+
+	return &PostgresStore{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+func (s *PostgresStore) Upsert(service models.Service) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		previousUserID, err := s.lockService(tx, service.ID, service.UserID)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT INTO read_services (id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (id) DO UPDATE
+			SET user_id = EXCLUDED.user_id,
+			    description = EXCLUDED.description,
+			    service_name = EXCLUDED.service_name,
+			    service_address = EXCLUDED.service_address,
+			    time_zone = EXCLUDED.time_zone,
+			    when_utc = EXCLUDED.when_utc,
+			    end_utc = EXCLUDED.end_utc`
+
+		_, err = tx.ExecContext(s.ctx, query, service.ID, service.UserID, service.Description, service.ServiceName,
+			service.ServiceAddress, service.TimeZone, service.WhenUTC, service.EndUTC)
+		if err != nil {
+			return err
+		}
+
+		return s.recount(tx, previousUserID, service.UserID)
+	})
+}
+
+func (s *PostgresStore) Remove(serviceID uuid.UUID) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		userID, err := s.lockService(tx, serviceID, 0)
+		if err != nil || userID == 0 {
+			return err
+		}
+
+		if _, err = tx.ExecContext(s.ctx, `DELETE FROM read_services WHERE id = $1`, serviceID); err != nil {
+			return err
+		}
+
+		return s.recount(tx, userID)
+	})
+}
+
+func (s *PostgresStore) Reset() error {
+	_, err := s.db.ExecContext(s.ctx, `TRUNCATE read_services, read_user_counters`)
+	return err
+}
+
+func (s *PostgresStore) ListServices(limit uint64, offset uint64) ([]models.Service, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+
+	// The same hack as in the primary repo: max limit lists all services
+	if limit < ^uint64(0) {
+		rows, err = s.db.QueryContext(s.ctx, selectColumns+` ORDER BY when_utc DESC, id LIMIT $1 OFFSET $2`, limit, offset)
+	} else {
+		rows, err = s.db.QueryContext(s.ctx, selectColumns+` ORDER BY when_utc DESC, id`)
+	}
+
+	if err != nil {
+		log.Err(err).Msg("Error occurred during read model query execution")
+		return nil, err
+	}
+
+	return scanServices(rows)
+}
+
+// ListServicesByInterval matches services the same way as models.IntervalFilter.Matches
+func (s *PostgresStore) ListServicesByInterval(filter models.IntervalFilter, limit uint64, offset uint64) ([]models.Service, error) {
+	query := selectColumns + ` WHERE when_utc IS NOT NULL`
+	args := make([]interface{}, 0, 6)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.ServiceName) > 0 {
+		query += ` AND service_name = ` + arg(filter.ServiceName)
+	}
+	if len(filter.ServiceAddress) > 0 {
+		query += ` AND service_address = ` + arg(filter.ServiceAddress)
+	}
+
+	switch {
+	case filter.Within:
+		if !filter.From.IsZero() {
+			query += ` AND when_utc >= ` + arg(filter.From.UTC())
+		}
+		if !filter.To.IsZero() {
+			query += ` AND COALESCE(end_utc, when_utc) <= ` + arg(filter.To.UTC())
+		}
+	default:
+		if !filter.To.IsZero() {
+			query += ` AND when_utc < ` + arg(filter.To.UTC())
+		}
+		if !filter.From.IsZero() {
+			from := arg(filter.From.UTC())
+			query += ` AND (end_utc > ` + from + ` OR (end_utc IS NULL AND when_utc >= ` + from + `))`
+		}
+	}
+
+	query += ` ORDER BY when_utc DESC, id`
+	if limit < ^uint64(0) {
+		query += ` LIMIT ` + arg(limit) + ` OFFSET ` + arg(offset)
+	}
+
+	rows, err := s.db.QueryContext(s.ctx, query, args...)
+	if err != nil {
+		log.Err(err).Msg("Error occurred during read model query execution")
+		return nil, err
+	}
+
+	return scanServices(rows)
+}
+
+func (s *PostgresStore) DescribeService(serviceID uuid.UUID) (*models.Service, error) {
+	rows, err := s.db.QueryContext(s.ctx, selectColumns+` WHERE id = $1`, serviceID)
+	if err != nil {
+		log.Err(err).Msg("Error occurred during read model query execution")
+		return nil, err
+	}
+
+	services, err := scanServices(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, notFoundError(serviceID)
+	}
+
+	return &services[0], nil
+}
+
+// UserCalendar overlaps services with the range the same way as ListServicesByInterval
+func (s *PostgresStore) UserCalendar(userID uint64, from, to time.Time) ([]models.Service, error) {
+	query := selectColumns + ` WHERE user_id = $1 AND when_utc < $3
+		AND (end_utc > $2 OR (end_utc IS NULL AND when_utc >= $2))
+		ORDER BY when_utc, id`
+
+	rows, err := s.db.QueryContext(s.ctx, query, userID, from.UTC(), to.UTC())
+	if err != nil {
+		log.Err(err).Msg("Error occurred during read model query execution")
+		return nil, err
+	}
+
+	return scanServices(rows)
+}
+
+func (s *PostgresStore) UserCounters(userID uint64) (UserCounters, error) {
+	counters := UserCounters{UserID: userID}
+
+	row := s.db.QueryRowContext(s.ctx, `SELECT total, scheduled FROM read_user_counters WHERE user_id = $1`, userID)
+	err := row.Scan(&counters.Total, &counters.Scheduled)
+	if err == sql.ErrNoRows {
+		return counters, nil
+	}
+
+	return counters, err
+}
+
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// lockService locks the projected row of the service and counters of its users, so concurrent changes
+// of the same user do not recount from the stale snapshot. It returns the user ID of the projected service
+// or zero if the service is not projected.
+func (s *PostgresStore) lockService(tx *sql.Tx, serviceID uuid.UUID, userID uint64) (uint64, error) {
+	var previousUserID uint64
+
+	row := tx.QueryRowContext(s.ctx, `SELECT user_id FROM read_services WHERE id = $1 FOR UPDATE`, serviceID)
+	if err := row.Scan(&previousUserID); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	userIDs := []uint64{previousUserID, userID}
+	// Locks are taken in the same order by all transactions to avoid deadlocks
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	for i, id := range userIDs {
+		if id == 0 || (i > 0 && id == userIDs[i-1]) {
+			continue
+		}
+
+		if _, err := tx.ExecContext(s.ctx, `SELECT pg_advisory_xact_lock($1)`, int64(id)); err != nil {
+			return 0, err
+		}
+	}
+
+	return previousUserID, nil
+}
+
+// recount recalculates counters of the users from their projected services
+func (s *PostgresStore) recount(tx *sql.Tx, userIDs ...uint64) error {
+	query := `INSERT INTO read_user_counters (user_id, total, scheduled)
+			SELECT $1, count(*), count(when_utc) FROM read_services WHERE user_id = $1
+			ON CONFLICT (user_id) DO UPDATE
+			SET total = EXCLUDED.total,
+			    scheduled = EXCLUDED.scheduled`
+
+	for _, userID := range userIDs {
+		if userID == 0 {
+			continue
+		}
+
+		if _, err := tx.ExecContext(s.ctx, query, int64(userID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *PostgresStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(s.ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Err(rollbackErr).Msg("Failed to rollback read model transaction")
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanServices(rows *sql.Rows) ([]models.Service, error) {
+	defer func(rows *sql.Rows) {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Err(closeErr).Msg("Can't properly close rows cursor")
+		}
+	}(rows)
+
+	services := make([]models.Service, 0)
+
+	for rows.Next() {
+		var (
+			service                                  models.Service
+			description, serviceName, serviceAddress sql.NullString
+			timeZone                                 string
+			whenUTC, endUTC                          sql.NullTime
+		)
+
+		if err := rows.Scan(&service.ID, &service.UserID, &description, &serviceName, &serviceAddress,
+			&timeZone, &whenUTC, &endUTC); err != nil {
+			log.Err(err).Msg("Can't parse single row")
+			return nil, err
+		}
+
+		service.Description = description.String
+		service.ServiceName = serviceName.String
+		service.ServiceAddress = serviceAddress.String
+
+		var when, end *time.Time
+		if whenUTC.Valid {
+			when = &whenUTC.Time
+		}
+		if endUTC.Valid {
+			end = &endUTC.Time
+		}
+		if err := service.RestoreCalendar(when, end, timeZone); err != nil {
+			log.Err(err).Msg("Can't restore service calendar")
+			return nil, err
+		}
+
+		services = append(services, service)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Err(err).Msg("Error occurs during cursor iteration")
+		return nil, err
+	}
+
+	return services, nil
+}
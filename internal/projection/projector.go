@@ -0,0 +1,147 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/repo"
+	"github.com/ozonva/ova-service-api/pkg/consumer"
+)
+
+// DefaultRebuildBatchSize is the number of services loaded from the primary database at once during the rebuild.
+const DefaultRebuildBatchSize = 500
+
+// Source is the primary database the read model is projected from.
+type Source interface {
+	DescribeService(serviceID uuid.UUID) (*models.Service, error)
+	ListServicesForReplay(filter repo.ReplayFilter) ([]models.Service, error)
+}
+
+// Metrics tracks how far the read model is behind the primary database.
+type Metrics interface {
+	SetReadModelLag(lag time.Duration)
+}
+
+// Projector maintains the read model from service events. Events carry the service ID only, so the current
+// state of the service is loaded from the primary database on every event. This makes the projection
+// idempotent and insensitive to the order of events of different services: whatever event is handled last,
+// the read model ends up with the latest state.
+type Projector struct {
+	// Rebuild takes the write lock, so events are not projected on the store being reset
+	sync.RWMutex
+	source  Source
+	store   Store
+	metrics Metrics
+}
+
+func NewProjector(source Source, store Store, metrics Metrics) *Projector {
+	return &Projector{
+		source:  source,
+		store:   store,
+		metrics: metrics,
+	}
+}
+
+// Router routes create, update and delete events to the projector.
+func (p *Projector) Router() *consumer.Router {
+	return consumer.NewRouter().
+		HandleCreated(p.Project).
+		HandleUpdated(p.Project).
+		HandleDeleted(p.Project)
+}
+
+// Project applies the event to the read model.
+func (p *Projector) Project(_ context.Context, event consumer.Event) error {
+	serviceID, err := uuid.Parse(event.Payload.GetServiceId())
+	if err != nil {
+		return fmt.Errorf("invalid service ID in event %s: %w", event.Payload.GetEventId(), err)
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	if err = p.apply(event, serviceID); err != nil {
+		return err
+	}
+
+	if p.metrics != nil && event.Payload.GetTimestamp() != nil {
+		p.metrics.SetReadModelLag(time.Since(event.Payload.GetTimestamp().AsTime()))
+	}
+
+	return nil
+}
+
+func (p *Projector) apply(event consumer.Event, serviceID uuid.UUID) error {
+	if event.Payload.GetEventType() == events.Delete {
+		return p.store.Remove(serviceID)
+	}
+
+	service, err := p.source.DescribeService(serviceID)
+	// The service is deleted after the event, the delete event follows
+	if errors.Is(err, repo.ErrServiceNotFound) {
+		return p.store.Remove(serviceID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.store.Upsert(*service)
+}
+
+// Rebuild resets the read model and projects every service from the primary database.
+// Events are not projected until the rebuild is finished, they are applied on top of the rebuilt model.
+func (p *Projector) Rebuild(ctx context.Context, batchSize uint64) (uint64, error) {
+	if batchSize == 0 {
+		batchSize = DefaultRebuildBatchSize
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if err := p.store.Reset(); err != nil {
+		return 0, err
+	}
+
+	var (
+		projected uint64
+		after     uuid.UUID
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return projected, err
+		}
+
+		services, err := p.source.ListServicesForReplay(repo.ReplayFilter{AfterID: after, Limit: batchSize})
+		if err != nil {
+			return projected, err
+		}
+
+		for _, service := range services {
+			if err = p.store.Upsert(service); err != nil {
+				return projected, err
+			}
+			projected++
+		}
+
+		if uint64(len(services)) < batchSize {
+			break
+		}
+		after = services[len(services)-1].ID
+	}
+
+	if p.metrics != nil {
+		p.metrics.SetReadModelLag(0)
+	}
+
+	log.Info().Uint64("services", projected).Msg("Read model is rebuilt")
+	return projected, nil
+}
@@ -0,0 +1,135 @@
+package projection_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/projection"
+	"github.com/ozonva/ova-service-api/internal/repo"
+	"github.com/ozonva/ova-service-api/pkg/consumer"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+func newEvent(eventType events.EventType, service models.Service) consumer.Event {
+	return consumer.Event{Payload: events.ServiceCUDEvent{
+		EventID:   uuid.New(),
+		EventType: eventType,
+		ServiceID: service.ID,
+		UserID:    service.UserID,
+		Timestamp: time.Now().UTC(),
+	}.ToProto()}
+}
+
+func newProjector(t *testing.T) (*projection.Projector, *projection.MemoryStore, *mocks.MockRepo, *mocks.MockMetrics) {
+	ctrl := gomock.NewController(t)
+	repoMock := mocks.NewMockRepo(ctrl)
+	metricsMock := mocks.NewMockMetrics(ctrl)
+	store := projection.NewMemoryStore()
+
+	return projection.NewProjector(repoMock, store, metricsMock), store, repoMock, metricsMock
+}
+
+func TestProjector_WhenServiceIsUpdated_ShouldProjectItsCurrentState(t *testing.T) {
+	projector, store, repoMock, metricsMock := newProjector(t)
+	service := models.Service{ID: uuid.New(), UserID: 1, ServiceName: "Updated"}
+
+	repoMock.EXPECT().DescribeService(service.ID).Return(&service, nil)
+	metricsMock.EXPECT().SetReadModelLag(gomock.Any())
+
+	err := projector.Router().Dispatch(context.Background(), newEvent(events.Update, service))
+
+	require.NoError(t, err, "No error should be returned")
+	projected, err := store.DescribeService(service.ID)
+	require.NoError(t, err, "Service should be projected")
+	assert.Equal(t, service, *projected)
+}
+
+func TestProjector_WhenServiceIsDeletedAfterEvent_ShouldRemoveIt(t *testing.T) {
+	projector, store, repoMock, metricsMock := newProjector(t)
+	service := models.Service{ID: uuid.New(), UserID: 1}
+	require.NoError(t, store.Upsert(service))
+
+	repoMock.EXPECT().DescribeService(service.ID).Return(nil, fmt.Errorf("service with ID: %s: %w", service.ID, repo.ErrServiceNotFound))
+	metricsMock.EXPECT().SetReadModelLag(gomock.Any())
+
+	err := projector.Project(context.Background(), newEvent(events.Create, service))
+
+	require.NoError(t, err, "No error should be returned")
+	_, err = store.DescribeService(service.ID)
+	assert.Error(t, err, "Deleted service should not be projected")
+}
+
+func TestProjector_WhenRepoFails_ShouldKeepProjectedServiceAndReturnError(t *testing.T) {
+	projector, store, repoMock, _ := newProjector(t)
+	service := models.Service{ID: uuid.New(), UserID: 1}
+	require.NoError(t, store.Upsert(service))
+
+	repoMock.EXPECT().DescribeService(service.ID).Return(nil, fmt.Errorf("connection refused"))
+
+	err := projector.Project(context.Background(), newEvent(events.Update, service))
+
+	assert.Error(t, err, "Repo error should be returned")
+	_, err = store.DescribeService(service.ID)
+	assert.NoError(t, err, "Service should not be removed on repo error")
+}
+
+func TestProjector_WhenServiceIsDeleted_ShouldRemoveItWithoutLoading(t *testing.T) {
+	projector, store, repoMock, metricsMock := newProjector(t)
+	service := models.Service{ID: uuid.New(), UserID: 1}
+	require.NoError(t, store.Upsert(service))
+
+	repoMock.EXPECT().DescribeService(gomock.Any()).Times(0)
+	metricsMock.EXPECT().SetReadModelLag(gomock.Any())
+
+	err := projector.Project(context.Background(), newEvent(events.Delete, service))
+
+	require.NoError(t, err, "No error should be returned")
+	counters, err := store.UserCounters(1)
+	require.NoError(t, err)
+	assert.Zero(t, counters.Total, "Deleted service should not be counted")
+}
+
+func TestProjector_WhenRebuilt_ShouldReplaceReadModelWithPrimaryDatabase(t *testing.T) {
+	projector, store, repoMock, metricsMock := newProjector(t)
+	stale := models.Service{ID: uuid.New(), UserID: 1}
+	require.NoError(t, store.Upsert(stale))
+
+	services := []models.Service{{ID: uuid.New(), UserID: 2}, {ID: uuid.New(), UserID: 2}, {ID: uuid.New(), UserID: 3}}
+	gomock.InOrder(
+		repoMock.EXPECT().ListServicesForReplay(repo.ReplayFilter{Limit: 2}).Return(services[:2], nil),
+		repoMock.EXPECT().ListServicesForReplay(repo.ReplayFilter{AfterID: services[1].ID, Limit: 2}).Return(services[2:], nil),
+	)
+	metricsMock.EXPECT().SetReadModelLag(time.Duration(0))
+
+	projected, err := projector.Rebuild(context.Background(), 2)
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, uint64(3), projected)
+
+	_, err = store.DescribeService(stale.ID)
+	assert.Error(t, err, "Stale service should be removed by rebuild")
+
+	counters, err := store.UserCounters(2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), counters.Total)
+}
+
+func TestProjector_WhenServiceIDIsInvalid_ShouldReturnError(t *testing.T) {
+	projector, _, _, _ := newProjector(t)
+	event := consumer.Event{Payload: &pb.ServiceCUDEventV1{EventType: events.Create, ServiceId: "bad uuid", Timestamp: timestamppb.Now()}}
+
+	err := projector.Project(context.Background(), event)
+
+	assert.Error(t, err, "Invalid service ID should be reported")
+}
@@ -0,0 +1,42 @@
+package projection
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// UserCounters are aggregated per user, so they are read without scanning user services.
+type UserCounters struct {
+	UserID uint64
+	// Total is the number of services of the user
+	Total uint64
+	// Scheduled is the number of services with the time set
+	Scheduled uint64
+}
+
+// Store keeps the denormalized read model. Writes must be idempotent, the same change may be projected twice.
+type Store interface {
+	// Upsert stores the current state of the service and updates counters of its user
+	Upsert(service models.Service) error
+	// Remove deletes the service and updates counters of its user, unknown services are ignored
+	Remove(serviceID uuid.UUID) error
+	// Reset deletes everything, it is the first step of the rebuild
+	Reset() error
+
+	ListServices(limit uint64, offset uint64) ([]models.Service, error)
+	// ListServicesByInterval returns services matching the filter ordered the same way as ListServices
+	ListServicesByInterval(filter models.IntervalFilter, limit uint64, offset uint64) ([]models.Service, error)
+	DescribeService(serviceID uuid.UUID) (*models.Service, error)
+	// UserCalendar returns services of the user overlapping the [from, to) range ordered by time, the same services
+	// as ListServicesByInterval with the range selects
+	UserCalendar(userID uint64, from, to time.Time) ([]models.Service, error)
+	UserCounters(userID uint64) (UserCounters, error)
+}
+
+func notFoundError(serviceID uuid.UUID) error {
+	return fmt.Errorf("service with ID: %s was not found in the read model", serviceID.String())
+}
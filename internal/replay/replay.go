@@ -0,0 +1,184 @@
+package replay
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/repo"
+)
+
+const (
+	// DefaultBatchSize is the number of services loaded from the database and published at once.
+	DefaultBatchSize = 100
+	// DefaultRate is the max number of events per second replayed by ReplayEventsV1 which sets no rate
+	DefaultRate = 100
+)
+
+type Source interface {
+	ListServicesForReplay(filter repo.ReplayFilter) ([]models.Service, error)
+}
+
+type Publisher interface {
+	PublishBatch(messages []eventbus.Message) error
+}
+
+type Encoder interface {
+	Encode(event events.ServiceCUDEvent) (eventbus.Message, error)
+}
+
+// Options of the single replay run. Zero values disable the corresponding limit.
+type Options struct {
+	From   time.Time
+	To     time.Time
+	UserID uint64
+	// After is the cursor to resume the replay from, LastServiceID of the previous run
+	After uuid.UUID
+	// Rate is the max number of events per second
+	Rate int
+	// Limit is the max number of events replayed by the run
+	Limit uint64
+	// BatchSize is DefaultBatchSize if not set
+	BatchSize uint64
+}
+
+// Progress is reported after every published batch, so the replay can be resumed from LastServiceID.
+type Progress struct {
+	Replayed      uint64
+	LastServiceID uuid.UUID
+	Done          bool
+}
+
+// Checkpoint is called with the progress after every published batch. Replay is stopped if it returns error.
+type Checkpoint func(progress Progress) error
+
+// Replayer re-emits create events for services stored in the database. Events are marked as replayed,
+// so consumers are able to rebuild their state without triggering side effects of the live events.
+type Replayer struct {
+	source    Source
+	publisher Publisher
+	encoder   Encoder
+}
+
+func NewReplayer(source Source, publisher Publisher, encoder Encoder) *Replayer {
+	return &Replayer{
+		source:    source,
+		publisher: publisher,
+		encoder:   encoder,
+	}
+}
+
+// Run replays events until all matched services are replayed, the limit is reached or the context is canceled.
+// The returned progress is valid even if error is returned and contains the cursor of the last published batch.
+func (r *Replayer) Run(ctx context.Context, options Options, checkpoint Checkpoint) (Progress, error) {
+	progress := Progress{LastServiceID: options.After}
+
+	batchSize := options.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	limiter := newLimiter(options.Rate)
+	defer limiter.stop()
+
+	for options.Limit == 0 || progress.Replayed < options.Limit {
+		limit := batchSize
+		if options.Limit > 0 && options.Limit-progress.Replayed < limit {
+			limit = options.Limit - progress.Replayed
+		}
+
+		services, err := r.source.ListServicesForReplay(repo.ReplayFilter{
+			From:    options.From,
+			To:      options.To,
+			UserID:  options.UserID,
+			AfterID: progress.LastServiceID,
+			Limit:   limit,
+		})
+		if err != nil {
+			return progress, err
+		}
+
+		if len(services) == 0 {
+			progress.Done = true
+			break
+		}
+
+		messages := make([]eventbus.Message, len(services))
+		for i, service := range services {
+			if err = limiter.wait(ctx); err != nil {
+				return progress, err
+			}
+
+			messages[i], err = r.encoder.Encode(events.NewServiceReplayEvent(service.ID, service.UserID))
+			if err != nil {
+				return progress, err
+			}
+		}
+
+		if err = r.publisher.PublishBatch(messages); err != nil {
+			return progress, err
+		}
+
+		progress.Replayed += uint64(len(services))
+		progress.LastServiceID = services[len(services)-1].ID
+
+		// Short batch means there are no more services, so we can skip one more query
+		if uint64(len(services)) < limit {
+			progress.Done = true
+		}
+
+		log.Debug().
+			Uint64("replayed", progress.Replayed).
+			Str("last_service_id", progress.LastServiceID.String()).
+			Msg("Events batch is replayed")
+
+		if checkpoint != nil {
+			if err = checkpoint(progress); err != nil {
+				return progress, err
+			}
+		}
+
+		if progress.Done {
+			break
+		}
+	}
+
+	return progress, nil
+}
+
+// limiter allows one event per tick, rate is not limited if the ticker is nil
+type limiter struct {
+	ticker *time.Ticker
+}
+
+func newLimiter(rate int) limiter {
+	if rate <= 0 {
+		return limiter{}
+	}
+
+	return limiter{ticker: time.NewTicker(time.Second / time.Duration(rate))}
+}
+
+func (l limiter) wait(ctx context.Context) error {
+	if l.ticker == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+func (l limiter) stop() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
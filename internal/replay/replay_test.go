@@ -0,0 +1,118 @@
+package replay_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/internal/events"
+	"github.com/ozonva/ova-service-api/internal/mocks"
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/replay"
+	"github.com/ozonva/ova-service-api/internal/repo"
+)
+
+func newServices(count int) []models.Service {
+	services := make([]models.Service, count)
+	for i := range services {
+		services[i] = models.Service{ID: uuid.New(), UserID: uint64(i + 1)}
+	}
+	return services
+}
+
+func newReplayer(t *testing.T) (*replay.Replayer, *mocks.MockRepo, *mocks.MockPublisher) {
+	ctrl := gomock.NewController(t)
+	repoMock := mocks.NewMockRepo(ctrl)
+	publisherMock := mocks.NewMockPublisher(ctrl)
+	encoder, err := events.NewEncoder(events.EncoderConfig{})
+	require.NoError(t, err, "Encoder should be created")
+
+	return replay.NewReplayer(repoMock, publisherMock, encoder), repoMock, publisherMock
+}
+
+func TestReplayer_WhenServicesSpanSeveralBatches_ShouldResumeFromLastServiceID(t *testing.T) {
+	replayer, repoMock, publisherMock := newReplayer(t)
+	services := newServices(3)
+
+	gomock.InOrder(
+		repoMock.EXPECT().ListServicesForReplay(repo.ReplayFilter{UserID: 7, Limit: 2}).Return(services[:2], nil),
+		repoMock.EXPECT().ListServicesForReplay(repo.ReplayFilter{UserID: 7, AfterID: services[1].ID, Limit: 2}).Return(services[2:], nil),
+	)
+
+	var published []eventbus.Message
+	publisherMock.EXPECT().PublishBatch(gomock.Any()).Times(2).DoAndReturn(func(messages []eventbus.Message) error {
+		published = append(published, messages...)
+		return nil
+	})
+
+	var checkpoints []replay.Progress
+	progress, err := replayer.Run(context.Background(), replay.Options{UserID: 7, BatchSize: 2}, func(progress replay.Progress) error {
+		checkpoints = append(checkpoints, progress)
+		return nil
+	})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, replay.Progress{Replayed: 3, LastServiceID: services[2].ID, Done: true}, progress)
+	assert.Equal(t, []replay.Progress{
+		{Replayed: 2, LastServiceID: services[1].ID},
+		{Replayed: 3, LastServiceID: services[2].ID, Done: true},
+	}, checkpoints, "Progress should be reported after every batch")
+
+	require.Len(t, published, 3, "Event should be published for every service")
+	for i, message := range published {
+		assert.Equal(t, services[i].ID.String(), string(message.Key))
+		assert.Equal(t, "true", message.Headers[events.ReplayHeader], "Event should be marked as replayed")
+	}
+}
+
+func TestReplayer_WhenLimitIsSet_ShouldStopAfterLimit(t *testing.T) {
+	replayer, repoMock, publisherMock := newReplayer(t)
+	services := newServices(3)
+	after := uuid.New()
+
+	repoMock.EXPECT().ListServicesForReplay(repo.ReplayFilter{AfterID: after, Limit: 3}).Return(services, nil)
+	publisherMock.EXPECT().PublishBatch(gomock.Len(3)).Return(nil)
+
+	progress, err := replayer.Run(context.Background(), replay.Options{After: after, Limit: 3}, nil)
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, replay.Progress{Replayed: 3, LastServiceID: services[2].ID}, progress)
+}
+
+func TestReplayer_WhenPublishFails_ShouldKeepCursorOfLastPublishedBatch(t *testing.T) {
+	replayer, repoMock, publisherMock := newReplayer(t)
+	services := newServices(2)
+
+	gomock.InOrder(
+		repoMock.EXPECT().ListServicesForReplay(gomock.Any()).Return(services[:1], nil),
+		repoMock.EXPECT().ListServicesForReplay(gomock.Any()).Return(services[1:], nil),
+	)
+	gomock.InOrder(
+		publisherMock.EXPECT().PublishBatch(gomock.Any()).Return(nil),
+		publisherMock.EXPECT().PublishBatch(gomock.Any()).Return(fmt.Errorf("broker is down")),
+	)
+
+	progress, err := replayer.Run(context.Background(), replay.Options{BatchSize: 1}, nil)
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, replay.Progress{Replayed: 1, LastServiceID: services[0].ID}, progress)
+}
+
+func TestReplayer_WhenContextIsCanceled_ShouldStopWithoutPublishing(t *testing.T) {
+	replayer, repoMock, publisherMock := newReplayer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repoMock.EXPECT().ListServicesForReplay(gomock.Any()).Return(newServices(1), nil)
+	publisherMock.EXPECT().PublishBatch(gomock.Any()).Times(0)
+
+	_, err := replayer.Run(ctx, replay.Options{Rate: 1}, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,261 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// ConflictRule names the field services must not share while their times overlap
+type ConflictRule string
+
+const (
+	// ConflictRuleUser forbids the user to book two services at the same time
+	ConflictRuleUser ConflictRule = "user"
+	// ConflictRuleAddress forbids two services at the same address at the same time
+	ConflictRuleAddress ConflictRule = "address"
+	// ConflictRuleServiceName forbids two services with the same name at the same time
+	ConflictRuleServiceName ConflictRule = "service_name"
+)
+
+// serviceRange is the time of the service as the Postgres range, the same one as models.Service.Overlaps uses.
+// The range of migrations/00006_service_conflicts.sql indexes must match it.
+const serviceRange = `tstzrange(when_utc, COALESCE(end_utc, when_utc), CASE WHEN end_utc IS NULL THEN '[]' ELSE '[)' END)`
+
+// ParseConflictRules parses the comma separated list of rules, e.g. "user,address". Empty list disables checks.
+func ParseConflictRules(value string) ([]ConflictRule, error) {
+	rules := make([]ConflictRule, 0)
+	seen := make(map[ConflictRule]struct{})
+
+	for _, name := range strings.Split(value, ",") {
+		rule := ConflictRule(strings.TrimSpace(name))
+		if len(rule) == 0 {
+			continue
+		}
+
+		switch rule {
+		case ConflictRuleUser, ConflictRuleAddress, ConflictRuleServiceName:
+		default:
+			return nil, fmt.Errorf("unknown conflict rule: %q", rule)
+		}
+
+		if _, ok := seen[rule]; !ok {
+			seen[rule] = struct{}{}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// column is the column of the field compared by the rule
+func (rule ConflictRule) column() string {
+	switch rule {
+	case ConflictRuleUser:
+		return "user_id"
+	case ConflictRuleAddress:
+		return "service_address"
+	default:
+		return "service_name"
+	}
+}
+
+// value returns the field compared by the rule, services with the empty address or name don't conflict by them
+func (rule ConflictRule) value(service *models.Service) (string, bool) {
+	switch rule {
+	case ConflictRuleUser:
+		return strconv.FormatUint(service.UserID, 10), true
+	case ConflictRuleAddress:
+		return service.ServiceAddress, len(service.ServiceAddress) > 0
+	default:
+		return service.ServiceName, len(service.ServiceName) > 0
+	}
+}
+
+// Conflict is the service overlapping the stored one by the rule
+type Conflict struct {
+	Rule      ConflictRule
+	ServiceID uuid.UUID
+}
+
+// ConflictError is returned by writes of services overlapping other services by the conflict rules.
+// Nothing is written if it is returned.
+type ConflictError struct {
+	// ServiceID is the service which was written
+	ServiceID uuid.UUID
+	Conflicts []Conflict
+}
+
+func (err *ConflictError) Error() string {
+	conflicts := make([]string, len(err.Conflicts))
+	for i, conflict := range err.Conflicts {
+		conflicts[i] = fmt.Sprintf("%s (%s)", conflict.ServiceID.String(), conflict.Rule)
+	}
+
+	return fmt.Sprintf("service with ID: %s conflicts with services: %s", err.ServiceID.String(), strings.Join(conflicts, ", "))
+}
+
+// ServiceIDs returns distinct IDs of conflicting services in the order of conflicts
+func (err *ConflictError) ServiceIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(err.Conflicts))
+	seen := make(map[uuid.UUID]struct{}, len(err.Conflicts))
+
+	for _, conflict := range err.Conflicts {
+		if _, ok := seen[conflict.ServiceID]; !ok {
+			seen[conflict.ServiceID] = struct{}{}
+			ids = append(ids, conflict.ServiceID)
+		}
+	}
+
+	return ids
+}
+
+// findConflicts returns the conflict of the first service overlapping another one of the batch or a stored one.
+// Stored services with IDs of the batch are skipped, so the updated service doesn't conflict with itself.
+func findConflicts(ctx context.Context, tx *sql.Tx, rules []ConflictRule, services []models.Service) error {
+	for i := range services {
+		service := &services[i]
+		if service.WhenUTC == nil {
+			continue
+		}
+
+		var conflicts []Conflict
+		for j := range services[:i] {
+			conflicts = append(conflicts, matchConflicts(rules, service, &services[j])...)
+		}
+
+		stored, err := listOverlapping(ctx, tx, rules, service, services)
+		if err != nil {
+			return err
+		}
+		for j := range stored {
+			conflicts = append(conflicts, matchConflicts(rules, service, &stored[j])...)
+		}
+
+		if len(conflicts) > 0 {
+			return &ConflictError{ServiceID: service.ID, Conflicts: conflicts}
+		}
+	}
+
+	return nil
+}
+
+// matchConflicts returns the rules the other service breaks for the service
+func matchConflicts(rules []ConflictRule, service *models.Service, other *models.Service) []Conflict {
+	if !service.Overlaps(other) {
+		return nil
+	}
+
+	var conflicts []Conflict
+	for _, rule := range rules {
+		value, ok := rule.value(service)
+		if !ok {
+			continue
+		}
+		if otherValue, _ := rule.value(other); otherValue == value {
+			conflicts = append(conflicts, Conflict{Rule: rule, ServiceID: other.ID})
+		}
+	}
+
+	return conflicts
+}
+
+// listOverlapping returns stored services overlapping the service which share any field compared by the rules
+func listOverlapping(ctx context.Context, tx *sql.Tx, rules []ConflictRule, service *models.Service, batch []models.Service) ([]models.Service, error) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc").
+		From("services")
+
+	fields := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if value, ok := rule.value(service); ok {
+			if rule == ConflictRuleUser {
+				fields = append(fields, sb.Equal(rule.column(), service.UserID))
+			} else {
+				fields = append(fields, sb.Equal(rule.column(), value))
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	start, end, bounds := rangeOf(service)
+	ids := make([]interface{}, len(batch))
+	for i := range batch {
+		ids[i] = batch[i].ID
+	}
+
+	sb.Where(
+		sb.IsNotNull("when_utc"),
+		sb.NotIn("id", ids...),
+		fmt.Sprintf("%s && tstzrange(%s, %s, %s)", serviceRange, sb.Var(start), sb.Var(end), sb.Var(bounds)),
+		sb.Or(fields...),
+	)
+	sb.OrderBy("when_utc", "id")
+
+	query, values := sb.Build()
+	query = sqlx.Rebind(sqlx.DOLLAR, query)
+
+	rows, err := tx.QueryContext(ctx, query, values...)
+	if err != nil {
+		log.Err(err).Msg("Error occurred during conflict query execution")
+		return nil, err
+	}
+
+	return scanServices(rows)
+}
+
+// rangeOf returns bounds of serviceRange of the service
+func rangeOf(service *models.Service) (start, end interface{}, bounds string) {
+	if service.EndUTC == nil {
+		return *service.WhenUTC, *service.WhenUTC, "[]"
+	}
+	return *service.WhenUTC, *service.EndUTC, "[)"
+}
+
+// lockConflictKeys serializes writes of services sharing fields compared by the rules until the transaction ends,
+// so concurrent writes can't both pass the check. Keys are locked in the same order to avoid deadlocks.
+func lockConflictKeys(ctx context.Context, tx *sql.Tx, rules []ConflictRule, services []models.Service) error {
+	keys := make([]string, 0, len(rules)*len(services))
+	seen := make(map[string]struct{})
+
+	for i := range services {
+		if services[i].WhenUTC == nil {
+			continue
+		}
+
+		for _, rule := range rules {
+			value, ok := rule.value(&services[i])
+			if !ok {
+				continue
+			}
+
+			key := string(rule) + ":" + value
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+			log.Err(err).Msg("Can't lock conflict key")
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+func TestParseConflictRules_ShouldSkipEmptyAndRepeatedRules(t *testing.T) {
+	rules, err := ParseConflictRules(" user, ,address,user ")
+
+	require.NoError(t, err)
+	assert.Equal(t, []ConflictRule{ConflictRuleUser, ConflictRuleAddress}, rules)
+}
+
+func TestParseConflictRules_WhenRuleIsUnknown_ShouldReturnError(t *testing.T) {
+	_, err := ParseConflictRules("user,room")
+
+	assert.EqualError(t, err, `unknown conflict rule: "room"`)
+}
+
+func TestMatchConflicts_ShouldReturnRulesBrokenByOverlappingService(t *testing.T) {
+	when := time.Date(2040, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := when.Add(time.Hour)
+	service := &models.Service{ID: uuid.New(), UserID: 1, ServiceAddress: "Moscow", WhenUTC: &when, EndUTC: &end}
+	rules := []ConflictRule{ConflictRuleUser, ConflictRuleAddress, ConflictRuleServiceName}
+
+	otherWhen := when.Add(30 * time.Minute)
+	other := &models.Service{ID: uuid.New(), UserID: 1, ServiceAddress: "Moscow", WhenUTC: &otherWhen}
+	later := &models.Service{ID: uuid.New(), UserID: 1, ServiceAddress: "Moscow", WhenUTC: &end}
+
+	assert.Equal(t, []Conflict{
+		{Rule: ConflictRuleUser, ServiceID: other.ID},
+		{Rule: ConflictRuleAddress, ServiceID: other.ID},
+	}, matchConflicts(rules, service, other), "Empty service names should not conflict")
+	assert.Empty(t, matchConflicts(rules, service, later), "Service starting at the end should not conflict")
+}
+
+func TestConflictError_ShouldListDistinctServiceIDs(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	err := &ConflictError{ServiceID: uuid.New(), Conflicts: []Conflict{
+		{Rule: ConflictRuleUser, ServiceID: first},
+		{Rule: ConflictRuleAddress, ServiceID: first},
+		{Rule: ConflictRuleAddress, ServiceID: second},
+	}}
+
+	assert.Equal(t, []uuid.UUID{first, second}, err.ServiceIDs())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/huandu/go-sqlbuilder"
@@ -21,13 +22,16 @@ type dbService struct {
 	Description    sql.NullString
 	ServiceName    sql.NullString
 	ServiceAddress sql.NullString
-	WhenLocal      sql.NullTime
+	TimeZone       string
 	WhenUTC        sql.NullTime
+	EndUTC         sql.NullTime
 }
 
 type PostgresServiceRepo struct {
 	ctx context.Context
 	db  *sql.DB
+	// conflictRules are checked by AddServices and UpdateService, no checks are made if it is empty
+	conflictRules []ConflictRule
 }
 
 func NewPostgresServiceRepo(ctx context.Context, dsn string) (*PostgresServiceRepo, error) {
@@ -49,6 +53,13 @@ func NewPostgresServiceRepo(ctx context.Context, dsn string) (*PostgresServiceRe
 	}, nil
 }
 
+// WithConflictRules makes writes fail with ConflictError if the service overlaps other services by the rules.
+// Writes are made in transactions holding locks of the compared fields then.
+func (repo *PostgresServiceRepo) WithConflictRules(rules []ConflictRule) *PostgresServiceRepo {
+	repo.conflictRules = rules
+	return repo
+}
+
 func (repo *PostgresServiceRepo) AddServices(services []models.Service) error {
 	log.Debug().Msg("PostgresServiceRepo.AddServices call")
 
@@ -56,18 +67,59 @@ func (repo *PostgresServiceRepo) AddServices(services []models.Service) error {
 		return nil
 	}
 
+	if len(repo.conflictRules) == 0 {
+		return repo.addServices(repo.db, services)
+	}
+
+	return repo.inConflictCheckedTx(services, func(tx *sql.Tx) error {
+		return repo.addServices(tx, services)
+	})
+}
+
+// execer is either the database or the transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// inConflictCheckedTx runs fn in the transaction if services don't conflict with each other and stored services
+func (repo *PostgresServiceRepo) inConflictCheckedTx(services []models.Service, fn func(tx *sql.Tx) error) error {
+	tx, err := repo.db.BeginTx(repo.ctx, nil)
+	if err != nil {
+		log.Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Err(rollbackErr).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	if err = lockConflictKeys(repo.ctx, tx, repo.conflictRules, services); err != nil {
+		return err
+	}
+	if err = findConflicts(repo.ctx, tx, repo.conflictRules, services); err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *PostgresServiceRepo) addServices(db execer, services []models.Service) error {
 	sb := sqlbuilder.NewInsertBuilder().
 		InsertInto("services").
-		Cols("id, user_id, description, service_name, service_address, when_local, when_utc")
+		Cols("id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc")
 
 	for _, service := range services {
-		sb.Values(service.ID, service.UserID, service.Description, service.ServiceName, service.ServiceAddress, service.WhenLocal, service.WhenUTC)
+		sb.Values(service.ID, service.UserID, service.Description, service.ServiceName, service.ServiceAddress, service.TimeZone, service.WhenUTC, service.EndUTC)
 	}
 
 	query, values := sb.Build()
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
 
-	if _, err := repo.db.ExecContext(repo.ctx, query, values...); err != nil {
+	if _, err := db.ExecContext(repo.ctx, query, values...); err != nil {
 		log.Err(err).Msg("Failed to begin transaction")
 		return err
 	}
@@ -79,64 +131,224 @@ func (repo *PostgresServiceRepo) AddServices(services []models.Service) error {
 func (repo *PostgresServiceRepo) ListServices(limit uint64, offset uint64) ([]models.Service, error) {
 	log.Debug().Msg("PostgresServiceRepo.ListServices call")
 
+	query, args := listServicesQuery(limit, offset)
+	rows, err := repo.db.QueryContext(repo.ctx, query, args...)
+
+	if err != nil {
+		log.Err(err).Msg("Error occurred during query execution")
+		return nil, err
+	}
+
+	return scanServices(rows)
+}
+
+// ExportServices calls fn for every service in the ListServices order without loading all of them to memory.
+// Services are filtered the same way as ListServicesByInterval if the range of the filter is bounded.
+// Services are read in the read-only repeatable read transaction, so the export sees the single snapshot
+// regardless of its duration and concurrent writes. The fn error stops the export and is returned as is.
+func (repo *PostgresServiceRepo) ExportServices(filter models.IntervalFilter, limit uint64, offset uint64, fn func(service models.Service) error) error {
+	log.Debug().Msg("PostgresServiceRepo.ExportServices call")
+
+	tx, err := repo.db.BeginTx(repo.ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		log.Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		// Read-only transaction has nothing to commit
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Err(rollbackErr).Msg("Can't properly rollback transaction")
+		}
+	}()
+
+	query, args := listServicesQuery(limit, offset)
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		query, args = listServicesByIntervalQuery(filter, limit, offset)
+	}
+	rows, err := tx.QueryContext(repo.ctx, query, args...)
+	if err != nil {
+		log.Err(err).Msg("Error occurred during query execution")
+		return err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		service, scanErr := scanService(rows)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		if err := fn(service); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Err(err).Msg("Error occurs during cursor iteration")
+		return err
+	}
+
+	return nil
+}
+
+// ListServicesAfter is the keyset pagination of ListServices, the nil cursor returns the first page.
+// Unlike the offset, the cursor is found by the index, so every page is loaded in the same time.
+func (repo *PostgresServiceRepo) ListServicesAfter(cursor *ServiceCursor, limit uint64) ([]models.Service, error) {
+	log.Debug().Msg("PostgresServiceRepo.ListServicesAfter call")
+
 	var (
-		query string
-		rows  *sql.Rows
-		err   error
+		rows *sql.Rows
+		err  error
 	)
 
-	// This is actually a hack to handle the difference between the required Repo API which includes limit and offset
-	// and gRPC server API which allows to list all.
-	if limit < ^uint64(0) {
-		query = `SELECT id, user_id, description, service_name, service_address, when_local, when_utc
+	// Services without time go first, as NULLs are the greatest values for the descending order
+	switch {
+	case cursor == nil:
+		rows, err = repo.db.QueryContext(repo.ctx, `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
 			FROM services
-			ORDER BY when_utc DESC
-			LIMIT $1 OFFSET $2`
-		rows, err = repo.db.QueryContext(repo.ctx, query, limit, offset)
-	} else {
-		query = `SELECT id, user_id, description, service_name, service_address, when_local, when_utc
+			ORDER BY when_utc DESC, id
+			LIMIT $1`, limit)
+	case cursor.WhenUTC == nil:
+		rows, err = repo.db.QueryContext(repo.ctx, `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
 			FROM services
-			ORDER BY when_utc DESC`
-		rows, err = repo.db.QueryContext(repo.ctx, query)
+			WHERE when_utc IS NOT NULL OR id > $1
+			ORDER BY when_utc DESC, id
+			LIMIT $2`, cursor.ID, limit)
+	default:
+		rows, err = repo.db.QueryContext(repo.ctx, `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
+			FROM services
+			WHERE when_utc < $1 OR (when_utc = $1 AND id > $2)
+			ORDER BY when_utc DESC, id
+			LIMIT $3`, cursor.WhenUTC.UTC(), cursor.ID, limit)
 	}
 
 	if err != nil {
 		log.Err(err).Msg("Error occurred during query execution")
 		return nil, err
 	}
-	defer func(rows *sql.Rows) {
-		closeErr := rows.Close()
-		if closeErr != nil {
-			log.Err(err).Msg("Can't properly close rows cursor")
-		}
-	}(rows)
 
-	services := make([]models.Service, 0)
+	return scanServices(rows)
+}
 
-	for rows.Next() {
-		var service dbService
+// listServicesQuery is actually a hack to handle the difference between the required Repo API which includes
+// limit and offset and gRPC server API which allows to list all.
+func listServicesQuery(limit uint64, offset uint64) (string, []interface{}) {
+	if limit < ^uint64(0) {
+		return `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
+			FROM services
+			ORDER BY when_utc DESC, id
+			LIMIT $1 OFFSET $2`, []interface{}{limit, offset}
+	}
 
-		if err = rows.Scan(&service.ID, &service.UserID, &service.Description, &service.ServiceName,
-			&service.ServiceAddress, &service.WhenLocal, &service.WhenUTC); err != nil {
-			log.Err(err).Msg("Can't parse single row")
-			return nil, err
+	return `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
+			FROM services
+			ORDER BY when_utc DESC, id`, nil
+}
+
+// ListServicesByInterval lists services matching the filter in the ListServices order
+func (repo *PostgresServiceRepo) ListServicesByInterval(filter models.IntervalFilter, limit uint64, offset uint64) ([]models.Service, error) {
+	log.Debug().Msg("PostgresServiceRepo.ListServicesByInterval call")
+
+	query, values := listServicesByIntervalQuery(filter, limit, offset)
+	rows, err := repo.db.QueryContext(repo.ctx, query, values...)
+	if err != nil {
+		log.Err(err).Msg("Error occurred during query execution")
+		return nil, err
+	}
+
+	return scanServices(rows)
+}
+
+func listServicesByIntervalQuery(filter models.IntervalFilter, limit uint64, offset uint64) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc").
+		From("services").
+		Where(sb.IsNotNull("when_utc"))
+	whereInterval(sb, filter)
+	sb.OrderBy("when_utc DESC", "id")
+
+	// The same hack as in ListServices: max limit lists all services
+	if limit < ^uint64(0) {
+		sb.Limit(int(limit)).Offset(int(offset))
+	}
+
+	query, values := sb.Build()
+	return sqlx.Rebind(sqlx.DOLLAR, query), values
+}
+
+// whereInterval adds conditions of models.IntervalFilter.Matches, services without the end are instants
+func whereInterval(sb *sqlbuilder.SelectBuilder, filter models.IntervalFilter) {
+	if len(filter.ServiceName) > 0 {
+		sb.Where(sb.Equal("service_name", filter.ServiceName))
+	}
+	if len(filter.ServiceAddress) > 0 {
+		sb.Where(sb.Equal("service_address", filter.ServiceAddress))
+	}
+
+	if filter.Within {
+		if !filter.From.IsZero() {
+			sb.Where(sb.GreaterEqualThan("when_utc", filter.From.UTC()))
 		}
+		if !filter.To.IsZero() {
+			sb.Where(sb.LessEqualThan("COALESCE(end_utc, when_utc)", filter.To.UTC()))
+		}
+		return
+	}
 
-		services = append(services, mapDBServiceToDomainService(&service))
+	if !filter.To.IsZero() {
+		sb.Where(sb.LessThan("when_utc", filter.To.UTC()))
+	}
+	if !filter.From.IsZero() {
+		sb.Where(sb.Or(
+			sb.GreaterThan("end_utc", filter.From.UTC()),
+			sb.And(sb.IsNull("end_utc"), sb.GreaterEqualThan("when_utc", filter.From.UTC())),
+		))
 	}
+}
 
-	if err = rows.Err(); err != nil {
-		log.Err(err).Msg("Error occurs during cursor iteration")
+func (repo *PostgresServiceRepo) ListServicesForReplay(filter ReplayFilter) ([]models.Service, error) {
+	log.Debug().Msg("PostgresServiceRepo.ListServicesForReplay call")
+
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc").
+		From("services")
+
+	if !filter.From.IsZero() {
+		sb.Where(sb.GreaterEqualThan("created_at", filter.From.UTC()))
+	}
+	if !filter.To.IsZero() {
+		sb.Where(sb.LessThan("created_at", filter.To.UTC()))
+	}
+	if filter.UserID != 0 {
+		sb.Where(sb.Equal("user_id", filter.UserID))
+	}
+	if filter.AfterID != uuid.Nil {
+		sb.Where(sb.GreaterThan("id", filter.AfterID))
+	}
+
+	// Order by primary key makes the last returned ID a stable cursor to resume the replay from.
+	// UUIDv7 IDs are ordered by the creation time, so services are replayed in the order they were created.
+	sb.OrderBy("id")
+	if filter.Limit > 0 {
+		sb.Limit(int(filter.Limit))
+	}
+
+	query, values := sb.Build()
+	query = sqlx.Rebind(sqlx.DOLLAR, query)
+
+	rows, err := repo.db.QueryContext(repo.ctx, query, values...)
+	if err != nil {
+		log.Err(err).Msg("Error occurred during query execution")
 		return nil, err
 	}
 
-	return services, nil
+	return scanServices(rows)
 }
 
 func (repo *PostgresServiceRepo) DescribeService(serviceID uuid.UUID) (*models.Service, error) {
 	log.Debug().Msg("PostgresServiceRepo.DescribeService call")
 
-	query := `SELECT id, user_id, description, service_name, service_address, when_local, when_utc
+	query := `SELECT id, user_id, description, service_name, service_address, time_zone, when_utc, end_utc
 			FROM services
 			WHERE id = $1`
 
@@ -144,14 +356,17 @@ func (repo *PostgresServiceRepo) DescribeService(serviceID uuid.UUID) (*models.S
 
 	var service dbService
 	err := row.Scan(&service.ID, &service.UserID, &service.Description, &service.ServiceName,
-		&service.ServiceAddress, &service.WhenLocal, &service.WhenUTC)
+		&service.ServiceAddress, &service.TimeZone, &service.WhenUTC, &service.EndUTC)
 
 	switch err {
 	case nil:
-		domainService := mapDBServiceToDomainService(&service)
+		domainService, mapErr := mapDBServiceToDomainService(&service)
+		if mapErr != nil {
+			return nil, mapErr
+		}
 		return &domainService, nil
 	case sql.ErrNoRows:
-		notFoundErr := fmt.Errorf("service with ID: %s was not found in the repo", serviceID.String())
+		notFoundErr := fmt.Errorf("service with ID: %s: %w", serviceID.String(), ErrServiceNotFound)
 		log.Err(notFoundErr).Msg("Error occurred during describe service")
 		return nil, notFoundErr
 	default:
@@ -184,19 +399,30 @@ func (repo *PostgresServiceRepo) UpdateService(service *models.Service) error {
 		return nilErr
 	}
 
+	if len(repo.conflictRules) == 0 {
+		return repo.updateService(repo.db, service)
+	}
+
+	return repo.inConflictCheckedTx([]models.Service{*service}, func(tx *sql.Tx) error {
+		return repo.updateService(tx, service)
+	})
+}
+
+func (repo *PostgresServiceRepo) updateService(db execer, service *models.Service) error {
 	query := `UPDATE services
 			SET user_id = $1,
 			    description = $2,
 			    service_name = $3,
 			    service_address = $4,
-			    when_local = $5,
+			    time_zone = $5,
 			    when_utc = $6,
+			    end_utc = $7,
 			    updated_at = CURRENT_TIMESTAMP,
 			    version = version + 1
-			WHERE id = $7`
+			WHERE id = $8`
 
-	res, err := repo.db.ExecContext(repo.ctx, query, service.UserID, service.Description, service.ServiceName,
-		service.ServiceAddress, service.WhenLocal, service.WhenUTC, service.ID)
+	res, err := db.ExecContext(repo.ctx, query, service.UserID, service.Description, service.ServiceName,
+		service.ServiceAddress, service.TimeZone, service.WhenUTC, service.EndUTC, service.ID)
 
 	if err != nil {
 		log.Err(err).Msg("Error occurs during update operation execution")
@@ -220,7 +446,47 @@ func (repo *PostgresServiceRepo) UpdateService(service *models.Service) error {
 	return nil
 }
 
-func mapDBServiceToDomainService(service *dbService) models.Service {
+func scanServices(rows *sql.Rows) ([]models.Service, error) {
+	defer closeRows(rows)
+
+	services := make([]models.Service, 0)
+
+	for rows.Next() {
+		service, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		services = append(services, service)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Err(err).Msg("Error occurs during cursor iteration")
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func scanService(rows *sql.Rows) (models.Service, error) {
+	var service dbService
+
+	if err := rows.Scan(&service.ID, &service.UserID, &service.Description, &service.ServiceName,
+		&service.ServiceAddress, &service.TimeZone, &service.WhenUTC, &service.EndUTC); err != nil {
+		log.Err(err).Msg("Can't parse single row")
+		return models.Service{}, err
+	}
+
+	return mapDBServiceToDomainService(&service)
+}
+
+func closeRows(rows *sql.Rows) {
+	if closeErr := rows.Close(); closeErr != nil {
+		log.Err(closeErr).Msg("Can't properly close rows cursor")
+	}
+}
+
+func mapDBServiceToDomainService(service *dbService) (models.Service, error) {
 	var domainService models.Service
 
 	domainService.ID = service.ID
@@ -231,12 +497,18 @@ func mapDBServiceToDomainService(service *dbService) models.Service {
 	domainService.ServiceName = service.ServiceName.String
 	domainService.ServiceAddress = service.ServiceAddress.String
 
-	if service.WhenLocal.Valid {
-		domainService.WhenLocal = &service.WhenLocal.Time
-	}
+	var when, end *time.Time
 	if service.WhenUTC.Valid {
-		domainService.WhenUTC = &service.WhenUTC.Time
+		when = &service.WhenUTC.Time
+	}
+	if service.EndUTC.Valid {
+		end = &service.EndUTC.Time
+	}
+
+	if err := domainService.RestoreCalendar(when, end, service.TimeZone); err != nil {
+		log.Err(err).Str("service_id", service.ID.String()).Msg("Can't restore service calendar")
+		return models.Service{}, err
 	}
 
-	return domainService
+	return domainService, nil
 }
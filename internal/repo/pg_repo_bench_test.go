@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// benchBatchSize is the number of services inserted by the single AddServices call
+const benchBatchSize = 1000
+
+// newBenchRepo connects to the database with the applied migrations from BENCH_DATABASE_CONNECTION_STRING,
+// benchmarks are skipped if it is not set. Do not point it to the production database.
+func newBenchRepo(b *testing.B) *PostgresServiceRepo {
+	dsn := os.Getenv("BENCH_DATABASE_CONNECTION_STRING")
+	if len(dsn) == 0 {
+		b.Skip("BENCH_DATABASE_CONNECTION_STRING is not set")
+	}
+
+	repo, err := NewPostgresServiceRepo(context.Background(), dsn)
+	if err != nil {
+		b.Fatalf("Can't connect to the database: %s", err.Error())
+	}
+	b.Cleanup(func() {
+		_ = repo.db.Close()
+	})
+
+	return repo
+}
+
+// benchmarkAddServices inserts batches of services with IDs of the generator. Random IDs are inserted to random
+// pages of the primary key index, so the gap with time ordered IDs grows with the table size.
+func benchmarkAddServices(b *testing.B, generator models.IDGenerator) {
+	repo := newBenchRepo(b)
+
+	inserted := make([]string, 0, b.N*benchBatchSize)
+	b.Cleanup(func() {
+		if _, err := repo.db.Exec(`DELETE FROM services WHERE id = ANY($1::uuid[])`, inserted); err != nil {
+			b.Errorf("Can't delete inserted services: %s", err.Error())
+		}
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		services := make([]models.Service, benchBatchSize)
+		for j := range services {
+			service, err := models.NewService(generator, 1, "Benchmark", "Benchmark service", "Nowhere", nil, nil, "")
+			if err != nil {
+				b.Fatal(err)
+			}
+			services[j] = *service
+			inserted = append(inserted, service.ID.String())
+		}
+		b.StartTimer()
+
+		if err := repo.AddServices(services); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddServices_UUIDv4(b *testing.B) {
+	benchmarkAddServices(b, models.UUIDv4)
+}
+
+func BenchmarkAddServices_UUIDv7(b *testing.B) {
+	benchmarkAddServices(b, models.UUIDv7)
+}
@@ -1,15 +1,94 @@
 package repo
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 
 	"github.com/ozonva/ova-service-api/internal/models"
 )
 
+var (
+	// ErrServiceNotFound is wrapped by errors returned for unknown service IDs
+	ErrServiceNotFound = errors.New("service was not found in the repo")
+	// ErrInvalidCursor is wrapped by errors returned for tokens which are not issued by ServiceCursor.Token
+	ErrInvalidCursor = errors.New("invalid service cursor")
+)
+
 type Repo interface {
 	AddServices(services []models.Service) error
 	ListServices(limit uint64, offset uint64) ([]models.Service, error)
+	ListServicesByInterval(filter models.IntervalFilter, limit uint64, offset uint64) ([]models.Service, error)
 	DescribeService(serviceID uuid.UUID) (*models.Service, error)
 	RemoveService(serviceID uuid.UUID) error
 	UpdateService(service *models.Service) error
+	ListServicesForReplay(filter ReplayFilter) ([]models.Service, error)
+	ExportServices(filter models.IntervalFilter, limit uint64, offset uint64, fn func(service models.Service) error) error
+	ListServicesAfter(cursor *ServiceCursor, limit uint64) ([]models.Service, error)
+}
+
+// ServiceCursor is the position right after the service in the ListServices order. Services with the same time
+// are ordered by ID, so pages neither repeat nor skip them whatever is inserted between page loads.
+type ServiceCursor struct {
+	WhenUTC *time.Time
+	ID      uuid.UUID
+}
+
+// CursorAfter returns the cursor of the next page, when the service is the last one of the page
+func CursorAfter(service models.Service) *ServiceCursor {
+	return &ServiceCursor{WhenUTC: service.WhenUTC, ID: service.ID}
+}
+
+// Token encodes the cursor to the opaque string, the time keeps its full precision
+func (cursor *ServiceCursor) Token() string {
+	when := ""
+	if cursor.WhenUTC != nil {
+		when = cursor.WhenUTC.UTC().Format(time.RFC3339Nano)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(when + "/" + cursor.ID.String()))
+}
+
+// ParseServiceCursor decodes the token of ServiceCursor.Token
+func ParseServiceCursor(token string) (*ServiceCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, token)
+	}
+
+	parts := strings.SplitN(string(decoded), "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, token)
+	}
+
+	cursor := &ServiceCursor{}
+	if cursor.ID, err = uuid.Parse(parts[1]); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, token)
+	}
+	if len(parts[0]) > 0 {
+		when, parseErr := time.Parse(time.RFC3339Nano, parts[0])
+		if parseErr != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, token)
+		}
+		cursor.WhenUTC = &when
+	}
+
+	return cursor, nil
+}
+
+// ReplayFilter selects stored services to re-emit events for. Zero values disable the corresponding condition.
+type ReplayFilter struct {
+	// From and To limit the creation time of services to the [From, To) range
+	From time.Time
+	To   time.Time
+	// UserID selects services of the single user
+	UserID uint64
+	// AfterID is the cursor, only services with greater ID are returned
+	AfterID uuid.UUID
+	// Limit is the max number of returned services
+	Limit uint64
 }
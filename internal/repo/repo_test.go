@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceCursor_Token_ShouldKeepFullPrecisionOfTime(t *testing.T) {
+	when := time.Date(2040, 1, 1, 10, 0, 0, 123456789, time.FixedZone("MSK", 3*60*60))
+	cursor := &ServiceCursor{WhenUTC: &when, ID: uuid.New()}
+
+	parsed, err := ParseServiceCursor(cursor.Token())
+
+	require.NoError(t, err)
+	assert.Equal(t, cursor.ID, parsed.ID)
+	assert.True(t, when.Equal(*parsed.WhenUTC), "Services of the same millisecond should not be skipped")
+}
+
+func TestServiceCursor_Token_WhenServiceHasNoTime_ShouldKeepNilTime(t *testing.T) {
+	cursor := &ServiceCursor{ID: uuid.New()}
+
+	parsed, err := ParseServiceCursor(cursor.Token())
+
+	require.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+}
+
+func TestParseServiceCursor_WhenTokenIsNotIssued_ShouldReturnError(t *testing.T) {
+	for _, token := range []string{"", "not a token", "MjA0MC0wMS0wMQ", "LzEyMw"} {
+		_, err := ParseServiceCursor(token)
+
+		assert.ErrorIs(t, err, ErrInvalidCursor, "Token %q should be rejected", token)
+	}
+}
@@ -0,0 +1,71 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the name of gRPC metadata key, HTTP header and Kafka message header carrying request ID.
+const Header = "x-request-id"
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext returns request ID stored in the context or empty string.
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// UnaryServerInterceptor takes request ID from the incoming metadata or generates the new one,
+// stores it in the handler context and returns it to the client in the response header.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := incomingRequestID(ctx)
+
+		// Error is possible only if headers were already sent, which is not the case before the handler call.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(Header, requestID))
+
+		return handler(NewContext(ctx, requestID), req)
+	}
+}
+
+// StreamServerInterceptor does the same as UnaryServerInterceptor for streaming calls, the request ID is stored
+// in the context of the stream passed to the handler.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		requestID := incomingRequestID(stream.Context())
+
+		// Headers are not sent before the handler call as well
+		_ = stream.SetHeader(metadata.Pairs(Header, requestID))
+
+		return handler(srv, &serverStream{ServerStream: stream, ctx: NewContext(stream.Context(), requestID)})
+	}
+}
+
+// incomingRequestID returns request ID of the incoming metadata or the new one
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(Header); len(values) > 0 && len(values[0]) > 0 {
+			return values[0]
+		}
+	}
+
+	return uuid.New().String()
+}
+
+// serverStream replaces the context of the wrapped stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,94 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/ova.service.ServiceAPI/CreateServiceV1"}
+
+func captureRequestID(ctx context.Context) (string, error) {
+	var got string
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		got = FromContext(ctx)
+		return nil, nil
+	}
+
+	_, err := UnaryServerInterceptor()(ctx, nil, info, handler)
+	return got, err
+}
+
+func TestUnaryServerInterceptor_WhenRequestIDIsPassed_ShouldStoreItInContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "request-1"))
+
+	got, err := captureRequestID(ctx)
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, "request-1", got, "Request ID should be taken from metadata")
+}
+
+func TestUnaryServerInterceptor_WhenRequestIDIsAbsent_ShouldGenerateNewOne(t *testing.T) {
+	got, err := captureRequestID(context.Background())
+
+	require.NoError(t, err, "No error should be returned")
+	_, parseErr := uuid.Parse(got)
+	assert.NoError(t, parseErr, "Generated request ID should be UUID")
+}
+
+// fakeServerStream records headers set by the interceptor
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func captureStreamRequestID(ctx context.Context) (string, *fakeServerStream, error) {
+	var got string
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		got = FromContext(stream.Context())
+		return nil
+	}
+
+	stream := &fakeServerStream{ctx: ctx}
+	info := &grpc.StreamServerInfo{FullMethod: "/ova.service.ServiceAPI/ExportServicesV1", IsServerStream: true}
+	err := StreamServerInterceptor()(nil, stream, info, handler)
+	return got, stream, err
+}
+
+func TestStreamServerInterceptor_WhenRequestIDIsPassed_ShouldStoreItInStreamContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "request-1"))
+
+	got, stream, err := captureStreamRequestID(ctx)
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, "request-1", got, "Request ID should be taken from metadata")
+	assert.Equal(t, []string{"request-1"}, stream.header.Get(Header), "Request ID should be returned in the header")
+}
+
+func TestStreamServerInterceptor_WhenRequestIDIsAbsent_ShouldGenerateNewOne(t *testing.T) {
+	got, stream, err := captureStreamRequestID(context.Background())
+
+	require.NoError(t, err, "No error should be returned")
+	_, parseErr := uuid.Parse(got)
+	assert.NoError(t, parseErr, "Generated request ID should be UUID")
+	assert.Equal(t, []string{got}, stream.header.Get(Header), "Generated request ID should be returned in the header")
+}
+
+func TestFromContext_WhenRequestIDIsAbsent_ShouldReturnEmptyString(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+}
@@ -0,0 +1,179 @@
+package slots
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+const (
+	// MaxDays limits the date range of the search, slots of longer ranges are rarely shown at once
+	MaxDays = 31
+	// MinStep limits the number of slots of the day
+	MinStep = 5 * time.Minute
+)
+
+// Request describes slots to find. Dates and working hours are wall clock times in Location.
+type Request struct {
+	// FirstDay and LastDay are dates of the range, both are included. Only their dates are used.
+	FirstDay time.Time
+	LastDay  time.Time
+	Location *time.Location
+	// WorkStart and WorkEnd are offsets of working hours from the midnight, every day of the range is a working day
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	// Duration is the length of the slot, slots start every Step from the start of working hours
+	Duration time.Duration
+	Step     time.Duration
+	// Buffer is the free time kept before and after booked services
+	Buffer time.Duration
+	// Now excludes slots starting before it
+	Now time.Time
+}
+
+// Slot is the free time of Request.Duration, both bounds are in Request.Location
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (req Request) Validate() error {
+	if req.Location == nil {
+		return fmt.Errorf("time zone is not set")
+	}
+	if req.Duration <= 0 {
+		return fmt.Errorf("slot duration must be positive")
+	}
+	if req.Step < MinStep {
+		return fmt.Errorf("slot step must be %v at least", MinStep)
+	}
+	if req.Buffer < 0 {
+		return fmt.Errorf("buffer can't be negative")
+	}
+	if req.WorkStart < 0 || req.WorkEnd > 24*time.Hour || req.WorkStart >= req.WorkEnd {
+		return fmt.Errorf("working hours must be within the day and end after their start")
+	}
+
+	days := req.days()
+	if days < 1 {
+		return fmt.Errorf("last day must not be before the first one")
+	}
+	if days > MaxDays {
+		return fmt.Errorf("date range can't be longer than %d days", MaxDays)
+	}
+
+	return nil
+}
+
+// Window returns the range services booked in which can take slots of the request
+func (req Request) Window() (from, to time.Time) {
+	from, _ = req.workingHours(req.FirstDay)
+	_, to = req.workingHours(req.LastDay)
+	return from.Add(-req.Buffer), to.Add(req.Buffer)
+}
+
+// Find returns free slots of the request ordered by time. Booked services take their time with buffers around it,
+// services without time take none.
+func Find(req Request, booked []models.Service) []Slot {
+	busy := busyRanges(booked, req.Buffer)
+
+	slots := make([]Slot, 0)
+	first := date(req.FirstDay, req.Location)
+	days := req.days()
+	for day := 0; day < days; day++ {
+		workStart, workEnd := req.workingHours(first.AddDate(0, 0, day))
+
+		for start := workStart; !start.Add(req.Duration).After(workEnd); start = start.Add(req.Step) {
+			if start.Before(req.Now) {
+				continue
+			}
+
+			slot := Slot{Start: start, End: start.Add(req.Duration)}
+			if !overlapsAny(slot, busy) {
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	return slots
+}
+
+// busyRange is [start, end) or the instant start if end equals it
+type busyRange struct {
+	start time.Time
+	end   time.Time
+}
+
+func busyRanges(services []models.Service, buffer time.Duration) []busyRange {
+	ranges := make([]busyRange, 0, len(services))
+	for _, service := range services {
+		if service.WhenUTC == nil {
+			continue
+		}
+
+		end := *service.WhenUTC
+		if service.EndUTC != nil {
+			end = *service.EndUTC
+		}
+		ranges = append(ranges, busyRange{start: service.WhenUTC.Add(-buffer), end: end.Add(buffer)})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Before(ranges[j].start)
+	})
+
+	return ranges
+}
+
+// overlapsAny uses the semantics of models.Service.Overlaps: the instant takes the slot it is in
+func overlapsAny(slot Slot, busy []busyRange) bool {
+	for _, r := range busy {
+		if !r.start.Before(slot.End) {
+			// Ranges are sorted by start, the rest start after the slot
+			return false
+		}
+
+		if r.start.Equal(r.end) {
+			if !r.start.Before(slot.Start) {
+				return true
+			}
+			continue
+		}
+		if r.end.After(slot.Start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// workingHours returns bounds of working hours of the day. Offsets are wall clock times, so hours are kept on days
+// of DST transitions. Times skipped by the transition are normalized by time.Date.
+func (req Request) workingHours(day time.Time) (start, end time.Time) {
+	year, month, dayOfMonth := day.Date()
+
+	at := func(offset time.Duration) time.Time {
+		return time.Date(year, month, dayOfMonth, 0, 0, int(offset/time.Second), 0, req.Location)
+	}
+
+	return at(req.WorkStart), at(req.WorkEnd)
+}
+
+func (req Request) days() int {
+	first := date(req.FirstDay, req.Location)
+	last := date(req.LastDay, req.Location)
+
+	days := 0
+	for day := first; !day.After(last) && days <= MaxDays; day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// date returns the midnight of the date of t in the location, the zone of t is ignored
+func date(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
@@ -0,0 +1,131 @@
+package slots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+func newTestRequest(t *testing.T, zone string) Request {
+	location, err := models.LoadTimeZone(zone)
+	require.NoError(t, err)
+
+	return Request{
+		FirstDay:  time.Date(2040, 1, 10, 0, 0, 0, 0, time.UTC),
+		LastDay:   time.Date(2040, 1, 10, 0, 0, 0, 0, time.UTC),
+		Location:  location,
+		WorkStart: 9 * time.Hour,
+		WorkEnd:   12 * time.Hour,
+		Duration:  time.Hour,
+		Step:      time.Hour,
+	}
+}
+
+func booked(start, end time.Time) models.Service {
+	return models.Service{WhenUTC: &start, EndUTC: &end}
+}
+
+func starts(slots []Slot) []string {
+	res := make([]string, len(slots))
+	for i, slot := range slots {
+		res[i] = slot.Start.Format("2006-01-02T15:04Z07:00")
+	}
+	return res
+}
+
+func TestFind_WhenNothingIsBooked_ShouldReturnWorkingHoursInZone(t *testing.T) {
+	req := newTestRequest(t, "Europe/Moscow")
+
+	got := Find(req, nil)
+
+	assert.Equal(t, []string{"2040-01-10T09:00+03:00", "2040-01-10T10:00+03:00", "2040-01-10T11:00+03:00"}, starts(got))
+	assert.Equal(t, got[0].Start.Add(time.Hour), got[0].End, "Slot should last the duration")
+}
+
+func TestFind_ShouldSkipBookedServicesWithBuffers(t *testing.T) {
+	req := newTestRequest(t, "UTC")
+	req.WorkStart = 8 * time.Hour
+	req.WorkEnd = 15 * time.Hour
+	req.Step = 30 * time.Minute
+	req.Buffer = 15 * time.Minute
+	at := func(hour, minute int) time.Time {
+		return time.Date(2040, 1, 10, hour, minute, 0, 0, time.UTC)
+	}
+	instant := at(13, 30)
+
+	got := Find(req, []models.Service{
+		booked(at(10, 0), at(11, 0)),
+		{WhenUTC: &instant},
+		{},
+	})
+
+	// 10:00-11:00 takes 9:45-11:15 and the instant at 13:30 takes 13:15-13:45
+	assert.Equal(t, []string{
+		"2040-01-10T08:00Z", "2040-01-10T08:30Z", "2040-01-10T11:30Z", "2040-01-10T12:00Z", "2040-01-10T14:00Z",
+	}, starts(got))
+}
+
+func TestFind_WhenBufferIsZero_ShouldSkipSlotsTakenByInstants(t *testing.T) {
+	req := newTestRequest(t, "UTC")
+	instant := time.Date(2040, 1, 10, 10, 0, 0, 0, time.UTC)
+
+	got := Find(req, []models.Service{{WhenUTC: &instant}})
+
+	assert.Equal(t, []string{"2040-01-10T09:00Z", "2040-01-10T11:00Z"}, starts(got))
+}
+
+func TestFind_WhenSlotEndsAtServiceStart_ShouldReturnIt(t *testing.T) {
+	req := newTestRequest(t, "UTC")
+	at := func(hour int) time.Time {
+		return time.Date(2040, 1, 10, hour, 0, 0, 0, time.UTC)
+	}
+
+	got := Find(req, []models.Service{booked(at(10), at(11))})
+
+	assert.Equal(t, []string{"2040-01-10T09:00Z", "2040-01-10T11:00Z"}, starts(got))
+}
+
+func TestFind_ShouldSkipSlotsInThePast(t *testing.T) {
+	req := newTestRequest(t, "UTC")
+	req.Now = time.Date(2040, 1, 10, 9, 30, 0, 0, time.UTC)
+
+	got := Find(req, nil)
+
+	assert.Equal(t, []string{"2040-01-10T10:00Z", "2040-01-10T11:00Z"}, starts(got))
+}
+
+func TestFind_WhenDSTStarts_ShouldKeepWallClockWorkingHours(t *testing.T) {
+	req := newTestRequest(t, "America/New_York")
+	req.FirstDay = time.Date(2040, 3, 10, 0, 0, 0, 0, time.UTC)
+	req.LastDay = time.Date(2040, 3, 11, 0, 0, 0, 0, time.UTC)
+	req.WorkEnd = 10 * time.Hour
+
+	got := Find(req, nil)
+
+	assert.Equal(t, []string{"2040-03-10T09:00-05:00", "2040-03-11T09:00-04:00"}, starts(got))
+}
+
+func TestRequest_Validate(t *testing.T) {
+	cases := map[string]func(req *Request){
+		"without zone":             func(req *Request) { req.Location = nil },
+		"without duration":         func(req *Request) { req.Duration = 0 },
+		"with too small step":      func(req *Request) { req.Step = time.Minute },
+		"with negative buffer":     func(req *Request) { req.Buffer = -time.Minute },
+		"with reversed hours":      func(req *Request) { req.WorkStart, req.WorkEnd = req.WorkEnd, req.WorkStart },
+		"with hours after the day": func(req *Request) { req.WorkEnd = 25 * time.Hour },
+		"with reversed days":       func(req *Request) { req.LastDay = req.FirstDay.AddDate(0, 0, -1) },
+		"with too long range":      func(req *Request) { req.LastDay = req.FirstDay.AddDate(0, 0, MaxDays) },
+	}
+
+	require.NoError(t, newTestRequest(t, "UTC").Validate(), "Valid request should pass")
+	for name, modify := range cases {
+		req := newTestRequest(t, "UTC")
+		modify(&req)
+
+		assert.Error(t, req.Validate(), "Request %s should be rejected", name)
+	}
+}
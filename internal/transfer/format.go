@@ -0,0 +1,345 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// Formats of the transfer files
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// maxLineSize is the max size of the JSONL record
+const maxLineSize = 1024 * 1024
+
+// ErrMalformedRecord is wrapped by the read errors of the single record, the following records can still be read
+var ErrMalformedRecord = errors.New("malformed record")
+
+// columns are the same as the CSV columns of the HTTP export, so the exported files can be imported back
+var columns = []string{"service_id", "user_id", "description", "service_name", "service_address", "when", "when_utc", "time_zone", "end"}
+
+// Record is the service in the transfer file. When and End are written in TimeZone with the offset. WhenUTC is
+// written for the reference only, it is derived from When on import.
+type Record struct {
+	ServiceID      string     `json:"service_id,omitempty"`
+	UserID         userID     `json:"user_id"`
+	Description    string     `json:"description,omitempty"`
+	ServiceName    string     `json:"service_name,omitempty"`
+	ServiceAddress string     `json:"service_address,omitempty"`
+	When           *time.Time `json:"when,omitempty"`
+	WhenUTC        *time.Time `json:"when_utc,omitempty"`
+	TimeZone       string     `json:"time_zone,omitempty"`
+	End            *time.Time `json:"end,omitempty"`
+}
+
+// userID is read from both the JSON number and the string, the HTTP export writes uint64 as string
+type userID uint64
+
+func (id *userID) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid user_id %s", data)
+	}
+
+	*id = userID(value)
+	return nil
+}
+
+func NewRecord(service models.Service) Record {
+	return Record{
+		ServiceID:      service.ID.String(),
+		UserID:         userID(service.UserID),
+		Description:    service.Description,
+		ServiceName:    service.ServiceName,
+		ServiceAddress: service.ServiceAddress,
+		When:           service.WhenLocal,
+		WhenUTC:        service.WhenUTC,
+		TimeZone:       service.TimeZone,
+		End:            service.EndLocal,
+	}
+}
+
+// Service validates the record through models.NewService. The time is restored like the time of the stored
+// service, so services in the past are imported back from backups. The service ID is kept if it is set,
+// so the repeated import of the same file fails instead of duplicating services, the generator gives it otherwise.
+func (r Record) Service(ids models.IDGenerator) (*models.Service, error) {
+	service, err := models.NewService(ids, uint64(r.UserID), r.Description, r.ServiceName, r.ServiceAddress, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = service.RestoreCalendar(r.When, r.End, r.TimeZone); err != nil {
+		return nil, err
+	}
+
+	if len(r.ServiceID) > 0 {
+		if service.ID, err = uuid.Parse(r.ServiceID); err != nil {
+			return nil, fmt.Errorf("invalid service_id %q", r.ServiceID)
+		}
+	}
+
+	return service, nil
+}
+
+// Reader reads records from the transfer file. Read returns io.EOF at the end of the file.
+type Reader interface {
+	Read() (Record, error)
+	// Line is the number of the last read record in the file starting from 1. For CSV it counts the header and
+	// matches the line in the file unless quoted fields contain line breaks.
+	Line() int
+}
+
+type Writer interface {
+	Write(record Record) error
+	Flush() error
+}
+
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+		return &jsonlReader{scanner: scanner}, nil
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		return &csvReader{reader: reader}, nil
+	default:
+		return nil, fmt.Errorf("unknown transfer format: %q", format)
+	}
+}
+
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown transfer format: %q", format)
+	}
+}
+
+// FormatFromPath returns the format by the file extension
+func FormatFromPath(path string) (string, error) {
+	switch {
+	case strings.HasSuffix(path, ".jsonl") || strings.HasSuffix(path, ".ndjson"):
+		return FormatJSONL, nil
+	case strings.HasSuffix(path, ".csv"):
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("can't detect the format of %q, set it explicitly", path)
+	}
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *jsonlReader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := strings.TrimSpace(r.scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return Record{}, fmt.Errorf("%w: %s", ErrMalformedRecord, err.Error())
+		}
+
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+
+	return Record{}, io.EOF
+}
+
+func (r *jsonlReader) Line() int {
+	return r.line
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	// header maps column names to the positions, columns may go in any order and unknown ones are ignored
+	header map[string]int
+	line   int
+}
+
+func (r *csvReader) Read() (Record, error) {
+	if r.header == nil {
+		if err := r.readHeader(); err != nil {
+			return Record{}, err
+		}
+	}
+
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+	r.line++
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Record{}, fmt.Errorf("%w: %s", ErrMalformedRecord, err.Error())
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	return r.parse(fields)
+}
+
+func (r *csvReader) readHeader() error {
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("can't read CSV header: %w", err)
+	}
+	r.line++
+
+	r.header = make(map[string]int, len(fields))
+	for i, name := range fields {
+		r.header[strings.TrimSpace(name)] = i
+	}
+
+	if _, ok := r.header["user_id"]; !ok {
+		return fmt.Errorf("CSV header has no user_id column")
+	}
+
+	return nil
+}
+
+func (r *csvReader) parse(fields []string) (Record, error) {
+	field := func(name string) string {
+		if i, ok := r.header[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	var record Record
+
+	value, err := strconv.ParseUint(field("user_id"), 10, 64)
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: invalid user_id %q", ErrMalformedRecord, field("user_id"))
+	}
+
+	record.ServiceID = field("service_id")
+	record.UserID = userID(value)
+	record.Description = field("description")
+	record.ServiceName = field("service_name")
+	record.ServiceAddress = field("service_address")
+	record.TimeZone = field("time_zone")
+
+	if record.When, err = parseCSVTime(field("when")); err != nil {
+		return Record{}, fmt.Errorf("%w: invalid when: %s", ErrMalformedRecord, err.Error())
+	}
+	if record.WhenUTC, err = parseCSVTime(field("when_utc")); err != nil {
+		return Record{}, fmt.Errorf("%w: invalid when_utc: %s", ErrMalformedRecord, err.Error())
+	}
+	if record.End, err = parseCSVTime(field("end")); err != nil {
+		return Record{}, fmt.Errorf("%w: invalid end: %s", ErrMalformedRecord, err.Error())
+	}
+
+	return record, nil
+}
+
+func (r *csvReader) Line() int {
+	return r.line
+}
+
+func parseCSVTime(value string) (*time.Time, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+type jsonlWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (w *jsonlWriter) Write(record Record) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.buffered.Flush()
+}
+
+type csvWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(record Record) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(columns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	return w.writer.Write([]string{
+		record.ServiceID,
+		strconv.FormatUint(uint64(record.UserID), 10),
+		record.Description,
+		record.ServiceName,
+		record.ServiceAddress,
+		formatCSVTime(record.When),
+		formatCSVTime(record.WhenUTC),
+		record.TimeZone,
+		formatCSVTime(record.End),
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	// Empty export still gets the header, so the file can be imported
+	if !w.headerWritten {
+		if err := w.writer.Write(columns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
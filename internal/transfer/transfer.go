@@ -0,0 +1,196 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+)
+
+// DefaultBatchSize is the number of services stored to the repo at once
+const DefaultBatchSize = 100
+
+type Source interface {
+	ExportServices(filter models.IntervalFilter, limit uint64, offset uint64, fn func(service models.Service) error) error
+}
+
+type Repo interface {
+	AddServices(services []models.Service) error
+}
+
+// Export writes services in the List order and returns the number of written ones. Zero limit exports all services,
+// the filter with the unbounded range exports services without time as well.
+func Export(source Source, writer Writer, filter models.IntervalFilter, limit uint64, offset uint64) (uint64, error) {
+	if limit == 0 {
+		limit = ^uint64(0)
+	}
+
+	var exported uint64
+	err := source.ExportServices(filter, limit, offset, func(service models.Service) error {
+		if err := writer.Write(NewRecord(service)); err != nil {
+			return err
+		}
+
+		exported++
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	return exported, writer.Flush()
+}
+
+// Options of the single import run
+type Options struct {
+	// DryRun validates records without storing them
+	DryRun bool
+	// BatchSize is DefaultBatchSize if not set
+	BatchSize int
+	// FromLine skips records before the line, NextLine of the interrupted run
+	FromLine int
+}
+
+// Failure is the record which is not imported
+type Failure struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Report is called for every failed record. Import is stopped if it returns error.
+type Report func(failure Failure) error
+
+// Progress of the import. All records before NextLine are either imported or reported as failed,
+// so the interrupted import can be resumed from it.
+type Progress struct {
+	Imported uint64
+	Failed   uint64
+	NextLine int
+}
+
+type pendingService struct {
+	service models.Service
+	line    int
+}
+
+// Importer stores services from the transfer file directly to the repo. No events are published,
+// run replay-events for the imported services if consumers need them.
+type Importer struct {
+	repo Repo
+	ids  models.IDGenerator
+}
+
+// NewImporter returns the importer giving records without service_id IDs of the generator
+func NewImporter(repo Repo, ids models.IDGenerator) *Importer {
+	return &Importer{repo: repo, ids: ids}
+}
+
+func (i *Importer) Run(ctx context.Context, reader Reader, options Options, report Report) (Progress, error) {
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	progress := Progress{NextLine: options.FromLine}
+	if progress.NextLine < 1 {
+		progress.NextLine = 1
+	}
+
+	batch := make([]pendingService, 0, batchSize)
+
+	fail := func(line int, err error) error {
+		progress.Failed++
+		if report == nil {
+			return nil
+		}
+		return report(Failure{Line: line, Error: err.Error()})
+	}
+
+	// failRecord reports the record which can't be added to the batch
+	failRecord := func(line int, err error) error {
+		if reportErr := fail(line, err); reportErr != nil {
+			return reportErr
+		}
+		if len(batch) == 0 {
+			progress.NextLine = line + 1
+		}
+		return nil
+	}
+
+	flush := func(nextLine int) error {
+		if len(batch) > 0 && !options.DryRun {
+			services := make([]models.Service, len(batch))
+			for j, pending := range batch {
+				services[j] = pending.service
+			}
+
+			if err := i.repo.AddServices(services); err != nil {
+				// The batch is stored in the single statement, so all of its services are failed
+				for _, pending := range batch {
+					if reportErr := fail(pending.line, err); reportErr != nil {
+						return reportErr
+					}
+				}
+				batch = batch[:0]
+				progress.NextLine = nextLine
+				return nil
+			}
+		}
+
+		progress.Imported += uint64(len(batch))
+		progress.NextLine = nextLine
+		batch = batch[:0]
+
+		log.Debug().Uint64("imported", progress.Imported).Int("next_line", progress.NextLine).Msg("Services batch is imported")
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			return progress, flush(reader.Line() + 1)
+		}
+
+		line := reader.Line()
+		if line < options.FromLine {
+			if err != nil && !errors.Is(err, ErrMalformedRecord) {
+				return progress, err
+			}
+			continue
+		}
+
+		if err != nil {
+			if !errors.Is(err, ErrMalformedRecord) {
+				return progress, err
+			}
+			if reportErr := failRecord(line, err); reportErr != nil {
+				return progress, reportErr
+			}
+			continue
+		}
+
+		service, err := record.Service(i.ids)
+		if err != nil {
+			if reportErr := failRecord(line, err); reportErr != nil {
+				return progress, reportErr
+			}
+			continue
+		}
+
+		batch = append(batch, pendingService{service: *service, line: line})
+		if len(batch) < batchSize {
+			continue
+		}
+
+		if err = flush(line + 1); err != nil {
+			return progress, err
+		}
+	}
+}
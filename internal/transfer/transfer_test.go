@@ -0,0 +1,184 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/models"
+	"github.com/ozonva/ova-service-api/internal/transfer"
+)
+
+type fakeRepo struct {
+	batches [][]models.Service
+	err     error
+}
+
+func (r *fakeRepo) AddServices(services []models.Service) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.batches = append(r.batches, services)
+	return nil
+}
+
+func (r *fakeRepo) ExportServices(_ models.IntervalFilter, _ uint64, _ uint64, fn func(service models.Service) error) error {
+	for _, batch := range r.batches {
+		for _, service := range batch {
+			if err := fn(service); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func runImport(t *testing.T, repo *fakeRepo, input string, format string, options transfer.Options) (transfer.Progress, []transfer.Failure, error) {
+	reader, err := transfer.NewReader(strings.NewReader(input), format)
+	require.NoError(t, err, "Reader should be created")
+
+	var failures []transfer.Failure
+	progress, err := transfer.NewImporter(repo, models.UUIDv7).Run(context.Background(), reader, options, func(failure transfer.Failure) error {
+		failures = append(failures, failure)
+		return nil
+	})
+
+	return progress, failures, err
+}
+
+func TestImporter_WhenJSONLContainsInvalidRecords_ShouldReportTheirLines(t *testing.T) {
+	repo := &fakeRepo{}
+	input := `{"user_id": 1, "service_name": "Car service"}
+not json
+
+{"user_id": 0, "service_name": "Service without user"}
+{"user_id": "2", "service_name": "Yacht service", "when": "2100-01-01T10:00:00+03:00"}
+`
+
+	progress, failures, err := runImport(t, repo, input, transfer.FormatJSONL, transfer.Options{BatchSize: 10})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, transfer.Progress{Imported: 2, Failed: 2, NextLine: 6}, progress, "Progress should count all records")
+	require.Len(t, failures, 2, "Invalid records should be reported")
+	assert.Equal(t, 2, failures[0].Line, "Malformed line should be reported")
+	assert.Equal(t, 4, failures[1].Line, "Line failed the validation should be reported")
+
+	require.Len(t, repo.batches, 1, "Services should be stored in the single batch")
+	assert.Equal(t, uint64(2), repo.batches[0][1].UserID, "User ID should be read from the string")
+	assert.Equal(t, time.Date(2100, 1, 1, 7, 0, 0, 0, time.UTC), repo.batches[0][1].WhenUTC.UTC(), "UTC time should be derived")
+}
+
+func TestImporter_WhenBatchIsFull_ShouldStoreItAndAdvanceNextLine(t *testing.T) {
+	repo := &fakeRepo{}
+	input := "user_id,service_name\n1,First\n1,Second\n1,Third\n"
+
+	progress, _, err := runImport(t, repo, input, transfer.FormatCSV, transfer.Options{BatchSize: 2})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, uint64(3), progress.Imported, "All services should be imported")
+	require.Len(t, repo.batches, 2, "Services should be stored in batches")
+	assert.Len(t, repo.batches[0], 2, "First batch should be full")
+}
+
+func TestImporter_WhenFromLineIsSet_ShouldSkipPreviousRecords(t *testing.T) {
+	repo := &fakeRepo{}
+	input := "user_id,service_name\n1,First\nbroken,Second\n1,Third\n"
+
+	progress, failures, err := runImport(t, repo, input, transfer.FormatCSV, transfer.Options{FromLine: 4})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, uint64(1), progress.Imported, "Only records after the line should be imported")
+	assert.Empty(t, failures, "Skipped records should not be reported")
+	assert.Equal(t, "Third", repo.batches[0][0].ServiceName, "Record at the line should be imported")
+}
+
+func TestImporter_WhenDryRun_ShouldNotStoreServices(t *testing.T) {
+	repo := &fakeRepo{}
+
+	progress, _, err := runImport(t, repo, `{"user_id": 1}`, transfer.FormatJSONL, transfer.Options{DryRun: true})
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, uint64(1), progress.Imported, "Valid services should be counted")
+	assert.Empty(t, repo.batches, "Services should not be stored")
+}
+
+func TestImporter_WhenRepoFails_ShouldReportAllServicesOfBatch(t *testing.T) {
+	repo := &fakeRepo{err: fmt.Errorf("duplicate key")}
+	input := "{\"user_id\": 1}\n{\"user_id\": 2}\n"
+
+	progress, failures, err := runImport(t, repo, input, transfer.FormatJSONL, transfer.Options{})
+
+	require.NoError(t, err, "Import should go on")
+	assert.Equal(t, transfer.Progress{Failed: 2, NextLine: 3}, progress, "Batch services should be failed")
+	assert.Len(t, failures, 2, "Batch services should be reported")
+}
+
+func TestExport_ShouldWriteRecordsWhichCanBeImported(t *testing.T) {
+	when := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	exported := models.Service{ID: uuid.New(), UserID: 1, ServiceName: "Car, boat and yacht service"}
+	end := when.Add(90 * time.Minute)
+	require.NoError(t, exported.RestoreCalendar(&when, &end, "Europe/Moscow"))
+	source := &fakeRepo{batches: [][]models.Service{{exported}}}
+
+	for _, format := range []string{transfer.FormatJSONL, transfer.FormatCSV} {
+		var buffer bytes.Buffer
+		writer, err := transfer.NewWriter(&buffer, format)
+		require.NoError(t, err, "Writer should be created")
+
+		exported, err := transfer.Export(source, writer, models.IntervalFilter{}, 0, 0)
+		require.NoError(t, err, "No error should be returned")
+		assert.Equal(t, uint64(1), exported, "All services should be exported")
+
+		reader, err := transfer.NewReader(&buffer, format)
+		require.NoError(t, err, "Reader should be created")
+
+		record, err := reader.Read()
+		require.NoError(t, err, "Exported record should be read in %s", format)
+		service, err := record.Service(models.UUIDv7)
+		require.NoError(t, err, "Exported record should be valid in %s", format)
+		assert.Equal(t, source.batches[0][0].ID, service.ID, "Service ID should be kept in %s", format)
+		assert.Equal(t, source.batches[0][0].ServiceName, service.ServiceName, "Fields should be kept in %s", format)
+		assert.True(t, when.Equal(*service.WhenLocal), "Time should be kept in %s", format)
+		assert.True(t, end.Equal(*service.EndLocal), "End should be kept in %s", format)
+		assert.Equal(t, "Europe/Moscow", service.TimeZone, "Time zone should be kept in %s", format)
+
+		_, err = reader.Read()
+		assert.Equal(t, io.EOF, err, "Only exported services should be read in %s", format)
+	}
+}
+
+func TestExport_WhenServiceIsInPast_ShouldBeImportedBack(t *testing.T) {
+	when := time.Date(2020, 3, 31, 10, 0, 0, 0, time.UTC)
+	end := when.Add(time.Hour)
+	exported := models.Service{ID: uuid.New(), UserID: 1, ServiceName: "Car service"}
+	require.NoError(t, exported.RestoreCalendar(&when, &end, "Europe/Moscow"))
+	source := &fakeRepo{batches: [][]models.Service{{exported}}}
+
+	for _, format := range []string{transfer.FormatJSONL, transfer.FormatCSV} {
+		var buffer bytes.Buffer
+		writer, err := transfer.NewWriter(&buffer, format)
+		require.NoError(t, err, "Writer should be created")
+		_, err = transfer.Export(source, writer, models.IntervalFilter{}, 0, 0)
+		require.NoError(t, err, "No error should be returned on export")
+
+		target := &fakeRepo{}
+		progress, failures, err := runImport(t, target, buffer.String(), format, transfer.Options{BatchSize: 10})
+
+		require.NoError(t, err, "No error should be returned on import")
+		assert.Empty(t, failures, "Past service should not fail in %s", format)
+		assert.Equal(t, uint64(1), progress.Imported, "Past service should be imported in %s", format)
+		require.Len(t, target.batches, 1, "Past service should be stored in %s", format)
+		assert.Equal(t, exported.ID, target.batches[0][0].ID, "Service ID should be kept in %s", format)
+		assert.True(t, when.Equal(*target.batches[0][0].WhenUTC), "Time should be restored in %s", format)
+		assert.True(t, end.Equal(*target.batches[0][0].EndUTC), "End should be restored in %s", format)
+	}
+}
@@ -0,0 +1,250 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// Default hub settings
+const (
+	DefaultHistory = 10000
+	DefaultBuffer  = 256
+)
+
+var (
+	// ErrInvalidCursor is returned for cursors which are not issued by any hub
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCursorExpired is returned when the changes after the cursor are not kept anymore: they are evicted
+	// from the history or the cursor is issued before the restart. The client has to resync with List.
+	ErrCursorExpired = errors.New("cursor is expired")
+	// ErrSlowSubscriber closes the subscription which doesn't keep up with changes
+	ErrSlowSubscriber = errors.New("subscriber is too slow")
+)
+
+// Change is the event with the cursor to resume watching after it.
+type Change struct {
+	Cursor string
+	Event  *pb.ServiceCUDEventV1
+}
+
+// Filter selects changes delivered to the subscription. Zero values match everything.
+type Filter struct {
+	UserID uint64
+}
+
+func (f Filter) match(event *pb.ServiceCUDEventV1) bool {
+	return f.UserID == 0 || event.GetUserId() == f.UserID
+}
+
+type entry struct {
+	seq   uint64
+	event *pb.ServiceCUDEventV1
+}
+
+// Hub fans changes out to subscribers and keeps the bounded history of recent changes, so subscribers
+// reconnecting with the cursor receive changes they missed. Cursors are "<epoch>-<sequence>", the epoch
+// changes on every start, so cursors of the previous process are reported as expired rather than misread.
+type Hub struct {
+	sync.Mutex
+	epoch         string
+	next          uint64
+	history       []entry
+	historySize   int
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewHub(historySize int) *Hub {
+	if historySize <= 0 {
+		historySize = DefaultHistory
+	}
+
+	return &Hub{
+		epoch:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		next:          1,
+		historySize:   historySize,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Ingest appends the event to the history and delivers it to matching subscribers.
+func (h *Hub) Ingest(event *pb.ServiceCUDEventV1) {
+	h.Lock()
+	defer h.Unlock()
+
+	e := entry{seq: h.next, event: event}
+	h.next++
+
+	h.history = append(h.history, e)
+	if len(h.history) > h.historySize {
+		// Copy to release the evicted head of the underlying array
+		h.history = append([]entry(nil), h.history[len(h.history)-h.historySize:]...)
+	}
+
+	for subscription := range h.subscriptions {
+		if subscription.filter.match(event) {
+			h.deliver(subscription, e)
+		}
+	}
+}
+
+// Subscribe returns the subscription receiving changes after the cursor, or live changes only if the cursor is empty.
+func (h *Hub) Subscribe(cursor string, filter Filter, buffer int) (*Subscription, error) {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	backlog, err := h.backlog(cursor, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Backlog is delivered through the same channel, so the buffer has to fit it
+	if len(backlog) > buffer {
+		buffer = len(backlog)
+	}
+
+	start := cursor
+	if len(start) == 0 {
+		start = h.cursor(h.next - 1)
+	}
+
+	subscription := &Subscription{
+		hub:     h,
+		filter:  filter,
+		start:   start,
+		changes: make(chan Change, buffer),
+	}
+
+	for _, e := range backlog {
+		subscription.changes <- h.change(e)
+	}
+
+	h.subscriptions[subscription] = struct{}{}
+	return subscription, nil
+}
+
+// Close closes all subscriptions.
+func (h *Hub) Close() {
+	h.Lock()
+	defer h.Unlock()
+
+	for subscription := range h.subscriptions {
+		h.unsubscribe(subscription, nil)
+	}
+}
+
+func (h *Hub) backlog(cursor string, filter Filter) ([]entry, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+
+	epoch, seq, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if epoch != h.epoch || seq >= h.next {
+		return nil, ErrCursorExpired
+	}
+
+	// The change right after the cursor must still be in the history
+	if seq+1 < h.next && (len(h.history) == 0 || h.history[0].seq > seq+1) {
+		return nil, ErrCursorExpired
+	}
+
+	var backlog []entry
+	for _, e := range h.history {
+		if e.seq > seq && filter.match(e.event) {
+			backlog = append(backlog, e)
+		}
+	}
+
+	return backlog, nil
+}
+
+// deliver never blocks the hub: the subscription which has no room for the change is closed with the error,
+// the client resumes from the last received cursor
+func (h *Hub) deliver(subscription *Subscription, e entry) {
+	select {
+	case subscription.changes <- h.change(e):
+	default:
+		h.unsubscribe(subscription, ErrSlowSubscriber)
+	}
+}
+
+func (h *Hub) unsubscribe(subscription *Subscription, err error) {
+	if _, ok := h.subscriptions[subscription]; !ok {
+		return
+	}
+
+	delete(h.subscriptions, subscription)
+	subscription.err = err
+	close(subscription.changes)
+}
+
+func (h *Hub) change(e entry) Change {
+	return Change{Cursor: h.cursor(e.seq), Event: e.event}
+}
+
+func (h *Hub) cursor(seq uint64) string {
+	return fmt.Sprintf("%s-%d", h.epoch, seq)
+}
+
+func parseCursor(cursor string) (string, uint64, error) {
+	parts := strings.SplitN(cursor, "-", 2)
+	if len(parts) != 2 {
+		return "", 0, ErrInvalidCursor
+	}
+
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+
+	return parts[0], seq, nil
+}
+
+// Subscription receives changes until it is closed by the client or by the hub.
+type Subscription struct {
+	hub     *Hub
+	filter  Filter
+	start   string
+	changes chan Change
+	err     error
+}
+
+// Start returns the cursor the subscription starts after, so the client is able to resume
+// even if it receives no changes.
+func (s *Subscription) Start() string {
+	return s.start
+}
+
+// Changes is closed when the subscription is closed, Err tells why.
+func (s *Subscription) Changes() <-chan Change {
+	return s.changes
+}
+
+// Err returns ErrSlowSubscriber if the hub closed the subscription because it didn't keep up, nil otherwise.
+// It must be called after Changes is closed.
+func (s *Subscription) Err() error {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+
+	s.hub.unsubscribe(s, nil)
+}
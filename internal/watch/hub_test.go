@@ -0,0 +1,128 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+func newEvent(id string, userID uint64) *pb.ServiceCUDEventV1 {
+	return &pb.ServiceCUDEventV1{EventId: id, UserId: userID}
+}
+
+func receive(t *testing.T, subscription *Subscription, count int) []Change {
+	changes := make([]Change, 0, count)
+	for i := 0; i < count; i++ {
+		select {
+		case change := <-subscription.Changes():
+			changes = append(changes, change)
+		default:
+			require.Failf(t, "Change is not delivered", "%d of %d changes are received", i, count)
+		}
+	}
+	return changes
+}
+
+func TestHub_WhenSubscribedWithFilter_ShouldDeliverMatchingLiveChanges(t *testing.T) {
+	hub := NewHub(10)
+	hub.Ingest(newEvent("before", 1))
+
+	subscription, err := hub.Subscribe("", Filter{UserID: 1}, 10)
+	require.NoError(t, err)
+	defer subscription.Close()
+
+	hub.Ingest(newEvent("other user", 2))
+	hub.Ingest(newEvent("live", 1))
+
+	changes := receive(t, subscription, 1)
+	assert.Equal(t, "live", changes[0].Event.EventId)
+	assert.Empty(t, subscription.Changes(), "Only matching live changes should be delivered")
+}
+
+func TestHub_WhenResumedFromCursor_ShouldDeliverMissedChangesFirst(t *testing.T) {
+	hub := NewHub(10)
+	first, err := hub.Subscribe("", Filter{}, 10)
+	require.NoError(t, err)
+
+	hub.Ingest(newEvent("received", 1))
+	received := receive(t, first, 1)
+	first.Close()
+
+	hub.Ingest(newEvent("missed", 1))
+
+	resumed, err := hub.Subscribe(received[0].Cursor, Filter{}, 10)
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	hub.Ingest(newEvent("live", 1))
+
+	changes := receive(t, resumed, 2)
+	assert.Equal(t, "missed", changes[0].Event.EventId)
+	assert.Equal(t, "live", changes[1].Event.EventId)
+	assert.Equal(t, received[0].Cursor, resumed.Start())
+}
+
+func TestHub_WhenSubscribedWithoutCursor_ShouldStartAfterLastChange(t *testing.T) {
+	hub := NewHub(10)
+	hub.Ingest(newEvent("before", 1))
+
+	subscription, err := hub.Subscribe("", Filter{}, 10)
+	require.NoError(t, err)
+	subscription.Close()
+
+	resumed, err := hub.Subscribe(subscription.Start(), Filter{}, 10)
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	assert.Empty(t, resumed.Changes(), "Changes before the start cursor should not be delivered")
+}
+
+func TestHub_WhenCursorIsEvicted_ShouldReturnExpired(t *testing.T) {
+	hub := NewHub(2)
+	subscription, err := hub.Subscribe("", Filter{}, 10)
+	require.NoError(t, err)
+	hub.Ingest(newEvent("evicted", 1))
+	cursor := receive(t, subscription, 1)[0].Cursor
+	subscription.Close()
+
+	for i := 0; i < 3; i++ {
+		hub.Ingest(newEvent("next", 1))
+	}
+
+	_, err = hub.Subscribe(cursor, Filter{}, 10)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+}
+
+func TestHub_WhenCursorIsIssuedByAnotherHub_ShouldReturnExpired(t *testing.T) {
+	previous := NewHub(10)
+	subscription, err := previous.Subscribe("", Filter{}, 10)
+	require.NoError(t, err)
+	previous.Ingest(newEvent("previous", 1))
+	cursor := receive(t, subscription, 1)[0].Cursor
+
+	hub := NewHub(10)
+	hub.epoch = previous.epoch + "0"
+
+	_, err = hub.Subscribe(cursor, Filter{}, 10)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+
+	_, err = hub.Subscribe("garbage", Filter{}, 10)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestHub_WhenSubscriberDoesNotKeepUp_ShouldCloseItWithError(t *testing.T) {
+	hub := NewHub(10)
+	subscription, err := hub.Subscribe("", Filter{}, 1)
+	require.NoError(t, err)
+
+	hub.Ingest(newEvent("buffered", 1))
+	hub.Ingest(newEvent("overflow", 1))
+
+	receive(t, subscription, 1)
+	_, ok := <-subscription.Changes()
+	assert.False(t, ok, "Subscription should be closed")
+	assert.ErrorIs(t, subscription.Err(), ErrSlowSubscriber)
+}
@@ -0,0 +1,63 @@
+package watch
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+	"github.com/ozonva/ova-service-api/pkg/consumer"
+)
+
+// Publisher feeds the hub with messages published by this service instance. It is teed with the event bus
+// publisher, so watchers see changes made through this instance only.
+type Publisher struct {
+	hub *Hub
+}
+
+func NewPublisher(hub *Hub) *Publisher {
+	return &Publisher{
+		hub: hub,
+	}
+}
+
+func (p *Publisher) Publish(message eventbus.Message) error {
+	event, err := consumer.Decode(message.Value, message.Headers)
+	if err != nil {
+		return err
+	}
+
+	p.hub.Ingest(event)
+	return nil
+}
+
+func (p *Publisher) PublishBatch(messages []eventbus.Message) error {
+	for _, message := range messages {
+		if err := p.Publish(message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return nil
+}
+
+// Handle feeds the hub with events of the consumer, so watchers see changes made through every instance.
+func (h *Hub) Handle(_ context.Context, event consumer.Event) error {
+	h.Ingest(event.Payload)
+	return nil
+}
+
+// Forward feeds the hub with messages of the memory broker subscription until it is closed.
+func (h *Hub) Forward(subscription *eventbus.Subscription) {
+	publisher := NewPublisher(h)
+
+	for message := range subscription.Messages() {
+		if err := publisher.Publish(message); err != nil {
+			log.Err(err).Msg("Failed to decode event for watchers")
+		}
+	}
+}
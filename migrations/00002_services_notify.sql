@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notifies listeners of the "service_changes" channel about every change of the services table.
+-- Notifications are delivered on commit only, the payload carries IDs, listeners load the state if they need it.
+CREATE OR REPLACE FUNCTION notify_service_change() RETURNS trigger AS $$
+DECLARE
+  changed services%ROWTYPE;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    changed := OLD;
+  ELSE
+    changed := NEW;
+  END IF;
+
+  PERFORM pg_notify('service_changes', json_build_object(
+    'op', TG_OP,
+    'service_id', changed.id,
+    'user_id', changed.user_id,
+    'at', now()
+  )::text);
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER services_notify
+  AFTER INSERT OR UPDATE OR DELETE ON services
+  FOR EACH ROW EXECUTE PROCEDURE notify_service_change();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER services_notify ON services;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION notify_service_change();
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Responses of create requests by the idempotency key. Rows without response belong to requests in progress.
+CREATE TABLE idempotency_keys
+(
+  scope VARCHAR(100) NOT NULL,
+  key VARCHAR(255) NOT NULL,
+  fingerprint VARCHAR(64) NOT NULL,
+  response BYTEA NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
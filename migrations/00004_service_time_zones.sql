@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Times are stored as absolute instants and the local time is computed from the IANA zone of the service.
+-- when_local kept the wall clock of the server zone rather than the customer one, so it is dropped.
+-- Zones of existing services are unknown, they get UTC.
+ALTER TABLE services ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE services ALTER COLUMN when_utc TYPE TIMESTAMPTZ USING when_utc AT TIME ZONE 'UTC';
+
+-- created_at and updated_at were filled by CURRENT_TIMESTAMP in the zone of the database session
+ALTER TABLE services ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('TimeZone');
+ALTER TABLE services ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE services DROP COLUMN when_local;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE services ADD COLUMN when_local TIMESTAMP NULL;
+UPDATE services SET when_local = when_utc AT TIME ZONE time_zone;
+
+ALTER TABLE services ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE current_setting('TimeZone');
+ALTER TABLE services ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('TimeZone');
+ALTER TABLE services ALTER COLUMN when_utc TYPE TIMESTAMP USING when_utc AT TIME ZONE 'UTC';
+
+ALTER TABLE services DROP COLUMN time_zone;
+-- +goose StatementEnd
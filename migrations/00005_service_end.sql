@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Services without the end take the single instant of when_utc
+ALTER TABLE services ADD COLUMN end_utc TIMESTAMPTZ NULL;
+
+ALTER TABLE services ADD CONSTRAINT services_end_after_start_check
+    CHECK (end_utc IS NULL OR (when_utc IS NOT NULL AND end_utc > when_utc));
+
+-- Interval filters of the list compare both bounds
+CREATE INDEX services_when_end_idx ON services (when_utc, end_utc);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX services_when_end_idx;
+ALTER TABLE services DROP COLUMN end_utc;
+-- +goose StatementEnd
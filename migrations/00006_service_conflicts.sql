@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Conflict rules are configured per deployment, so they are checked by the repo under advisory locks instead of
+-- exclusion constraints. These indexes serve the checks: the field equality and the overlap of service times.
+-- Ranges must match serviceRange of internal/repo/conflict.go.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE INDEX services_user_time_gist_idx ON services USING GIST (
+    user_id,
+    tstzrange(when_utc, COALESCE(end_utc, when_utc), CASE WHEN end_utc IS NULL THEN '[]' ELSE '[)' END)
+) WHERE when_utc IS NOT NULL;
+
+CREATE INDEX services_address_time_gist_idx ON services USING GIST (
+    service_address,
+    tstzrange(when_utc, COALESCE(end_utc, when_utc), CASE WHEN end_utc IS NULL THEN '[]' ELSE '[)' END)
+) WHERE when_utc IS NOT NULL;
+
+CREATE INDEX services_name_time_gist_idx ON services USING GIST (
+    service_name,
+    tstzrange(when_utc, COALESCE(end_utc, when_utc), CASE WHEN end_utc IS NULL THEN '[]' ELSE '[)' END)
+) WHERE when_utc IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX services_name_time_gist_idx;
+DROP INDEX services_address_time_gist_idx;
+DROP INDEX services_user_time_gist_idx;
+-- The extension may be used by other objects, so it is kept
+-- +goose StatementEnd
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// DefaultTimeout is the deadline of the call if the context has none, retries included
+const DefaultTimeout = 5 * time.Second
+
+// IdempotencyKeyHeader is the metadata key of the idempotency key sent with creates
+const IdempotencyKeyHeader = "idempotency-key"
+
+// defaultPageSize is the page size of ListPage if neither the size nor the token is passed, the default of the server
+const defaultPageSize = 100
+
+// balancedScheme is the scheme of the manual resolver which serves the endpoint list to the round robin balancer
+const balancedScheme = "ova-service-api"
+
+// ErrServiceNotFound is returned by Describe for unknown service IDs
+var ErrServiceNotFound = errors.New("service was not found")
+
+type Config struct {
+	// Endpoints are gRPC server addresses. Calls are balanced round robin across all of them,
+	// unavailable endpoints are skipped until they are reconnected.
+	Endpoints []string
+	// TLS config of the connection, plaintext connection is used if nil
+	TLS *tls.Config
+	// Timeout is DefaultTimeout if not set
+	Timeout time.Duration
+	Retry   RetryPolicy
+	// DialOptions are appended to the options of the client, e.g. to pass per RPC credentials or interceptors
+	DialOptions []grpc.DialOption
+}
+
+// Client is the ServiceAPI client with domain types, default deadlines and retries. It is safe for concurrent use.
+type Client struct {
+	conn    *grpc.ClientConn
+	api     pb.ServiceAPIClient
+	timeout time.Duration
+}
+
+// New doesn't wait for the connection, calls fail with Unavailable and are retried while servers are unreachable
+func New(config Config) (*Client, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("at least one endpoint is required")
+	}
+
+	opts := []grpc.DialOption{grpc.WithChainUnaryInterceptor(config.Retry.withDefaults().unaryInterceptor())}
+
+	if config.TLS != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(config.TLS)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	target := config.Endpoints[0]
+	if len(config.Endpoints) > 1 {
+		addresses := make([]resolver.Address, len(config.Endpoints))
+		for i, endpoint := range config.Endpoints {
+			addresses[i] = resolver.Address{Addr: endpoint}
+		}
+
+		r := manual.NewBuilderWithScheme(balancedScheme)
+		r.InitialState(resolver.State{Addresses: addresses})
+
+		target = r.Scheme() + ":///" + balancedScheme
+		opts = append(opts,
+			grpc.WithResolvers(r),
+			grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin": {}}]}`),
+		)
+	}
+
+	conn, err := grpc.Dial(target, append(opts, config.DialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Client{
+		conn:    conn,
+		api:     pb.NewServiceAPIClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// API returns the generated client for calls which have no domain methods, e.g. streams.
+// Unary calls of it are retried as well, but get no default deadline.
+func (c *Client) API() pb.ServiceAPIClient {
+	return c.api
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// CallOption configures the single create call
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey sets the key of the create, the repeated create with the same key returns IDs of the first one.
+// Random key is generated for every create by default, it protects from duplicates caused by retries only.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// ListOption filters services returned by List
+type ListOption func(*pb.ListServicesV1Request)
+
+// Overlapping lists services overlapping the [from, to) range, zero bounds leave it unbounded
+func Overlapping(from, to time.Time) ListOption {
+	return func(req *pb.ListServicesV1Request) {
+		req.From, req.To, req.Within = toTimestamp(nonZero(from)), toTimestamp(nonZero(to)), false
+	}
+}
+
+// Within lists services which take place entirely inside the [from, to) range, zero bounds leave it unbounded
+func Within(from, to time.Time) ListOption {
+	return func(req *pb.ListServicesV1Request) {
+		req.From, req.To, req.Within = toTimestamp(nonZero(from)), toTimestamp(nonZero(to)), true
+	}
+}
+
+// withDefaults applies the default deadline if the context has none
+func (c *Client) withDefaults(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// withIdempotencyKey adds the key to the outgoing metadata, retries of the call send the same key
+func withIdempotencyKey(ctx context.Context, opts []CallOption) context.Context {
+	options := callOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if len(options.idempotencyKey) == 0 {
+		options.idempotencyKey = uuid.New().String()
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, IdempotencyKeyHeader, options.idempotencyKey)
+}
+
+func (c *Client) Create(ctx context.Context, service NewService, opts ...CallOption) (uuid.UUID, error) {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	res, err := c.api.CreateServiceV1(withIdempotencyKey(ctx, opts), service.toRequest())
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := uuid.Parse(res.ServiceId)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid service ID in response: %w", err)
+	}
+
+	return id, nil
+}
+
+// MultiCreate returns IDs in the order of services
+func (c *Client) MultiCreate(ctx context.Context, services []NewService, opts ...CallOption) ([]uuid.UUID, error) {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	req := &pb.MultiCreateServiceV1Request{CreateService: make([]*pb.CreateServiceV1Request, len(services))}
+	for i, service := range services {
+		req.CreateService[i] = service.toRequest()
+	}
+
+	res, err := c.api.MultiCreateServiceV1(withIdempotencyKey(ctx, opts), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseServiceIDs(res.ServiceId)
+}
+
+// Describe returns ErrServiceNotFound for unknown service IDs
+func (c *Client) Describe(ctx context.Context, id uuid.UUID) (Service, error) {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	res, err := c.api.DescribeServiceV1(ctx, &pb.DescribeServiceV1Request{ServiceId: id.String()})
+	if status.Code(err) == codes.NotFound {
+		return Service{}, fmt.Errorf("%w: %s", ErrServiceNotFound, id.String())
+	}
+	if err != nil {
+		return Service{}, err
+	}
+
+	return serviceFromResponse(res)
+}
+
+// List returns all services unless the interval is set by Overlapping or Within
+func (c *Client) List(ctx context.Context, opts ...ListOption) ([]ServiceInfo, error) {
+	req := &pb.ListServicesV1Request{}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	infos, _, err := c.list(ctx, req)
+	return infos, err
+}
+
+// ListPage returns the page of services after the page of the token, the first page if it is empty.
+// The returned token of the next page is empty after the last page. The page size is limited by the server,
+// its default is used if it is 0.
+func (c *Client) ListPage(ctx context.Context, pageSize uint32, pageToken string) ([]ServiceInfo, string, error) {
+	if pageSize == 0 && len(pageToken) == 0 {
+		// The request without both lists all services
+		pageSize = defaultPageSize
+	}
+
+	return c.list(ctx, &pb.ListServicesV1Request{PageSize: pageSize, PageToken: pageToken})
+}
+
+func (c *Client) list(ctx context.Context, req *pb.ListServicesV1Request) ([]ServiceInfo, string, error) {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	res, err := c.api.ListServicesV1(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	infos := make([]ServiceInfo, len(res.ServiceShortInfo))
+	for i, info := range res.ServiceShortInfo {
+		if infos[i], err = serviceInfoFromResponse(info); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return infos, res.NextPageToken, nil
+}
+
+// Update replaces all fields of the service, WhenUTC is derived from When by the server, the service without End
+// becomes the single instant
+func (c *Client) Update(ctx context.Context, service Service) error {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	_, err := c.api.UpdateServiceV1(ctx, &pb.UpdateServiceV1Request{
+		ServiceId:      service.ID.String(),
+		UserId:         service.UserID,
+		Description:    service.Description,
+		ServiceName:    service.Name,
+		ServiceAddress: service.Address,
+		When:           toTimestamp(service.When),
+		TimeZone:       service.TimeZone,
+		End:            toTimestamp(service.End),
+	})
+
+	return err
+}
+
+// FindSlots returns free slots ordered by time
+func (c *Client) FindSlots(ctx context.Context, query SlotQuery) ([]Slot, error) {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	res, err := c.api.FindAvailableSlotsV1(ctx, query.toRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make([]Slot, len(res.Slots))
+	for i, slot := range res.Slots {
+		start, end := inTimeZone(fromTimestamp(slot.Start), res.TimeZone), inTimeZone(fromTimestamp(slot.End), res.TimeZone)
+		if start == nil || end == nil {
+			return nil, fmt.Errorf("slot without time in response")
+		}
+		slots[i] = Slot{Start: *start, End: *end}
+	}
+
+	return slots, nil
+}
+
+func (c *Client) Remove(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := c.withDefaults(ctx)
+	defer cancel()
+
+	_, err := c.api.RemoveServiceV1(ctx, &pb.RemoveServiceV1Request{ServiceId: id.String()})
+	return err
+}
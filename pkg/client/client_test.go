@@ -0,0 +1,209 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ozonva/ova-service-api/pkg/client"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// fakeServer fails the first calls with the configured errors and records idempotency keys of creates
+type fakeServer struct {
+	pb.UnimplementedServiceAPIServer
+	name string
+
+	mu       sync.Mutex
+	failures []error
+	keys     []string
+	calls    int
+}
+
+func (s *fakeServer) nextFailure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if len(s.failures) == 0 {
+		return nil
+	}
+
+	err := s.failures[0]
+	s.failures = s.failures[1:]
+	return err
+}
+
+func (s *fakeServer) CreateServiceV1(ctx context.Context, _ *pb.CreateServiceV1Request) (*pb.CreateServiceV1Response, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	s.mu.Lock()
+	s.keys = append(s.keys, md.Get(client.IdempotencyKeyHeader)...)
+	s.mu.Unlock()
+
+	if err := s.nextFailure(); err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateServiceV1Response{ServiceId: "d6fa505c-6072-4a45-bdae-86e6b13d7342"}, nil
+}
+
+func (s *fakeServer) DescribeServiceV1(_ context.Context, req *pb.DescribeServiceV1Request) (*pb.DescribeServiceV1Response, error) {
+	if err := s.nextFailure(); err != nil {
+		return nil, err
+	}
+
+	return &pb.DescribeServiceV1Response{ServiceId: req.ServiceId, UserId: 1, ServiceName: s.name}, nil
+}
+
+// ListServicesV1 serves two pages of the single service if the page is requested
+func (s *fakeServer) ListServicesV1(_ context.Context, req *pb.ListServicesV1Request) (*pb.ListServicesV1Response, error) {
+	if err := s.nextFailure(); err != nil {
+		return nil, err
+	}
+
+	if req.PageSize == 0 && len(req.PageToken) == 0 {
+		return &pb.ListServicesV1Response{}, nil
+	}
+
+	res := &pb.ListServicesV1Response{ServiceShortInfo: []*pb.ServiceShortInfoV1Response{
+		{ServiceId: "d6fa505c-6072-4a45-bdae-86e6b13d7342", UserId: 1},
+	}}
+	if len(req.PageToken) == 0 {
+		res.NextPageToken = "second"
+	}
+	return res, nil
+}
+
+// startServers serves fake servers on in-memory listeners named by the server name
+func startServers(t *testing.T, servers ...*fakeServer) client.Config {
+	listeners := make(map[string]*bufconn.Listener)
+	config := client.Config{
+		Retry: client.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	}
+
+	for _, server := range servers {
+		listener := bufconn.Listen(1024 * 1024)
+		grpcServer := grpc.NewServer()
+		pb.RegisterServiceAPIServer(grpcServer, server)
+		go func() {
+			_ = grpcServer.Serve(listener)
+		}()
+		t.Cleanup(grpcServer.Stop)
+
+		listeners[server.name] = listener
+		config.Endpoints = append(config.Endpoints, server.name)
+	}
+
+	config.DialOptions = []grpc.DialOption{grpc.WithContextDialer(func(_ context.Context, address string) (net.Conn, error) {
+		return listeners[address].Dial()
+	})}
+
+	return config
+}
+
+func newClient(t *testing.T, config client.Config) *client.Client {
+	c, err := client.New(config)
+	require.NoError(t, err, "Client should be created")
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+func TestClient_WhenServerIsUnavailable_ShouldRetryCreateWithTheSameIdempotencyKey(t *testing.T) {
+	server := &fakeServer{name: "first", failures: []error{
+		status.Error(codes.Unavailable, "restarting"),
+		status.Error(codes.Aborted, "conflict"),
+	}}
+	c := newClient(t, startServers(t, server))
+
+	id, err := c.Create(context.Background(), client.NewService{UserID: 1, Name: "Car service"})
+
+	require.NoError(t, err, "Create should succeed after retries")
+	assert.Equal(t, uuid.MustParse("d6fa505c-6072-4a45-bdae-86e6b13d7342"), id, "Created service ID should be returned")
+	require.Len(t, server.keys, 3, "Every attempt should send the idempotency key")
+	assert.NotEmpty(t, server.keys[0], "Idempotency key should be generated")
+	assert.Equal(t, server.keys[0], server.keys[2], "Retries should reuse the idempotency key")
+}
+
+func TestClient_WhenIdempotencyKeyIsPassed_ShouldSendIt(t *testing.T) {
+	server := &fakeServer{name: "first"}
+	c := newClient(t, startServers(t, server))
+
+	_, err := c.Create(context.Background(), client.NewService{UserID: 1}, client.WithIdempotencyKey("import-42"))
+
+	require.NoError(t, err, "Create should succeed")
+	assert.Equal(t, []string{"import-42"}, server.keys, "Passed key should be sent")
+}
+
+func TestClient_WhenErrorIsNotRetryable_ShouldReturnItAtOnce(t *testing.T) {
+	server := &fakeServer{name: "first", failures: []error{status.Error(codes.InvalidArgument, "invalid")}}
+	c := newClient(t, startServers(t, server))
+
+	_, err := c.List(context.Background())
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Server error should be returned")
+	assert.Equal(t, 1, server.calls, "Call should not be retried")
+}
+
+func TestClient_WhenAttemptsAreExhausted_ShouldReturnTheLastError(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "down")
+	server := &fakeServer{name: "first", failures: []error{unavailable, unavailable, unavailable, unavailable}}
+	c := newClient(t, startServers(t, server))
+
+	_, err := c.List(context.Background())
+
+	assert.Equal(t, codes.Unavailable, status.Code(err), "Last error should be returned")
+	assert.Equal(t, client.DefaultMaxAttempts, server.calls, "Call should be attempted MaxAttempts times")
+}
+
+func TestClient_WhenServiceIsUnknown_ShouldReturnErrServiceNotFound(t *testing.T) {
+	server := &fakeServer{name: "first", failures: []error{status.Error(codes.NotFound, "not found")}}
+	c := newClient(t, startServers(t, server))
+
+	_, err := c.Describe(context.Background(), uuid.New())
+
+	assert.ErrorIs(t, err, client.ErrServiceNotFound, "Not found error should be mapped")
+}
+
+func TestClient_ListPage_ShouldReturnTokenOfNextPage(t *testing.T) {
+	c := newClient(t, startServers(t, &fakeServer{name: "first"}))
+
+	first, token, err := c.ListPage(context.Background(), 0, "")
+	require.NoError(t, err)
+	assert.Len(t, first, 1, "The first page should be requested without the token")
+	assert.Equal(t, "second", token)
+
+	second, token, err := c.ListPage(context.Background(), 0, token)
+	require.NoError(t, err)
+	assert.Len(t, second, 1)
+	assert.Empty(t, token, "The last page should have no token")
+}
+
+func TestClient_WhenSeveralEndpoints_ShouldBalanceCalls(t *testing.T) {
+	first := &fakeServer{name: "first"}
+	second := &fakeServer{name: "second"}
+	c := newClient(t, startServers(t, first, second))
+
+	served := make(map[string]int)
+	for i := 0; i < 20; i++ {
+		service, err := c.Describe(context.Background(), uuid.New())
+		require.NoError(t, err, "Describe should succeed")
+		served[service.Name]++
+	}
+
+	assert.Greater(t, served["first"], 0, "First endpoint should serve calls")
+	assert.Greater(t, served["second"], 0, "Second endpoint should serve calls")
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// dateLayout is the layout of days of SlotQuery
+const dateLayout = "2006-01-02"
+
+// Service is the stored service returned by Describe
+type Service struct {
+	ID          uuid.UUID
+	UserID      uint64
+	Description string
+	Name        string
+	Address     string
+	// TimeZone is the IANA zone of the service, When is the service time in it and WhenUTC is the same instant in UTC
+	TimeZone string
+	When     *time.Time
+	WhenUTC  *time.Time
+	// End is the end of the service in TimeZone, the service is the single instant if it is nil
+	End *time.Time
+}
+
+// ServiceInfo is the short service representation returned by List
+type ServiceInfo struct {
+	ID       uuid.UUID
+	UserID   uint64
+	Name     string
+	TimeZone string
+	When     *time.Time
+	End      *time.Time
+}
+
+// NewService contains fields of the service to create, the ID is assigned by the server
+type NewService struct {
+	UserID      uint64
+	Description string
+	Name        string
+	Address     string
+	// TimeZone is the IANA zone of the service address, the server uses UTC if it is empty
+	TimeZone string
+	When     *time.Time
+	// End must be after When, the service is the single instant if it is nil
+	End *time.Time
+}
+
+func (s NewService) toRequest() *pb.CreateServiceV1Request {
+	return &pb.CreateServiceV1Request{
+		UserId:         s.UserID,
+		Description:    s.Description,
+		ServiceName:    s.Name,
+		ServiceAddress: s.Address,
+		When:           toTimestamp(s.When),
+		TimeZone:       s.TimeZone,
+		End:            toTimestamp(s.End),
+	}
+}
+
+// SlotQuery describes free slots to find for the service name or the address, exactly one of them must be set
+type SlotQuery struct {
+	ServiceName    string
+	ServiceAddress string
+	// FirstDay and LastDay are dates of the range in TimeZone, both are included. Only their dates are used.
+	FirstDay time.Time
+	LastDay  time.Time
+	// TimeZone is the IANA zone of dates and working hours, the server uses UTC if it is empty
+	TimeZone string
+	// WorkStart and WorkEnd are offsets of working hours from the midnight, e.g. 9 * time.Hour
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	Duration  time.Duration
+	// Step is Duration, or 5 minutes for shorter slots, if zero
+	Step time.Duration
+	// Buffer is the free time kept around booked services, the server buffer is used if it is nil
+	Buffer *time.Duration
+}
+
+// Slot is the free time in the zone of the query
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (q SlotQuery) toRequest() *pb.FindAvailableSlotsV1Request {
+	req := &pb.FindAvailableSlotsV1Request{
+		ServiceName:    q.ServiceName,
+		ServiceAddress: q.ServiceAddress,
+		FirstDay:       q.FirstDay.Format(dateLayout),
+		LastDay:        q.LastDay.Format(dateLayout),
+		TimeZone:       q.TimeZone,
+		WorkStart:      formatClock(q.WorkStart),
+		WorkEnd:        formatClock(q.WorkEnd),
+		SlotDuration:   durationpb.New(q.Duration),
+	}
+	if q.Step > 0 {
+		req.Step = durationpb.New(q.Step)
+	}
+	if q.Buffer != nil {
+		req.Buffer = durationpb.New(*q.Buffer)
+	}
+
+	return req
+}
+
+// formatClock formats the offset from the midnight as "15:04", the server accepts "24:00" as the end of the day
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
+
+func serviceFromResponse(res *pb.DescribeServiceV1Response) (Service, error) {
+	id, err := uuid.Parse(res.ServiceId)
+	if err != nil {
+		return Service{}, fmt.Errorf("invalid service ID in response: %w", err)
+	}
+
+	return Service{
+		ID:          id,
+		UserID:      res.UserId,
+		Description: res.Description,
+		Name:        res.ServiceName,
+		Address:     res.ServiceAddress,
+		TimeZone:    res.TimeZone,
+		When:        inTimeZone(fromTimestamp(res.When), res.TimeZone),
+		WhenUTC:     fromTimestamp(res.WhenUtc),
+		End:         inTimeZone(fromTimestamp(res.End), res.TimeZone),
+	}, nil
+}
+
+func serviceInfoFromResponse(res *pb.ServiceShortInfoV1Response) (ServiceInfo, error) {
+	id, err := uuid.Parse(res.ServiceId)
+	if err != nil {
+		return ServiceInfo{}, fmt.Errorf("invalid service ID in response: %w", err)
+	}
+
+	return ServiceInfo{
+		ID:       id,
+		UserID:   res.UserId,
+		Name:     res.ServiceName,
+		TimeZone: res.TimeZone,
+		When:     inTimeZone(fromTimestamp(res.When), res.TimeZone),
+		End:      inTimeZone(fromTimestamp(res.End), res.TimeZone),
+	}, nil
+}
+
+func parseServiceIDs(ids []string) ([]uuid.UUID, error) {
+	parsed := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		var err error
+		if parsed[i], err = uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("invalid service ID in response: %w", err)
+		}
+	}
+	return parsed, nil
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func nonZero(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// inTimeZone returns t in the zone, or in UTC if the zone is unknown to the local zone database
+func inTimeZone(t *time.Time, zone string) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	location, err := time.LoadLocation(zone)
+	if err != nil || zone == "Local" {
+		location = time.UTC
+	}
+
+	local := t.In(location)
+	return &local
+}
@@ -0,0 +1,203 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ozonva/ova-service-api/internal/eventbus"
+)
+
+// DefaultTopic is the topic ova-service-api produces events to.
+const DefaultTopic = "services"
+
+// ErrorHandler is called when the message can't be decoded or the handler fails. The message is marked
+// as consumed anyway, so a single broken message doesn't block the partition.
+type ErrorHandler func(message *sarama.ConsumerMessage, err error)
+
+type Config struct {
+	Brokers []string
+	// Topic to consume, DefaultTopic if empty
+	Topic string
+	// GroupID of the consumer group, members of the same group share the partitions
+	GroupID string
+	// FromBeginning starts the new group from the oldest offset instead of the newest one
+	FromBeginning bool
+	// Idempotency store, in-memory store with DefaultIdempotencyCapacity is used if nil
+	Idempotency IdempotencyStore
+	// OnError is called on decoding and handling errors, errors are logged if nil
+	OnError ErrorHandler
+	// Sarama allows to override the client configuration, e.g. to set up TLS
+	Sarama *sarama.Config
+}
+
+// Consumer reads events from the topic as the member of the consumer group and dispatches them to the router.
+type Consumer struct {
+	topic   string
+	group   sarama.ConsumerGroup
+	handler *groupHandler
+}
+
+func New(config Config, router *Router) (*Consumer, error) {
+	if len(config.GroupID) == 0 {
+		return nil, fmt.Errorf("consumer group ID is required")
+	}
+
+	saramaConfig := config.Sarama
+	if saramaConfig == nil {
+		saramaConfig = sarama.NewConfig()
+		// Skip messages of aborted transactions produced by the transactional producer
+		saramaConfig.Consumer.IsolationLevel = sarama.ReadCommitted
+	}
+
+	if config.FromBeginning {
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+
+	group, err := sarama.NewConsumerGroup(config.Brokers, config.GroupID, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := config.Topic
+	if len(topic) == 0 {
+		topic = DefaultTopic
+	}
+
+	return &Consumer{
+		topic:   topic,
+		group:   group,
+		handler: newGroupHandler(router, config.Idempotency, config.OnError),
+	}, nil
+}
+
+// Run consumes the topic until the context is canceled. Consumption is restarted after every rebalance.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		err := c.group.Consume(ctx, []string{c.topic}, c.handler)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) Close() error {
+	return c.group.Close()
+}
+
+// groupHandler implements sarama.ConsumerGroupHandler
+type groupHandler struct {
+	router      *Router
+	idempotency IdempotencyStore
+	onError     ErrorHandler
+}
+
+func newGroupHandler(router *Router, idempotency IdempotencyStore, onError ErrorHandler) *groupHandler {
+	if idempotency == nil {
+		idempotency = NewMemoryIdempotencyStore(DefaultIdempotencyCapacity)
+	}
+
+	if onError == nil {
+		onError = logError
+	}
+
+	return &groupHandler{
+		router:      router,
+		idempotency: idempotency,
+		onError:     onError,
+	}
+}
+
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for message := range claim.Messages() {
+		if err := h.process(session.Context(), message); err != nil {
+			h.onError(message, err)
+		}
+
+		session.MarkMessage(message, "")
+	}
+
+	return nil
+}
+
+func (h *groupHandler) process(ctx context.Context, message *sarama.ConsumerMessage) error {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	payload, err := Decode(message.Value, headers)
+	if err != nil {
+		return err
+	}
+
+	if h.idempotency.Processed(payload.EventId) {
+		log.Debug().Str("event_id", payload.EventId).Msg("Event is already processed, skipping")
+		return nil
+	}
+
+	span := startConsumeSpan(message, headers)
+	defer span.Finish()
+
+	event := Event{
+		Payload:   payload,
+		Key:       message.Key,
+		Headers:   headers,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+	}
+
+	if err = h.router.Dispatch(opentracing.ContextWithSpan(ctx, span), event); err != nil {
+		ext.Error.Set(span, true)
+		return err
+	}
+
+	h.idempotency.MarkProcessed(payload.EventId)
+	return nil
+}
+
+// startConsumeSpan continues the trace started by the producer if the message carries the span context.
+func startConsumeSpan(message *sarama.ConsumerMessage, headers map[string]string) opentracing.Span {
+	options := []opentracing.StartSpanOption{
+		ext.SpanKindConsumer,
+		opentracing.Tag{Key: "partition", Value: message.Partition},
+		opentracing.Tag{Key: "offset", Value: message.Offset},
+	}
+
+	tracer := opentracing.GlobalTracer()
+	parent, err := eventbus.ExtractSpanContext(tracer, eventbus.Message{Headers: headers})
+	if err == nil {
+		options = append(options, opentracing.FollowsFrom(parent))
+	}
+
+	return tracer.StartSpan("ConsumeEvent", options...)
+}
+
+func logError(message *sarama.ConsumerMessage, err error) {
+	log.Err(err).
+		Int32("partition", message.Partition).
+		Int64("offset", message.Offset).
+		Msg("Failed to process event")
+}
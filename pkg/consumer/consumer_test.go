@@ -0,0 +1,154 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+type fakeSession struct {
+	marked []int64
+}
+
+func (s *fakeSession) Claims() map[string][]int32               { return nil }
+func (s *fakeSession) MemberID() string                         { return "" }
+func (s *fakeSession) GenerationID() int32                      { return 0 }
+func (s *fakeSession) MarkOffset(string, int32, int64, string)  {}
+func (s *fakeSession) Commit()                                  {}
+func (s *fakeSession) ResetOffset(string, int32, int64, string) {}
+func (s *fakeSession) Context() context.Context                 { return context.Background() }
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeClaim(messages ...*sarama.ConsumerMessage) *fakeClaim {
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(messages))}
+	for _, message := range messages {
+		claim.messages <- message
+	}
+	close(claim.messages)
+	return claim
+}
+
+func (c *fakeClaim) Topic() string                            { return DefaultTopic }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func newConsumerMessage(t *testing.T, event events.ServiceCUDEvent, offset int64) *sarama.ConsumerMessage {
+	encoder, err := events.NewEncoder(events.EncoderConfig{})
+	require.NoError(t, err, "Encoder should be created")
+
+	message, err := encoder.Encode(event)
+	require.NoError(t, err, "Event should be encoded")
+
+	var headers []*sarama.RecordHeader
+	for key, value := range message.Headers {
+		headers = append(headers, &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	return &sarama.ConsumerMessage{
+		Topic:   DefaultTopic,
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+		Offset:  offset,
+	}
+}
+
+func TestGroupHandler_WhenEventIsRedelivered_ShouldHandleItOnce(t *testing.T) {
+	event := events.NewServiceCreateEvent(serviceID, 42)
+	var handled []string
+
+	router := NewRouter().HandleCreated(func(ctx context.Context, event Event) error {
+		handled = append(handled, event.Payload.EventId)
+		return nil
+	})
+	handler := newGroupHandler(router, nil, nil)
+	session := &fakeSession{}
+
+	err := handler.ConsumeClaim(session, newFakeClaim(
+		newConsumerMessage(t, event, 1),
+		newConsumerMessage(t, event, 2),
+	))
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, []string{event.EventID.String()}, handled, "Redelivered event should be skipped")
+	assert.Equal(t, []int64{1, 2}, session.marked, "Every message should be marked")
+}
+
+func TestGroupHandler_WhenHandlerFails_ShouldReportErrorAndRetryOnRedelivery(t *testing.T) {
+	event := events.NewServiceDeleteEvent(serviceID, 42)
+	attempts := 0
+	var failedOffsets []int64
+
+	router := NewRouter().HandleDeleted(func(ctx context.Context, event Event) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("storage is unavailable")
+		}
+		return nil
+	})
+	onError := func(message *sarama.ConsumerMessage, err error) {
+		failedOffsets = append(failedOffsets, message.Offset)
+	}
+	handler := newGroupHandler(router, nil, onError)
+	session := &fakeSession{}
+
+	err := handler.ConsumeClaim(session, newFakeClaim(
+		newConsumerMessage(t, event, 1),
+		newConsumerMessage(t, event, 2),
+	))
+
+	require.NoError(t, err, "No error should be returned")
+	assert.Equal(t, 2, attempts, "Failed event should not be marked as processed")
+	assert.Equal(t, []int64{1}, failedOffsets, "Failure should be reported")
+	assert.Equal(t, []int64{1, 2}, session.marked, "Every message should be marked")
+}
+
+func TestGroupHandler_WhenMessageCannotBeDecoded_ShouldReportError(t *testing.T) {
+	var errs []error
+	handler := newGroupHandler(NewRouter(), nil, func(message *sarama.ConsumerMessage, err error) {
+		errs = append(errs, err)
+	})
+
+	err := handler.ConsumeClaim(&fakeSession{}, newFakeClaim(&sarama.ConsumerMessage{Value: []byte("garbage")}))
+
+	require.NoError(t, err, "No error should be returned")
+	require.Len(t, errs, 1, "Decoding error should be reported")
+	assert.Equal(t, "message has no content-type header", errs[0].Error(), "Incorrect error message")
+}
+
+func TestRouter_WhenNoHandlerIsRegistered_ShouldUseFallback(t *testing.T) {
+	var handledTypes []pb.ServiceEventTypeV1
+	record := func(ctx context.Context, event Event) error {
+		handledTypes = append(handledTypes, event.Payload.EventType)
+		return nil
+	}
+	router := NewRouter().HandleCreated(record)
+
+	created := Event{Payload: &pb.ServiceCUDEventV1{EventType: pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED}}
+	updated := Event{Payload: &pb.ServiceCUDEventV1{EventType: pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED}}
+
+	require.NoError(t, router.Dispatch(context.Background(), updated), "Event without handler should be ignored")
+	router.HandleOther(record)
+	require.NoError(t, router.Dispatch(context.Background(), created))
+	require.NoError(t, router.Dispatch(context.Background(), updated))
+
+	assert.Equal(t, []pb.ServiceEventTypeV1{
+		pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED,
+		pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED,
+	}, handledTypes)
+}
@@ -0,0 +1,48 @@
+package consumer
+
+import (
+	"fmt"
+	"mime"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// Decode converts the message produced by ova-service-api to the event. Payload format is taken
+// from the content-type header, so both native and CloudEvents binary messages are supported.
+// Unknown fields are ignored to keep consumers compatible with newer minor versions of the schema.
+func Decode(value []byte, headers map[string]string) (*pb.ServiceCUDEventV1, error) {
+	contentType, ok := headers[events.ContentTypeHeader]
+	if !ok {
+		return nil, fmt.Errorf("message has no %s header", events.ContentTypeHeader)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse content type %q: %w", contentType, err)
+	}
+
+	if version, ok := headers[events.SchemaVersionHeader]; ok && version != events.SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version: %q", version)
+	}
+
+	var event pb.ServiceCUDEventV1
+
+	switch mediaType {
+	case events.ProtobufContentType:
+		err = proto.Unmarshal(value, &event)
+	case events.JSONContentType:
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(value, &event)
+	default:
+		return nil, fmt.Errorf("unsupported content type: %q", contentType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,74 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ozonva/ova-service-api/internal/events"
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+var serviceID = uuid.MustParse("3b241101-e2bb-4255-8caf-4136c566a962")
+
+func TestDecode_WhenMessageIsEncodedByService_ShouldReturnEvent(t *testing.T) {
+	configs := []events.EncoderConfig{
+		{Format: events.FormatProtobuf},
+		{Format: events.FormatJSON},
+		{Format: events.FormatProtobuf, Envelope: events.EnvelopeCloudEvents},
+		{Format: events.FormatJSON, Envelope: events.EnvelopeCloudEvents},
+	}
+
+	for _, config := range configs {
+		encoder, err := events.NewEncoder(config)
+		require.NoError(t, err, "Encoder should be created")
+
+		event := events.NewServiceUpdateEvent(serviceID, 42)
+		message, err := encoder.Encode(event)
+		require.NoError(t, err, "Event should be encoded")
+
+		decoded, err := Decode(message.Value, message.Headers)
+
+		require.NoError(t, err, "Event should be decoded for %+v", config)
+		assert.Equal(t, event.EventID.String(), decoded.EventId)
+		assert.Equal(t, pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED, decoded.EventType)
+		assert.Equal(t, serviceID.String(), decoded.ServiceId)
+		assert.Equal(t, uint64(42), decoded.UserId)
+	}
+}
+
+func TestDecode_WhenContentTypeIsMissing_ShouldReturnError(t *testing.T) {
+	_, err := Decode([]byte("{}"), map[string]string{})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "message has no content-type header", err.Error(), "Incorrect error message")
+}
+
+func TestDecode_WhenContentTypeIsUnknown_ShouldReturnError(t *testing.T) {
+	_, err := Decode([]byte("<xml/>"), map[string]string{events.ContentTypeHeader: "application/xml"})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "unsupported content type: \"application/xml\"", err.Error(), "Incorrect error message")
+}
+
+func TestDecode_WhenSchemaVersionIsUnknown_ShouldReturnError(t *testing.T) {
+	_, err := Decode([]byte("{}"), map[string]string{
+		events.ContentTypeHeader:   events.JSONContentType,
+		events.SchemaVersionHeader: "2",
+	})
+
+	require.Errorf(t, err, "Error should be returned")
+	assert.Equal(t, "unsupported schema version: \"2\"", err.Error(), "Incorrect error message")
+}
+
+func TestDecode_WhenJSONHasUnknownFields_ShouldIgnoreThem(t *testing.T) {
+	value := []byte(`{"eventId": "1", "eventType": "SERVICE_EVENT_TYPE_CREATED", "newField": true}`)
+
+	decoded, err := Decode(value, map[string]string{events.ContentTypeHeader: events.JSONContentType})
+
+	require.NoError(t, err, "Unknown fields should be ignored")
+	assert.Equal(t, "1", decoded.EventId)
+	assert.Equal(t, pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED, decoded.EventType)
+}
@@ -0,0 +1,61 @@
+package consumer
+
+import (
+	"sync"
+)
+
+// DefaultIdempotencyCapacity is the number of event IDs remembered by the default in-memory store.
+const DefaultIdempotencyCapacity = 10000
+
+// IdempotencyStore tracks processed events, so redelivered events (e.g. after rebalance or producer retry)
+// are not handled twice. Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	Processed(eventID string) bool
+	MarkProcessed(eventID string)
+}
+
+// MemoryIdempotencyStore remembers the last processed event IDs within the capacity.
+// It is lost on restart, so use persistent implementation if handlers are not idempotent by themselves.
+type MemoryIdempotencyStore struct {
+	sync.Mutex
+	ids   map[string]struct{}
+	order []string
+	next  int
+}
+
+func NewMemoryIdempotencyStore(capacity int) *MemoryIdempotencyStore {
+	if capacity <= 0 {
+		capacity = DefaultIdempotencyCapacity
+	}
+
+	return &MemoryIdempotencyStore{
+		ids:   make(map[string]struct{}, capacity),
+		order: make([]string, capacity),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Processed(eventID string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	_, ok := s.ids[eventID]
+	return ok
+}
+
+func (s *MemoryIdempotencyStore) MarkProcessed(eventID string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.ids[eventID]; ok {
+		return
+	}
+
+	// Evict the oldest ID when the ring buffer is full
+	if evicted := s.order[s.next]; len(evicted) > 0 {
+		delete(s.ids, evicted)
+	}
+
+	s.order[s.next] = eventID
+	s.ids[eventID] = struct{}{}
+	s.next = (s.next + 1) % len(s.order)
+}
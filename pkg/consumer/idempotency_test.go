@@ -0,0 +1,29 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryIdempotencyStore_WhenEventIsMarked_ShouldReportItProcessed(t *testing.T) {
+	store := NewMemoryIdempotencyStore(2)
+
+	store.MarkProcessed("1")
+
+	assert.True(t, store.Processed("1"), "Marked event should be processed")
+	assert.False(t, store.Processed("2"), "Unknown event should not be processed")
+}
+
+func TestMemoryIdempotencyStore_WhenCapacityIsExceeded_ShouldForgetOldestEvent(t *testing.T) {
+	store := NewMemoryIdempotencyStore(2)
+
+	store.MarkProcessed("1")
+	store.MarkProcessed("2")
+	store.MarkProcessed("2")
+	store.MarkProcessed("3")
+
+	assert.False(t, store.Processed("1"), "Oldest event should be evicted")
+	assert.True(t, store.Processed("2"))
+	assert.True(t, store.Processed("3"))
+}
@@ -0,0 +1,69 @@
+package consumer
+
+import (
+	"context"
+
+	pb "github.com/ozonva/ova-service-api/pkg/ova-service-api"
+)
+
+// Event is the decoded ServiceCUDEventV1 together with the Kafka message metadata.
+type Event struct {
+	Payload   *pb.ServiceCUDEventV1
+	Key       []byte
+	Headers   map[string]string
+	Partition int32
+	Offset    int64
+}
+
+// Handler processes single event. Context carries the consumer span which continues the producer's trace.
+type Handler func(ctx context.Context, event Event) error
+
+// Router dispatches events to the handlers registered for their type.
+type Router struct {
+	handlers map[pb.ServiceEventTypeV1]Handler
+	fallback Handler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[pb.ServiceEventTypeV1]Handler),
+	}
+}
+
+// Handle registers the handler for the event type, previously registered handler is replaced.
+func (r *Router) Handle(eventType pb.ServiceEventTypeV1, handler Handler) *Router {
+	r.handlers[eventType] = handler
+	return r
+}
+
+func (r *Router) HandleCreated(handler Handler) *Router {
+	return r.Handle(pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED, handler)
+}
+
+func (r *Router) HandleUpdated(handler Handler) *Router {
+	return r.Handle(pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED, handler)
+}
+
+func (r *Router) HandleDeleted(handler Handler) *Router {
+	return r.Handle(pb.ServiceEventTypeV1_SERVICE_EVENT_TYPE_DELETED, handler)
+}
+
+// HandleOther registers the handler for event types without dedicated handler.
+func (r *Router) HandleOther(handler Handler) *Router {
+	r.fallback = handler
+	return r
+}
+
+// Dispatch calls the handler registered for the event type. Events without handler are ignored.
+func (r *Router) Dispatch(ctx context.Context, event Event) error {
+	handler, ok := r.handlers[event.Payload.GetEventType()]
+	if !ok {
+		handler = r.fallback
+	}
+
+	if handler == nil {
+		return nil
+	}
+
+	return handler(ctx, event)
+}
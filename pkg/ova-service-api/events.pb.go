@@ -0,0 +1,272 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.12.4
+// source: api/ova-service-api/events.proto
+
+package ova_service_api
+
+import (
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type of the change applied to the service
+type ServiceEventTypeV1 int32
+
+const (
+	ServiceEventTypeV1_SERVICE_EVENT_TYPE_UNSPECIFIED ServiceEventTypeV1 = 0
+	ServiceEventTypeV1_SERVICE_EVENT_TYPE_CREATED     ServiceEventTypeV1 = 1
+	ServiceEventTypeV1_SERVICE_EVENT_TYPE_UPDATED     ServiceEventTypeV1 = 2
+	ServiceEventTypeV1_SERVICE_EVENT_TYPE_DELETED     ServiceEventTypeV1 = 3
+)
+
+// Enum value maps for ServiceEventTypeV1.
+var (
+	ServiceEventTypeV1_name = map[int32]string{
+		0: "SERVICE_EVENT_TYPE_UNSPECIFIED",
+		1: "SERVICE_EVENT_TYPE_CREATED",
+		2: "SERVICE_EVENT_TYPE_UPDATED",
+		3: "SERVICE_EVENT_TYPE_DELETED",
+	}
+	ServiceEventTypeV1_value = map[string]int32{
+		"SERVICE_EVENT_TYPE_UNSPECIFIED": 0,
+		"SERVICE_EVENT_TYPE_CREATED":     1,
+		"SERVICE_EVENT_TYPE_UPDATED":     2,
+		"SERVICE_EVENT_TYPE_DELETED":     3,
+	}
+)
+
+func (x ServiceEventTypeV1) Enum() *ServiceEventTypeV1 {
+	p := new(ServiceEventTypeV1)
+	*p = x
+	return p
+}
+
+func (x ServiceEventTypeV1) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ServiceEventTypeV1) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_ova_service_api_events_proto_enumTypes[0].Descriptor()
+}
+
+func (ServiceEventTypeV1) Type() protoreflect.EnumType {
+	return &file_api_ova_service_api_events_proto_enumTypes[0]
+}
+
+func (x ServiceEventTypeV1) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ServiceEventTypeV1.Descriptor instead.
+func (ServiceEventTypeV1) EnumDescriptor() ([]byte, []int) {
+	return file_api_ova_service_api_events_proto_rawDescGZIP(), []int{0}
+}
+
+// Event produced to the message broker on every create, update or delete of the service
+type ServiceCUDEventV1 struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventId   string               `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	EventType ServiceEventTypeV1   `protobuf:"varint,2,opt,name=event_type,json=eventType,proto3,enum=ova.service.ServiceEventTypeV1" json:"event_type,omitempty"`
+	ServiceId string               `protobuf:"bytes,3,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	UserId    uint64               `protobuf:"varint,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// True if the event is re-emitted from the database by the replay rather than produced by the live change
+	Replay bool `protobuf:"varint,6,opt,name=replay,proto3" json:"replay,omitempty"`
+}
+
+func (x *ServiceCUDEventV1) Reset() {
+	*x = ServiceCUDEventV1{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_ova_service_api_events_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServiceCUDEventV1) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceCUDEventV1) ProtoMessage() {}
+
+func (x *ServiceCUDEventV1) ProtoReflect() protoreflect.Message {
+	mi := &file_api_ova_service_api_events_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceCUDEventV1.ProtoReflect.Descriptor instead.
+func (*ServiceCUDEventV1) Descriptor() ([]byte, []int) {
+	return file_api_ova_service_api_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ServiceCUDEventV1) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ServiceCUDEventV1) GetEventType() ServiceEventTypeV1 {
+	if x != nil {
+		return x.EventType
+	}
+	return ServiceEventTypeV1_SERVICE_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *ServiceCUDEventV1) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *ServiceCUDEventV1) GetTimestamp() *timestamp.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ServiceCUDEventV1) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ServiceCUDEventV1) GetReplay() bool {
+	if x != nil {
+		return x.Replay
+	}
+	return false
+}
+
+var File_api_ova_service_api_events_proto protoreflect.FileDescriptor
+
+var file_api_ova_service_api_events_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x61, 0x70, 0x69, 0x2f, 0x6f, 0x76, 0x61, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2d, 0x61, 0x70, 0x69, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0b, 0x6f, 0x76, 0x61, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xf8, 0x01, 0x0a, 0x11, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x55, 0x44, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x56, 0x31, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x3e, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x6f, 0x76, 0x61, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x56, 0x31, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64,
+	0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x75, 0x73, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x2a, 0x98, 0x01, 0x0a, 0x12,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x56, 0x31, 0x12, 0x22, 0x0a, 0x1e, 0x53, 0x45, 0x52, 0x56, 0x49, 0x43, 0x45, 0x5f, 0x45, 0x56,
+	0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1e, 0x0a, 0x1a, 0x53, 0x45, 0x52, 0x56, 0x49, 0x43,
+	0x45, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x52, 0x45,
+	0x41, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x1e, 0x0a, 0x1a, 0x53, 0x45, 0x52, 0x56, 0x49, 0x43,
+	0x45, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x50, 0x44,
+	0x41, 0x54, 0x45, 0x44, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x53, 0x45, 0x52, 0x56, 0x49, 0x43,
+	0x45, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x45, 0x4c,
+	0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x7a, 0x6f, 0x6e, 0x76, 0x61, 0x2f, 0x6f, 0x76, 0x61, 0x2d,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2d, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_ova_service_api_events_proto_rawDescOnce sync.Once
+	file_api_ova_service_api_events_proto_rawDescData = file_api_ova_service_api_events_proto_rawDesc
+)
+
+func file_api_ova_service_api_events_proto_rawDescGZIP() []byte {
+	file_api_ova_service_api_events_proto_rawDescOnce.Do(func() {
+		file_api_ova_service_api_events_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_ova_service_api_events_proto_rawDescData)
+	})
+	return file_api_ova_service_api_events_proto_rawDescData
+}
+
+var file_api_ova_service_api_events_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_ova_service_api_events_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_ova_service_api_events_proto_goTypes = []interface{}{
+	(ServiceEventTypeV1)(0),     // 0: ova.service.ServiceEventTypeV1
+	(*ServiceCUDEventV1)(nil),   // 1: ova.service.ServiceCUDEventV1
+	(*timestamp.Timestamp)(nil), // 2: google.protobuf.Timestamp
+}
+var file_api_ova_service_api_events_proto_depIdxs = []int32{
+	0, // 0: ova.service.ServiceCUDEventV1.event_type:type_name -> ova.service.ServiceEventTypeV1
+	2, // 1: ova.service.ServiceCUDEventV1.timestamp:type_name -> google.protobuf.Timestamp
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_api_ova_service_api_events_proto_init() }
+func file_api_ova_service_api_events_proto_init() {
+	if File_api_ova_service_api_events_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_ova_service_api_events_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServiceCUDEventV1); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_ova_service_api_events_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_ova_service_api_events_proto_goTypes,
+		DependencyIndexes: file_api_ova_service_api_events_proto_depIdxs,
+		EnumInfos:         file_api_ova_service_api_events_proto_enumTypes,
+		MessageInfos:      file_api_ova_service_api_events_proto_msgTypes,
+	}.Build()
+	File_api_ova_service_api_events_proto = out.File
+	file_api_ova_service_api_events_proto_rawDesc = nil
+	file_api_ova_service_api_events_proto_goTypes = nil
+	file_api_ova_service_api_events_proto_depIdxs = nil
+}
@@ -19,18 +19,39 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ServiceAPIClient interface {
-	// Create new service
+	// Create new service. If conflict rules are configured, the service overlapping other services by them is not
+	// created and FAILED_PRECONDITION is returned with PreconditionFailure details listing conflicting service IDs.
 	CreateServiceV1(ctx context.Context, in *CreateServiceV1Request, opts ...grpc.CallOption) (*CreateServiceV1Response, error)
 	// Get service details
 	DescribeServiceV1(ctx context.Context, in *DescribeServiceV1Request, opts ...grpc.CallOption) (*DescribeServiceV1Response, error)
-	// List services with pagination
-	ListServicesV1(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListServicesV1Response, error)
+	// List services, all of them or the page of page_size services starting from page_token
+	ListServicesV1(ctx context.Context, in *ListServicesV1Request, opts ...grpc.CallOption) (*ListServicesV1Response, error)
 	// Remove service
 	RemoveServiceV1(ctx context.Context, in *RemoveServiceV1Request, opts ...grpc.CallOption) (*empty.Empty, error)
-	// Create multiple services
+	// Create multiple services, none of them is created if any conflicts with another one or a stored service
 	MultiCreateServiceV1(ctx context.Context, in *MultiCreateServiceV1Request, opts ...grpc.CallOption) (*MultiCreateServiceV1Response, error)
-	// Update service
+	// Update service, conflicts are reported the same way as by CreateServiceV1
 	UpdateServiceV1(ctx context.Context, in *UpdateServiceV1Request, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Re-emit create events for the stored services, admin only: the admin token is required in the
+	// "authorization" metadata (the "Authorization" header over HTTP) as "Bearer <token>"
+	ReplayEventsV1(ctx context.Context, in *ReplayEventsV1Request, opts ...grpc.CallOption) (*ReplayEventsV1Response, error)
+	// Create services from the stream of unbounded size, invalid or not saved services are reported in the summary
+	ImportServicesV1(ctx context.Context, opts ...grpc.CallOption) (ServiceAPI_ImportServicesV1Client, error)
+	// Stream services in the List order, all of them are read from the single database snapshot.
+	// Over HTTP it is served as JSON Lines or CSV when requested with "Accept: application/x-ndjson" or "Accept: text/csv".
+	ExportServicesV1(ctx context.Context, in *ExportServicesV1Request, opts ...grpc.CallOption) (ServiceAPI_ExportServicesV1Client, error)
+	// Stream create, update and delete notifications as they happen.
+	// Over HTTP it is served as Server-Sent Events when requested with "Accept: text/event-stream".
+	WatchServicesV1(ctx context.Context, in *WatchServicesV1Request, opts ...grpc.CallOption) (ServiceAPI_WatchServicesV1Client, error)
+	// Find free slots of the service name or the address in working hours, booked services and buffers around them
+	// are skipped
+	FindAvailableSlotsV1(ctx context.Context, in *FindAvailableSlotsV1Request, opts ...grpc.CallOption) (*FindAvailableSlotsV1Response, error)
+	// Services of the user overlapping the range ordered by time, read from the read model.
+	// FAILED_PRECONDITION is returned if the read model is disabled.
+	GetUserCalendarV1(ctx context.Context, in *GetUserCalendarV1Request, opts ...grpc.CallOption) (*GetUserCalendarV1Response, error)
+	// Service counters of the user, read from the read model.
+	// FAILED_PRECONDITION is returned if the read model is disabled.
+	GetUserCountersV1(ctx context.Context, in *GetUserCountersV1Request, opts ...grpc.CallOption) (*GetUserCountersV1Response, error)
 }
 
 type serviceAPIClient struct {
@@ -59,7 +80,7 @@ func (c *serviceAPIClient) DescribeServiceV1(ctx context.Context, in *DescribeSe
 	return out, nil
 }
 
-func (c *serviceAPIClient) ListServicesV1(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListServicesV1Response, error) {
+func (c *serviceAPIClient) ListServicesV1(ctx context.Context, in *ListServicesV1Request, opts ...grpc.CallOption) (*ListServicesV1Response, error) {
 	out := new(ListServicesV1Response)
 	err := c.cc.Invoke(ctx, "/ova.service.ServiceAPI/ListServicesV1", in, out, opts...)
 	if err != nil {
@@ -95,22 +116,177 @@ func (c *serviceAPIClient) UpdateServiceV1(ctx context.Context, in *UpdateServic
 	return out, nil
 }
 
+func (c *serviceAPIClient) ReplayEventsV1(ctx context.Context, in *ReplayEventsV1Request, opts ...grpc.CallOption) (*ReplayEventsV1Response, error) {
+	out := new(ReplayEventsV1Response)
+	err := c.cc.Invoke(ctx, "/ova.service.ServiceAPI/ReplayEventsV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceAPIClient) ImportServicesV1(ctx context.Context, opts ...grpc.CallOption) (ServiceAPI_ImportServicesV1Client, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceAPI_ServiceDesc.Streams[0], "/ova.service.ServiceAPI/ImportServicesV1", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceAPIImportServicesV1Client{stream}
+	return x, nil
+}
+
+type ServiceAPI_ImportServicesV1Client interface {
+	Send(*CreateServiceV1Request) error
+	CloseAndRecv() (*ImportServicesV1Response, error)
+	grpc.ClientStream
+}
+
+type serviceAPIImportServicesV1Client struct {
+	grpc.ClientStream
+}
+
+func (x *serviceAPIImportServicesV1Client) Send(m *CreateServiceV1Request) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceAPIImportServicesV1Client) CloseAndRecv() (*ImportServicesV1Response, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportServicesV1Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceAPIClient) ExportServicesV1(ctx context.Context, in *ExportServicesV1Request, opts ...grpc.CallOption) (ServiceAPI_ExportServicesV1Client, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceAPI_ServiceDesc.Streams[1], "/ova.service.ServiceAPI/ExportServicesV1", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceAPIExportServicesV1Client{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServiceAPI_ExportServicesV1Client interface {
+	Recv() (*ExportServicesV1Response, error)
+	grpc.ClientStream
+}
+
+type serviceAPIExportServicesV1Client struct {
+	grpc.ClientStream
+}
+
+func (x *serviceAPIExportServicesV1Client) Recv() (*ExportServicesV1Response, error) {
+	m := new(ExportServicesV1Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceAPIClient) WatchServicesV1(ctx context.Context, in *WatchServicesV1Request, opts ...grpc.CallOption) (ServiceAPI_WatchServicesV1Client, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceAPI_ServiceDesc.Streams[2], "/ova.service.ServiceAPI/WatchServicesV1", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceAPIWatchServicesV1Client{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServiceAPI_WatchServicesV1Client interface {
+	Recv() (*WatchServicesV1Response, error)
+	grpc.ClientStream
+}
+
+type serviceAPIWatchServicesV1Client struct {
+	grpc.ClientStream
+}
+
+func (x *serviceAPIWatchServicesV1Client) Recv() (*WatchServicesV1Response, error) {
+	m := new(WatchServicesV1Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceAPIClient) FindAvailableSlotsV1(ctx context.Context, in *FindAvailableSlotsV1Request, opts ...grpc.CallOption) (*FindAvailableSlotsV1Response, error) {
+	out := new(FindAvailableSlotsV1Response)
+	err := c.cc.Invoke(ctx, "/ova.service.ServiceAPI/FindAvailableSlotsV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceAPIClient) GetUserCalendarV1(ctx context.Context, in *GetUserCalendarV1Request, opts ...grpc.CallOption) (*GetUserCalendarV1Response, error) {
+	out := new(GetUserCalendarV1Response)
+	err := c.cc.Invoke(ctx, "/ova.service.ServiceAPI/GetUserCalendarV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceAPIClient) GetUserCountersV1(ctx context.Context, in *GetUserCountersV1Request, opts ...grpc.CallOption) (*GetUserCountersV1Response, error) {
+	out := new(GetUserCountersV1Response)
+	err := c.cc.Invoke(ctx, "/ova.service.ServiceAPI/GetUserCountersV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServiceAPIServer is the server API for ServiceAPI service.
 // All implementations must embed UnimplementedServiceAPIServer
 // for forward compatibility
 type ServiceAPIServer interface {
-	// Create new service
+	// Create new service. If conflict rules are configured, the service overlapping other services by them is not
+	// created and FAILED_PRECONDITION is returned with PreconditionFailure details listing conflicting service IDs.
 	CreateServiceV1(context.Context, *CreateServiceV1Request) (*CreateServiceV1Response, error)
 	// Get service details
 	DescribeServiceV1(context.Context, *DescribeServiceV1Request) (*DescribeServiceV1Response, error)
-	// List services with pagination
-	ListServicesV1(context.Context, *empty.Empty) (*ListServicesV1Response, error)
+	// List services, all of them or the page of page_size services starting from page_token
+	ListServicesV1(context.Context, *ListServicesV1Request) (*ListServicesV1Response, error)
 	// Remove service
 	RemoveServiceV1(context.Context, *RemoveServiceV1Request) (*empty.Empty, error)
-	// Create multiple services
+	// Create multiple services, none of them is created if any conflicts with another one or a stored service
 	MultiCreateServiceV1(context.Context, *MultiCreateServiceV1Request) (*MultiCreateServiceV1Response, error)
-	// Update service
+	// Update service, conflicts are reported the same way as by CreateServiceV1
 	UpdateServiceV1(context.Context, *UpdateServiceV1Request) (*empty.Empty, error)
+	// Re-emit create events for the stored services, admin only: the admin token is required in the
+	// "authorization" metadata (the "Authorization" header over HTTP) as "Bearer <token>"
+	ReplayEventsV1(context.Context, *ReplayEventsV1Request) (*ReplayEventsV1Response, error)
+	// Create services from the stream of unbounded size, invalid or not saved services are reported in the summary
+	ImportServicesV1(ServiceAPI_ImportServicesV1Server) error
+	// Stream services in the List order, all of them are read from the single database snapshot.
+	// Over HTTP it is served as JSON Lines or CSV when requested with "Accept: application/x-ndjson" or "Accept: text/csv".
+	ExportServicesV1(*ExportServicesV1Request, ServiceAPI_ExportServicesV1Server) error
+	// Stream create, update and delete notifications as they happen.
+	// Over HTTP it is served as Server-Sent Events when requested with "Accept: text/event-stream".
+	WatchServicesV1(*WatchServicesV1Request, ServiceAPI_WatchServicesV1Server) error
+	// Find free slots of the service name or the address in working hours, booked services and buffers around them
+	// are skipped
+	FindAvailableSlotsV1(context.Context, *FindAvailableSlotsV1Request) (*FindAvailableSlotsV1Response, error)
+	// Services of the user overlapping the range ordered by time, read from the read model.
+	// FAILED_PRECONDITION is returned if the read model is disabled.
+	GetUserCalendarV1(context.Context, *GetUserCalendarV1Request) (*GetUserCalendarV1Response, error)
+	// Service counters of the user, read from the read model.
+	// FAILED_PRECONDITION is returned if the read model is disabled.
+	GetUserCountersV1(context.Context, *GetUserCountersV1Request) (*GetUserCountersV1Response, error)
 	mustEmbedUnimplementedServiceAPIServer()
 }
 
@@ -124,7 +300,7 @@ func (UnimplementedServiceAPIServer) CreateServiceV1(context.Context, *CreateSer
 func (UnimplementedServiceAPIServer) DescribeServiceV1(context.Context, *DescribeServiceV1Request) (*DescribeServiceV1Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DescribeServiceV1 not implemented")
 }
-func (UnimplementedServiceAPIServer) ListServicesV1(context.Context, *empty.Empty) (*ListServicesV1Response, error) {
+func (UnimplementedServiceAPIServer) ListServicesV1(context.Context, *ListServicesV1Request) (*ListServicesV1Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListServicesV1 not implemented")
 }
 func (UnimplementedServiceAPIServer) RemoveServiceV1(context.Context, *RemoveServiceV1Request) (*empty.Empty, error) {
@@ -136,6 +312,27 @@ func (UnimplementedServiceAPIServer) MultiCreateServiceV1(context.Context, *Mult
 func (UnimplementedServiceAPIServer) UpdateServiceV1(context.Context, *UpdateServiceV1Request) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateServiceV1 not implemented")
 }
+func (UnimplementedServiceAPIServer) ReplayEventsV1(context.Context, *ReplayEventsV1Request) (*ReplayEventsV1Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayEventsV1 not implemented")
+}
+func (UnimplementedServiceAPIServer) ImportServicesV1(ServiceAPI_ImportServicesV1Server) error {
+	return status.Errorf(codes.Unimplemented, "method ImportServicesV1 not implemented")
+}
+func (UnimplementedServiceAPIServer) ExportServicesV1(*ExportServicesV1Request, ServiceAPI_ExportServicesV1Server) error {
+	return status.Errorf(codes.Unimplemented, "method ExportServicesV1 not implemented")
+}
+func (UnimplementedServiceAPIServer) WatchServicesV1(*WatchServicesV1Request, ServiceAPI_WatchServicesV1Server) error {
+	return status.Errorf(codes.Unimplemented, "method WatchServicesV1 not implemented")
+}
+func (UnimplementedServiceAPIServer) FindAvailableSlotsV1(context.Context, *FindAvailableSlotsV1Request) (*FindAvailableSlotsV1Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindAvailableSlotsV1 not implemented")
+}
+func (UnimplementedServiceAPIServer) GetUserCalendarV1(context.Context, *GetUserCalendarV1Request) (*GetUserCalendarV1Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserCalendarV1 not implemented")
+}
+func (UnimplementedServiceAPIServer) GetUserCountersV1(context.Context, *GetUserCountersV1Request) (*GetUserCountersV1Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserCountersV1 not implemented")
+}
 func (UnimplementedServiceAPIServer) mustEmbedUnimplementedServiceAPIServer() {}
 
 // UnsafeServiceAPIServer may be embedded to opt out of forward compatibility for this service.
@@ -186,7 +383,7 @@ func _ServiceAPI_DescribeServiceV1_Handler(srv interface{}, ctx context.Context,
 }
 
 func _ServiceAPI_ListServicesV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+	in := new(ListServicesV1Request)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -198,7 +395,7 @@ func _ServiceAPI_ListServicesV1_Handler(srv interface{}, ctx context.Context, de
 		FullMethod: "/ova.service.ServiceAPI/ListServicesV1",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceAPIServer).ListServicesV1(ctx, req.(*empty.Empty))
+		return srv.(ServiceAPIServer).ListServicesV1(ctx, req.(*ListServicesV1Request))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -257,6 +454,146 @@ func _ServiceAPI_UpdateServiceV1_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServiceAPI_ReplayEventsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayEventsV1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceAPIServer).ReplayEventsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ova.service.ServiceAPI/ReplayEventsV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceAPIServer).ReplayEventsV1(ctx, req.(*ReplayEventsV1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceAPI_ImportServicesV1_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceAPIServer).ImportServicesV1(&serviceAPIImportServicesV1Server{stream})
+}
+
+type ServiceAPI_ImportServicesV1Server interface {
+	SendAndClose(*ImportServicesV1Response) error
+	Recv() (*CreateServiceV1Request, error)
+	grpc.ServerStream
+}
+
+type serviceAPIImportServicesV1Server struct {
+	grpc.ServerStream
+}
+
+func (x *serviceAPIImportServicesV1Server) SendAndClose(m *ImportServicesV1Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceAPIImportServicesV1Server) Recv() (*CreateServiceV1Request, error) {
+	m := new(CreateServiceV1Request)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ServiceAPI_ExportServicesV1_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportServicesV1Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceAPIServer).ExportServicesV1(m, &serviceAPIExportServicesV1Server{stream})
+}
+
+type ServiceAPI_ExportServicesV1Server interface {
+	Send(*ExportServicesV1Response) error
+	grpc.ServerStream
+}
+
+type serviceAPIExportServicesV1Server struct {
+	grpc.ServerStream
+}
+
+func (x *serviceAPIExportServicesV1Server) Send(m *ExportServicesV1Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ServiceAPI_WatchServicesV1_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchServicesV1Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceAPIServer).WatchServicesV1(m, &serviceAPIWatchServicesV1Server{stream})
+}
+
+type ServiceAPI_WatchServicesV1Server interface {
+	Send(*WatchServicesV1Response) error
+	grpc.ServerStream
+}
+
+type serviceAPIWatchServicesV1Server struct {
+	grpc.ServerStream
+}
+
+func (x *serviceAPIWatchServicesV1Server) Send(m *WatchServicesV1Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ServiceAPI_FindAvailableSlotsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindAvailableSlotsV1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceAPIServer).FindAvailableSlotsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ova.service.ServiceAPI/FindAvailableSlotsV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceAPIServer).FindAvailableSlotsV1(ctx, req.(*FindAvailableSlotsV1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceAPI_GetUserCalendarV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserCalendarV1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceAPIServer).GetUserCalendarV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ova.service.ServiceAPI/GetUserCalendarV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceAPIServer).GetUserCalendarV1(ctx, req.(*GetUserCalendarV1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceAPI_GetUserCountersV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserCountersV1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceAPIServer).GetUserCountersV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ova.service.ServiceAPI/GetUserCountersV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceAPIServer).GetUserCountersV1(ctx, req.(*GetUserCountersV1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ServiceAPI_ServiceDesc is the grpc.ServiceDesc for ServiceAPI service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -288,7 +625,39 @@ var ServiceAPI_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateServiceV1",
 			Handler:    _ServiceAPI_UpdateServiceV1_Handler,
 		},
+		{
+			MethodName: "ReplayEventsV1",
+			Handler:    _ServiceAPI_ReplayEventsV1_Handler,
+		},
+		{
+			MethodName: "FindAvailableSlotsV1",
+			Handler:    _ServiceAPI_FindAvailableSlotsV1_Handler,
+		},
+		{
+			MethodName: "GetUserCalendarV1",
+			Handler:    _ServiceAPI_GetUserCalendarV1_Handler,
+		},
+		{
+			MethodName: "GetUserCountersV1",
+			Handler:    _ServiceAPI_GetUserCountersV1_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportServicesV1",
+			Handler:       _ServiceAPI_ImportServicesV1_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportServicesV1",
+			Handler:       _ServiceAPI_ExportServicesV1_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchServicesV1",
+			Handler:       _ServiceAPI_WatchServicesV1_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/ova-service-api/service.proto",
 }